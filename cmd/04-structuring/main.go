@@ -9,14 +9,23 @@ import (
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/genai"
+
+	"awesomeProject2/internal/schema"
 )
 
 // 구조화된 아웃풋 = 스키마
 // 설명을 잘 하는 것이 중요
 // json으로 구조를 정의를 해줘야
 
+// meetingNotes는 에이전트의 답변 구조입니다. OutputSchema를 이 구조체의 태그로 만들고, 답변도 이 구조체로 읽습니다.
+type meetingNotes struct {
+	Summary     string   `json:"summary" description:"A short summary of the user's text."`
+	ActionItems []string `json:"action_items" description:"Concrete things someone has to do, one per item. Include who and when if mentioned."`
+}
+
 func main() {
 	ctx := context.Background()
 
@@ -30,12 +39,10 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
-	outputSchema := &genai.Schema{
-		Type: genai.TypeObject,
-		Properties: map[string]*genai.Schema{
-			"summary":      {Type: genai.TypeString},
-			"action_items": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
-		},
+	// 구조체 태그에서 스키마 생성 (손으로 쓴 map과 달리 Go 코드와 어긋나지 않음)
+	outputSchema, err := schema.For[meetingNotes]()
+	if err != nil {
+		log.Fatalf("Failed to build output schema: %v", err)
 	}
 
 	routerAgent, err := llmagent.New(llmagent.Config{
@@ -44,6 +51,8 @@ func main() {
 		Description:  "A helpful agent. Uses a router to route the user questions.",
 		Instruction:  "You are a helpful assistant. Answer the user's questions.",
 		OutputSchema: outputSchema,
+		// 최종 답변을 meetingNotes로 읽어 스키마대로 왔는지 확인
+		AfterModelCallbacks: []llmagent.AfterModelCallback{logNotes},
 	})
 
 	if err != nil {
@@ -61,6 +70,26 @@ func main() {
 	}
 }
 
+// logNotes는 모델의 최종 답변을 meetingNotes로 읽어 로그로 남깁니다. 답변은 바꾸지 않습니다.
+func logNotes(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+	if respErr != nil || resp == nil || resp.Partial || resp.Content == nil {
+		return nil, nil
+	}
+	var text string
+	for _, p := range resp.Content.Parts {
+		if !p.Thought {
+			text += p.Text
+		}
+	}
+	notes, err := schema.Decode[meetingNotes](text)
+	if err != nil {
+		log.Printf("[notes] answer does not match the schema: %v", err)
+		return nil, nil
+	}
+	log.Printf("[notes] 요약 %d자, 할 일 %d개", len([]rune(notes.Summary)), len(notes.ActionItems))
+	return nil, nil
+}
+
 // tool + output structure --> 1 agent = 1 schema
 // 툴 + 스키마 하면 안됨
 // 툴로 검색을 하고 다른 에이전트한테 넘기고 걔가 구조화된 아웃풋을 주면 됨
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// --- 라우터 평가 (eval 하위 명령) ---
+
+// evalCase는 평가 데이터셋의 한 줄입니다. Priority가 비어 있으면 우선순위는 채점하지 않습니다.
+type evalCase struct {
+	Query       string `json:"query"`
+	Destination string `json:"destination"`
+	Priority    string `json:"priority,omitempty"`
+}
+
+// evalResult는 질문 하나를 분류한 결과입니다.
+type evalResult struct {
+	Case     evalCase
+	Decision routingDecision
+	Err      error
+	Latency  time.Duration
+}
+
+// Correct는 목적지를 맞혔는지 알려줍니다. 오류가 났거나 보내지 않고 되물은 경우는 틀린 것으로 셉니다.
+func (r evalResult) Correct() bool {
+	return r.Err == nil && !r.Decision.Clarify && r.Decision.Destination == r.Case.Destination
+}
+
+// classifyFunc는 질문 하나를 분류합니다. eval은 이 함수의 정확도와 지연 시간을 잽니다.
+type classifyFunc func(ctx context.Context, query string) (routingDecision, error)
+
+// runEval은 라벨이 붙은 JSONL 데이터셋을 라우터로 분류해 정확도, 목적지별 정밀도/재현율, 혼동 행렬, 지연 시간을 보고합니다.
+// 정확도가 --min_accuracy보다 낮으면 종료 코드 1로 끝나므로 CI에서 프롬프트 회귀를 막는 데 쓸 수 있습니다.
+//
+//	go run . eval --dataset testdata/router_eval.jsonl --min_accuracy 0.9
+func runEval(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	datasetFile := fs.String("dataset", "testdata/router_eval.jsonl", "Labeled JSONL dataset: {\"query\", \"destination\", \"priority\"} per line")
+	modelFlag := fs.String("model", modelName, "Model for the router agent")
+	minAccuracy := fs.Float64("min_accuracy", 0.8, "Exit with status 1 when destination accuracy is below this value (0-1)")
+	parallel := fs.Int("parallel", 4, "Number of queries classified at the same time")
+	timeout := fs.Duration("timeout", time.Minute, "Time limit for classifying one query")
+	useRules := fs.Bool("rules", true, "Evaluate the rule pre-router together with the LLM router (false: LLM only)")
+	rulesFile := fs.String("rules_file", "", "JSON file with routing rules (default: built-in Korean/English rules)")
+	ruleMinScore := fs.Int("rule_min_score", 2, "Minimum rule score to skip the LLM router (keyword = 1, pattern = 2)")
+	samples := fs.Int("samples", 1, "Ask the LLM router this many times per query and take the majority destination")
+	confidenceThreshold := fs.Float64("confidence_threshold", defaultConfidenceThreshold, "Count a query as a clarifying question when the average confidence is below this value (0-1, 0: never ask)")
+	minAgreement := fs.Float64("min_agreement", defaultMinAgreement, "Count a query as a clarifying question when the share of samples choosing the majority destination is below this value (0-1, 0: never ask)")
+	_ = fs.Parse(args)
+	if err := validateVoting(*samples, *confidenceThreshold, *minAgreement); err != nil {
+		log.Fatalf("Invalid voting options: %v", err)
+	}
+
+	cases, err := loadEvalCases(*datasetFile)
+	if err != nil {
+		log.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	ctx := context.Background()
+	llm, err := gemini.NewModel(ctx, *modelFlag, &genai.ClientConfig{APIKey: os.Getenv("GOOGLE_API_KEY")})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+	routerAgent, err := newRouterAgent(llm)
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+	classify, err := newAgentClassifier(routerAgent)
+	if err != nil {
+		log.Fatalf("Failed to create classifier: %v", err)
+	}
+	pre, err := openPreRouter(*useRules, *rulesFile, *ruleMinScore)
+	if err != nil {
+		log.Fatalf("Failed to load routing rules: %v", err)
+	}
+	classify = withPreRouter(pre, withVoting(classify, *samples, *confidenceThreshold, *minAgreement))
+
+	fmt.Fprintf(os.Stderr, ">>> %s의 질문 %d개를 분류합니다. (모델 %s)\n", *datasetFile, len(cases), *modelFlag)
+	results := evaluate(ctx, classify, cases, *parallel, *timeout)
+
+	report := &evalReport{
+		Dataset:             *datasetFile,
+		Model:               *modelFlag,
+		MinAccuracy:         *minAccuracy,
+		Samples:             *samples,
+		ConfidenceThreshold: *confidenceThreshold,
+		MinAgreement:        *minAgreement,
+		Results:             results,
+	}
+	if err := report.WriteMarkdown(os.Stdout); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if !report.Passed() {
+		fmt.Fprintf(os.Stderr, ">>> 정확도 %.1f%%가 기준 %.1f%%보다 낮습니다.\n", 100*report.Accuracy(), 100**minAccuracy)
+		os.Exit(1)
+	}
+}
+
+// loadEvalCases는 데이터셋을 읽습니다. 빈 줄과 '#'으로 시작하는 줄은 건너뜁니다.
+func loadEvalCases(path string) ([]evalCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []evalCase
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var c evalCase
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if c.Query == "" {
+			return nil, fmt.Errorf("%s:%d: query is empty", path, n)
+		}
+		if !slices.Contains(destinations, c.Destination) {
+			return nil, fmt.Errorf("%s:%d: unknown destination %q", path, n, c.Destination)
+		}
+		if c.Priority != "" && !slices.Contains(priorities, c.Priority) {
+			return nil, fmt.Errorf("%s:%d: unknown priority %q", path, n, c.Priority)
+		}
+		cases = append(cases, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("%s has no cases", path)
+	}
+	return cases, nil
+}
+
+// newAgentClassifier는 질문마다 새 세션에서 router를 실행해, 앞 질문이 다음 분류에 영향을 주지 않게 합니다.
+func newAgentClassifier(router agent.Agent) (classifyFunc, error) {
+	const appName, userID = "router_eval", "eval_user"
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: appName, Agent: router, SessionService: sessionService})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, query string) (routingDecision, error) {
+		created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID})
+		if err != nil {
+			return routingDecision{RoutedBy: layerLLM}, err
+		}
+		var answer string
+		msg := genai.NewContentFromText(query, genai.RoleUser)
+		for event, err := range r.Run(ctx, userID, created.Session.ID(), msg, agent.RunConfig{}) {
+			if err != nil {
+				return routingDecision{RoutedBy: layerLLM}, err
+			}
+			if !event.Partial && event.Content != nil {
+				answer = contentText(event.Content)
+			}
+		}
+		d, err := parseDecision(answer)
+		d.RoutedBy = layerLLM
+		return d, err
+	}, nil
+}
+
+// withPreRouter는 워크플로와 같은 순서로, 규칙이 확실하게 분류하지 못한 질문만 classify에 넘깁니다. pre가 nil이면 classify를 그대로 씁니다.
+func withPreRouter(pre *preRouter, classify classifyFunc) classifyFunc {
+	if pre == nil {
+		return classify
+	}
+	return func(ctx context.Context, query string) (routingDecision, error) {
+		if d, ok := pre.Route(query); ok {
+			return d, nil
+		}
+		return classify(ctx, query)
+	}
+}
+
+// evaluate는 cases를 parallel개씩 동시에 분류하고, 데이터셋 순서대로 결과를 반환합니다.
+func evaluate(ctx context.Context, classify classifyFunc, cases []evalCase, parallel int, timeout time.Duration) []evalResult {
+	results := make([]evalResult, len(cases))
+	sem := make(chan struct{}, max(parallel, 1))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for i, c := range cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			caseCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			d, err := classify(caseCtx, c.Query)
+			res := evalResult{Case: c, Decision: d, Err: err, Latency: time.Since(start)}
+			results[i] = res
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			mark := "✅"
+			if !res.Correct() {
+				mark = "❌"
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s %s → %s (%s)\n", done, len(cases), mark, snippet(c.Query, 30), res.predicted(), d.RoutedBy)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// snippet은 긴 문자열을 n글자까지만 보여줍니다.
+func snippet(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClassifier는 질문마다 정해 둔 결정(또는 오류)을 돌려줍니다.
+func fakeClassifier(answers map[string]routingDecision, failures map[string]error) classifyFunc {
+	return func(ctx context.Context, query string) (routingDecision, error) {
+		if err, ok := failures[query]; ok {
+			return routingDecision{RoutedBy: layerLLM}, err
+		}
+		d := answers[query]
+		d.RoutedBy = layerLLM
+		return d, nil
+	}
+}
+
+func TestEvaluateMetrics(t *testing.T) {
+	cases := []evalCase{
+		{Query: "tech-ok", Destination: destTechnical},
+		{Query: "tech-wrong", Destination: destTechnical},
+		{Query: "bill-ok", Destination: destBilling, Priority: "high"},
+		{Query: "bill-clarify", Destination: destBilling},
+		{Query: "general-error", Destination: destGeneral},
+		{Query: "escalate-ok", Destination: destEscalate, Priority: "high"},
+	}
+	classify := fakeClassifier(map[string]routingDecision{
+		"tech-ok":    {Destination: destTechnical},
+		"tech-wrong": {Destination: destBilling},
+		"bill-ok":    {Destination: destBilling, Priority: "high"},
+		// 목적지는 맞았지만 되물었으므로 틀린 것으로 셉니다.
+		"bill-clarify": {Destination: destBilling, Clarify: true},
+		"escalate-ok":  {Destination: destEscalate, Priority: "medium"},
+	}, map[string]error{"general-error": errors.New("model unavailable")})
+
+	results := evaluate(t.Context(), classify, cases, 3, time.Second)
+	for i, res := range results {
+		if res.Case != cases[i] {
+			t.Fatalf("result %d is for %q, want dataset order", i, res.Case.Query)
+		}
+	}
+
+	r := &evalReport{MinAccuracy: 0.5, Results: results}
+	if r.Correct() != 3 || r.Accuracy() != 0.5 {
+		t.Errorf("correct = %d, accuracy = %v, want 3, 0.5", r.Correct(), r.Accuracy())
+	}
+	if r.Clarified() != 1 {
+		t.Errorf("clarified = %d, want 1", r.Clarified())
+	}
+	// 되묻지 않은 5개 중 3개를 맞혔습니다. 오류는 틀린 것으로 셉니다.
+	if r.RoutedAccuracy() != 0.6 {
+		t.Errorf("routed accuracy = %v, want 0.6", r.RoutedAccuracy())
+	}
+	if acc, n := r.PriorityAccuracy(); acc != 0.5 || n != 2 {
+		t.Errorf("priority accuracy = %v of %d, want 0.5 of 2", acc, n)
+	}
+
+	wantMetrics := map[string]classMetrics{
+		destTechnical: {Destination: destTechnical, Precision: 1, Recall: 0.5, Predicted: 1, Support: 2, TruePositive: 1},
+		destBilling:   {Destination: destBilling, Precision: 0.5, Recall: 0.5, Predicted: 2, Support: 2, TruePositive: 1},
+		destGeneral:   {Destination: destGeneral, Precision: 0, Recall: 0, Predicted: 0, Support: 1},
+		destEscalate:  {Destination: destEscalate, Precision: 1, Recall: 1, Predicted: 1, Support: 1, TruePositive: 1},
+	}
+	metrics := r.ClassMetrics()
+	if len(metrics) != len(destinations) {
+		t.Fatalf("got metrics for %d destinations, want %d", len(metrics), len(destinations))
+	}
+	for _, m := range metrics {
+		if m != wantMetrics[m.Destination] {
+			t.Errorf("metrics for %s = %+v, want %+v", m.Destination, m, wantMetrics[m.Destination])
+		}
+	}
+	if f1 := wantMetrics[destTechnical].F1(); f1 < 0.666 || f1 > 0.667 {
+		t.Errorf("F1 = %v, want 2/3", f1)
+	}
+
+	wantMatrix := map[string]map[string]int{
+		destTechnical: {destTechnical: 1, destBilling: 1},
+		destBilling:   {destBilling: 1, predictedClarify: 1},
+		destGeneral:   {predictedError: 1},
+		destEscalate:  {destEscalate: 1},
+	}
+	if got := r.ConfusionMatrix(); !reflect.DeepEqual(got, wantMatrix) {
+		t.Errorf("confusion matrix = %v, want %v", got, wantMatrix)
+	}
+
+	// 정확도 기준: 같으면 통과, 넘지 못하면 실패(eval이 종료 코드 1로 끝남)
+	if !r.Passed() {
+		t.Error("accuracy 0.5 with threshold 0.5 should pass")
+	}
+	r.MinAccuracy = 0.6
+	if r.Passed() {
+		t.Error("accuracy 0.5 with threshold 0.6 should fail")
+	}
+	var md strings.Builder
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "기준 미달") {
+		t.Errorf("report does not show the failed gate:\n%s", md.String())
+	}
+}
+
+func TestEvaluateTimeout(t *testing.T) {
+	slow := func(ctx context.Context, query string) (routingDecision, error) {
+		<-ctx.Done()
+		return routingDecision{RoutedBy: layerLLM}, ctx.Err()
+	}
+	results := evaluate(t.Context(), slow, []evalCase{{Query: "q", Destination: destGeneral}}, 1, 10*time.Millisecond)
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) || results[0].Correct() || results[0].predicted() != predictedError {
+		t.Errorf("timed out result = %+v", results[0])
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var results []evalResult
+	for _, ms := range []int{50, 10, 40, 20, 30} {
+		results = append(results, evalResult{Latency: time.Duration(ms) * time.Millisecond})
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 10 * time.Millisecond}, // 순위가 0이어도 가장 작은 값
+		{20, 10 * time.Millisecond},
+		{50, 30 * time.Millisecond}, // ceil(2.5) = 3번째
+		{90, 50 * time.Millisecond},
+		{100, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(results, tt.p); got != tt.want {
+			t.Errorf("p%v = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile of no results = %v, want 0", got)
+	}
+	if got := percentile(results[:1], 99); got != 50*time.Millisecond {
+		t.Errorf("percentile of one result = %v", got)
+	}
+}
+
+func TestLoadEvalCases(t *testing.T) {
+	dir := t.TempDir()
+	write := func(data string) string {
+		f, err := os.CreateTemp(dir, "*.jsonl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(data)
+		f.Close()
+		return f.Name()
+	}
+
+	cases, err := loadEvalCases(write(`# 주석
+{"query": "환불해 주세요", "destination": "billing_inquiry", "priority": "medium"}
+
+{"query": "안녕", "destination": "general_chat"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []evalCase{
+		{Query: "환불해 주세요", Destination: destBilling, Priority: "medium"},
+		{Query: "안녕", Destination: destGeneral},
+	}
+	if !reflect.DeepEqual(cases, want) {
+		t.Errorf("cases = %+v, want %+v", cases, want)
+	}
+
+	tests := []struct {
+		name, data, want string
+	}{
+		{"bad JSON", "{\"query\": \"a\", \"destination\": \"general_chat\"}\n{\"query\":", ":2:"},
+		{"empty query", `{"query": "", "destination": "general_chat"}`, "query is empty"},
+		{"unknown destination", `{"query": "a", "destination": "sales"}`, `unknown destination "sales"`},
+		{"unknown priority", `{"query": "a", "destination": "general_chat", "priority": "urgent"}`, `unknown priority "urgent"`},
+		{"no cases", "# 비어 있음\n\n", "has no cases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadEvalCases(write(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := loadEvalCases(filepath.Join(dir, "missing.jsonl")); err == nil {
+		t.Error("missing file was loaded")
+	}
+
+	// 저장소에 있는 데이터셋도 읽혀야 합니다.
+	if cases, err := loadEvalCases("testdata/router_eval.jsonl"); err != nil || len(cases) == 0 {
+		t.Errorf("testdata: %d cases, %v", len(cases), err)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 목적지가 아닌 예측 값. 혼동 행렬의 마지막 열들이 됩니다.
+const (
+	predictedClarify = "(되묻기)" // 확신이 없어 보내지 않고 되물은 질문
+	predictedError   = "(오류)"  // 분류하다 오류가 난 질문
+)
+
+func (r evalResult) predicted() string {
+	if r.Err != nil {
+		return predictedError
+	}
+	if r.Decision.Clarify {
+		return predictedClarify
+	}
+	return r.Decision.Destination
+}
+
+// evalReport는 평가 결과와 그 지표들입니다.
+type evalReport struct {
+	Dataset     string
+	Model       string
+	MinAccuracy float64
+	// Samples는 질문마다 라우터에 물은 횟수, ConfidenceThreshold와 MinAgreement는 확신도와 일치율의 되묻기 기준입니다.
+	Samples             int
+	ConfidenceThreshold float64
+	MinAgreement        float64
+	Results             []evalResult
+}
+
+// Correct는 목적지를 맞힌 질문 수입니다.
+func (r *evalReport) Correct() int {
+	correct := 0
+	for _, res := range r.Results {
+		if res.Correct() {
+			correct++
+		}
+	}
+	return correct
+}
+
+// Accuracy는 목적지를 맞힌 비율입니다.
+func (r *evalReport) Accuracy() float64 {
+	return ratio(r.Correct(), len(r.Results))
+}
+
+// Passed는 목적지 정확도가 MinAccuracy 이상인지 알려줍니다. 아니면 eval이 종료 코드 1로 끝납니다.
+func (r *evalReport) Passed() bool {
+	return r.Accuracy() >= r.MinAccuracy
+}
+
+// Clarified는 보내지 않고 되물은 질문 수입니다.
+func (r *evalReport) Clarified() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err == nil && res.Decision.Clarify {
+			n++
+		}
+	}
+	return n
+}
+
+// RoutedAccuracy는 되묻지 않고 보낸 질문 중 목적지를 맞힌 비율입니다. 오류가 난 질문은 틀린 것으로 셉니다.
+func (r *evalReport) RoutedAccuracy() float64 {
+	return ratio(r.Correct(), len(r.Results)-r.Clarified())
+}
+
+// PriorityAccuracy는 정답 우선순위가 있는 질문 중 우선순위를 맞힌 비율과 그런 질문 수입니다.
+func (r *evalReport) PriorityAccuracy() (float64, int) {
+	correct, total := 0, 0
+	for _, res := range r.Results {
+		if res.Case.Priority == "" {
+			continue
+		}
+		total++
+		if res.Err == nil && res.Decision.Priority == res.Case.Priority {
+			correct++
+		}
+	}
+	return ratio(correct, total), total
+}
+
+// classMetrics는 목적지 하나의 정밀도(그 목적지로 예측한 것 중 맞은 비율)와 재현율(그 목적지인 질문 중 맞힌 비율)입니다.
+type classMetrics struct {
+	Destination        string
+	Precision, Recall  float64
+	Predicted, Support int // 그 목적지로 예측한 수, 정답이 그 목적지인 수
+	TruePositive       int
+}
+
+func (m classMetrics) F1() float64 {
+	if m.Precision+m.Recall == 0 {
+		return 0
+	}
+	return 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+}
+
+// ClassMetrics는 목적지마다 정밀도/재현율을 구합니다.
+func (r *evalReport) ClassMetrics() []classMetrics {
+	var metrics []classMetrics
+	for _, dest := range destinations {
+		m := classMetrics{Destination: dest}
+		for _, res := range r.Results {
+			actual, predicted := res.Case.Destination == dest, res.predicted() == dest
+			if actual {
+				m.Support++
+			}
+			if predicted {
+				m.Predicted++
+			}
+			if actual && predicted {
+				m.TruePositive++
+			}
+		}
+		m.Precision, m.Recall = ratio(m.TruePositive, m.Predicted), ratio(m.TruePositive, m.Support)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// ConfusionMatrix는 [정답][예측] 별 질문 수입니다. 예측에는 predictedClarify와 predictedError도 들어갑니다.
+func (r *evalReport) ConfusionMatrix() map[string]map[string]int {
+	matrix := make(map[string]map[string]int)
+	for _, res := range r.Results {
+		row, ok := matrix[res.Case.Destination]
+		if !ok {
+			row = make(map[string]int)
+			matrix[res.Case.Destination] = row
+		}
+		row[res.predicted()]++
+	}
+	return matrix
+}
+
+// LatencyPercentile은 지연 시간의 p 백분위수(0-100, nearest-rank)입니다.
+func (r *evalReport) LatencyPercentile(p float64) time.Duration {
+	return percentile(r.Results, p)
+}
+
+// layerMetrics는 분류 단계(규칙, LLM) 하나가 맡은 질문 수와 그 정확도, 지연 시간입니다.
+type layerMetrics struct {
+	Layer   string
+	Count   int
+	Correct int
+	P50     time.Duration
+}
+
+// Layers는 각 단계가 분류한 질문의 지표입니다. 규칙 없이 평가했다면 LLM 단계 하나만 나옵니다.
+func (r *evalReport) Layers() []layerMetrics {
+	var layers []layerMetrics
+	for _, layer := range []string{layerRules, layerLLM} {
+		var results []evalResult
+		for _, res := range r.Results {
+			if res.Decision.RoutedBy == layer {
+				results = append(results, res)
+			}
+		}
+		if len(results) == 0 {
+			continue
+		}
+		m := layerMetrics{Layer: layer, Count: len(results), P50: percentile(results, 50)}
+		for _, res := range results {
+			if res.Correct() {
+				m.Correct++
+			}
+		}
+		layers = append(layers, m)
+	}
+	return layers
+}
+
+// percentile은 results의 지연 시간 중 p 백분위수(0-100, nearest-rank)입니다.
+func percentile(results []evalResult, p float64) time.Duration {
+	if len(results) == 0 {
+		return 0
+	}
+	latencies := make([]time.Duration, len(results))
+	for i, res := range results {
+		latencies[i] = res.Latency
+	}
+	slices.Sort(latencies)
+	rank := int(math.Ceil(p / 100 * float64(len(latencies))))
+	return latencies[min(max(rank, 1), len(latencies))-1]
+}
+
+// WriteMarkdown은 요약, 목적지별 지표, 혼동 행렬, 틀린 질문 목록을 Markdown으로 씁니다.
+func (r *evalReport) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# 라우터 평가: %s\n\n", r.Dataset)
+	fmt.Fprintf(bw, "- 모델: %s\n", r.Model)
+	pass := "✅ 통과"
+	if !r.Passed() {
+		pass = "❌ 기준 미달"
+	}
+	fmt.Fprintf(bw, "- 목적지 정확도: %s (%d/%d), 기준 %s → %s\n", percent(r.Accuracy()), r.Correct(), len(r.Results), percent(r.MinAccuracy), pass)
+	fmt.Fprintf(bw, "- 되묻기: %d개 (%s), 되묻지 않은 질문의 정확도 %s · 샘플 %d번, 확신도 기준 %.2f, 일치율 기준 %.2f\n",
+		r.Clarified(), percent(ratio(r.Clarified(), len(r.Results))), percent(r.RoutedAccuracy()), r.Samples, r.ConfidenceThreshold, r.MinAgreement)
+	if acc, n := r.PriorityAccuracy(); n > 0 {
+		fmt.Fprintf(bw, "- 우선순위 정확도: %s (%d개 중)\n", percent(acc), n)
+	}
+	fmt.Fprintf(bw, "- 지연 시간: p50 %s, p90 %s, p99 %s, 최대 %s\n",
+		roundLatency(r.LatencyPercentile(50)), roundLatency(r.LatencyPercentile(90)),
+		roundLatency(r.LatencyPercentile(99)), roundLatency(r.LatencyPercentile(100)))
+
+	fmt.Fprintf(bw, "\n## 단계별 처리\n\n| 단계 | 맡은 질문 | 비율 | 정확도 | 지연 p50 |\n|---|---|---|---|---|\n")
+	for _, l := range r.Layers() {
+		fmt.Fprintf(bw, "| %s | %d | %s | %s | %s |\n", l.Layer, l.Count, percent(ratio(l.Count, len(r.Results))), percent(ratio(l.Correct, l.Count)), roundLatency(l.P50))
+	}
+
+	fmt.Fprintf(bw, "\n## 목적지별 정밀도/재현율\n\n| 목적지 | 정밀도 | 재현율 | F1 | 정답 수 |\n|---|---|---|---|---|\n")
+	for _, m := range r.ClassMetrics() {
+		// 한 번도 예측하지 않은(또는 정답에 없는) 목적지는 정밀도(재현율)를 정할 수 없습니다.
+		precision, recall := percent(m.Precision), percent(m.Recall)
+		if m.Predicted == 0 {
+			precision = "-"
+		}
+		if m.Support == 0 {
+			recall = "-"
+		}
+		fmt.Fprintf(bw, "| %s | %s | %s | %s | %d |\n", m.Destination, precision, recall, percent(m.F1()), m.Support)
+	}
+
+	columns := append(slices.Clone(destinations), predictedClarify, predictedError)
+	matrix := r.ConfusionMatrix()
+	fmt.Fprintf(bw, "\n## 혼동 행렬 (행: 정답, 열: 예측)\n\n| 정답 \\ 예측 | %s |\n|---|%s\n", strings.Join(columns, " | "), strings.Repeat("---|", len(columns)))
+	for _, actual := range destinations {
+		cells := make([]string, len(columns))
+		for i, predicted := range columns {
+			n := matrix[actual][predicted]
+			switch {
+			case n == 0:
+				cells[i] = "·"
+			case predicted == actual:
+				cells[i] = fmt.Sprintf("**%d**", n)
+			default:
+				cells[i] = fmt.Sprint(n)
+			}
+		}
+		fmt.Fprintf(bw, "| %s | %s |\n", actual, strings.Join(cells, " | "))
+	}
+
+	var wrong []string
+	for i, res := range r.Results {
+		if res.Correct() {
+			continue
+		}
+		reason := res.Decision.Reasoning
+		switch {
+		case res.Err != nil:
+			reason = res.Err.Error()
+		case res.Decision.Clarify:
+			reason = fmt.Sprintf("확신도 %.2f, 일치율 %.2f → %s", res.Decision.Confidence, res.Decision.Agreement, res.Decision.question())
+		}
+		wrong = append(wrong, fmt.Sprintf("| %d | %s | %s | %s | %s | %s |", i+1, cell(res.Case.Query), res.Case.Destination, res.predicted(), res.Decision.RoutedBy, cell(reason)))
+	}
+	if len(wrong) > 0 {
+		fmt.Fprintf(bw, "\n## 틀린 질문\n\n| # | 질문 | 정답 | 예측 | 단계 | 라우터의 이유 / 오류 |\n|---|---|---|---|---|---|\n%s\n", strings.Join(wrong, "\n"))
+	}
+	return bw.Flush()
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%.1f%%", 100*f)
+}
+
+// roundLatency는 지연 시간을 읽기 쉽게 밀리초 단위로 자릅니다.
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+// cell은 표 칸 안에 넣을 수 있게 '|'와 줄바꿈을 바꿉니다.
+func cell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
@@ -2,23 +2,61 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"net/http"
 	"os"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/session"
 	"google.golang.org/genai"
+
+	"awesomeProject2/internal/handoff"
+	"awesomeProject2/internal/schema"
+	"awesomeProject2/internal/sessionstore"
 )
 
+const modelName = "gemini-3-pro-preview"
+
 func main() {
+	// 상담원 대기열 보기: go run . queue [--handoff_file data/handoff.jsonl]
+	if len(os.Args) > 1 && os.Args[1] == "queue" {
+		runQueue(os.Args[2:])
+		return
+	}
+	// 라우터 평가: go run . eval [--dataset testdata/router_eval.jsonl] [--min_accuracy 0.8]
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		runEval(os.Args[2:])
+		return
+	}
+
+	// 런처가 쓰지 않는 옵션만 먼저 읽고, 나머지 인자는 런처에 그대로 넘깁니다.
+	fs := flag.NewFlagSet("router", flag.ExitOnError)
+	handoffFile := fs.String("handoff_file", defaultHandoffFile, "Tickets escalated to a human operator are appended to this file")
+	operatorAddr := fs.String("operator_addr", "localhost:8090", "Address of the operator page and ticket API (empty: disabled)")
+	operatorToken := fs.String("operator_token", os.Getenv("HANDOFF_OPERATOR_TOKEN"), "Token operators must present to the operator page and ticket API")
+	useRules := fs.Bool("rules", true, "Classify obvious messages with keyword/regex rules before calling the LLM router")
+	rulesFile := fs.String("rules_file", "", "JSON file with routing rules (default: built-in Korean/English rules)")
+	ruleMinScore := fs.Int("rule_min_score", 2, "Minimum rule score to skip the LLM router (keyword = 1, pattern = 2)")
+	samples := fs.Int("samples", 1, "Ask the LLM router this many times and take the majority destination (self-consistency)")
+	confidenceThreshold := fs.Float64("confidence_threshold", defaultConfidenceThreshold, "Ask a clarifying question instead of routing when the router's average confidence is below this value (0-1, 0: never ask)")
+	minAgreement := fs.Float64("min_agreement", defaultMinAgreement, "Ask a clarifying question instead of routing when the share of samples choosing the majority destination is below this value (0-1, 0: never ask)")
+	sessionFile := fs.String("session_file", "", "Persist sessions to this file so operator replies can reach conversations after a restart (default: in-memory)")
+	_ = fs.Parse(os.Args[1:])
+	if err := validateVoting(*samples, *confidenceThreshold, *minAgreement); err != nil {
+		log.Fatalf("Invalid voting options: %v", err)
+	}
+
 	ctx := context.Background()
 
 	// 라우팅은 속도가 생명이므로 Flash 모델 권장 (예: gemini-1.5-flash)
-	model, err := gemini.NewModel(ctx,
-		"gemini-3-pro-preview",
+	llm, err := gemini.NewModel(ctx,
+		modelName,
 		&genai.ClientConfig{
 			APIKey: os.Getenv("GOOGLE_API_KEY"),
 		})
@@ -26,35 +64,101 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
+	routerAgent, err := newRouterAgent(llm)
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
+	// [개선 3] 전문 에이전트: 라우터가 요약한 의도({intent_summary})를 받아 실제로 답변합니다.
+	specialists := make(map[string]agent.Agent)
+	for _, spec := range specialistSpecs {
+		a, err := llmagent.New(llmagent.Config{
+			Name:        spec.destination,
+			Model:       llm,
+			Description: spec.description,
+			Instruction: spec.instruction + specialistContext,
+			// 다음 메시지도 워크플로가 다시 분류해야 하므로 다른 에이전트로 넘기지 않습니다.
+			DisallowTransferToParent: true,
+			DisallowTransferToPeers:  true,
+		})
+		if err != nil {
+			log.Fatalf("Failed to create %s agent: %v", spec.destination, err)
+		}
+		specialists[spec.destination] = a
+	}
+
+	// [개선 4] 상담원 대기열: escalate_to_human은 티켓이 되어 이 파일에 쌓입니다.
+	queue, err := handoff.Open(*handoffFile)
+	if err != nil {
+		log.Fatalf("Failed to open handoff queue: %v", err)
+	}
+	defer queue.Close()
+
+	// [개선 5] 규칙 라우터: 누가 봐도 분명한 메시지는 LLM을 부르지 않고 키워드/정규식으로 바로 분류합니다.
+	pre, err := openPreRouter(*useRules, *rulesFile, *ruleMinScore)
+	if err != nil {
+		log.Fatalf("Failed to load routing rules: %v", err)
+	}
+
+	// [개선 6] 워크플로: 라우터의 결정(JSON)을 읽어 전문 에이전트나 상담원 대기열로 보냅니다.
+	// 라우터에 여러 번 물어 다수결하고, 확신이 없으면 보내지 않고 되묻습니다.
+	workflow, err := newRoutingWorkflow(routerAgent, specialists, queue, routingOptions{
+		Pre:           pre,
+		Samples:       *samples,
+		MinConfidence: *confidenceThreshold,
+		MinAgreement:  *minAgreement,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create routing workflow: %v", err)
+	}
+
+	// [개선 7] 상담원 페이지: 티켓을 맡고 답하면, 답변이 사용자의 세션에 그대로 들어갑니다.
+	// 그래서 에이전트와 상담원 페이지가 같은 세션 서비스를 써야 합니다.
+	var sessionService session.Service = session.InMemoryService()
+	if *sessionFile != "" {
+		store, err := sessionstore.Open(*sessionFile)
+		if err != nil {
+			log.Fatalf("Failed to open session file: %v", err)
+		}
+		defer store.Close()
+		sessionService = store
+	}
+	// 티켓에는 사용자의 메시지와 대화가 들어 있으므로 토큰 없이는 상담원 페이지를 열지 않습니다.
+	if *operatorAddr != "" && *operatorToken == "" {
+		log.Printf("[handoff] operator page disabled: set --operator_token (or HANDOFF_OPERATOR_TOKEN) to enable it")
+	} else if *operatorAddr != "" {
+		operatorHandler, err := handoff.NewHandler(queue, sessionService, *operatorToken)
+		if err != nil {
+			log.Fatalf("Failed to create operator page: %v", err)
+		}
+		go func() {
+			log.Printf("[handoff] operator page: http://%s/", *operatorAddr)
+			if err := http.ListenAndServe(*operatorAddr, operatorHandler); err != nil {
+				log.Printf("[handoff] operator page stopped: %v", err)
+			}
+		}()
+	}
+
+	config := &launcher.Config{
+		AgentLoader:    agent.NewSingleLoader(workflow),
+		SessionService: sessionService,
+	}
+
+	l := full.NewLauncher()
+
+	// 실행 시 인자 예시: "내 신용카드 결제가 두 번 되었어, 환불해줘" -> billing_inquiry -> 결제 담당 에이전트가 답변
+	if err = l.Execute(ctx, config, fs.Args()); err != nil {
+		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+	}
+}
+
+// newRouterAgent는 사용자의 요청을 분류만 하는 라우터 에이전트를 만듭니다. eval 하위 명령도 같은 라우터를 평가합니다.
+func newRouterAgent(llm model.LLM) (agent.Agent, error) {
 	// [개선 1] OutputSchema: 답변이 아닌 '라우팅 결정'을 위한 구조체 정의
-	// 사용자의 의도를 파악하여 정해진 카테고리 중 하나로 분류합니다.
-	outputSchema := &genai.Schema{
-		Type: genai.TypeObject,
-		Properties: map[string]*genai.Schema{
-			// 1. 어디로 보낼지 결정 (Enum을 사용하여 환각 방지 및 엄격한 분류)
-			"destination": {
-				Type:        genai.TypeString,
-				Enum:        []string{"technical_support", "billing_inquiry", "general_chat", "escalate_to_human"},
-				Description: "The target agent or department to handle the user query.",
-			},
-			// 2. 분류 이유 (디버깅 및 검증용)
-			"reasoning": {
-				Type:        genai.TypeString,
-				Description: "Explanation of why this destination was chosen.",
-			},
-			// 3. 사용자 의도 요약 (다음 에이전트에게 넘겨주기 위함)
-			"intent_summary": {
-				Type:        genai.TypeString,
-				Description: "A concise summary of what the user wants to achieve.",
-			},
-			// 4. 난이도/우선순위 파악
-			"priority": {
-				Type: genai.TypeString,
-				Enum: []string{"high", "medium", "low"},
-			},
-		},
-		// 필수 필드 지정
-		Required: []string{"destination", "reasoning", "intent_summary"},
+	// 스키마는 routingDecision의 태그로 만들므로, 워크플로가 읽는 구조체와 어긋나지 않습니다.
+	outputSchema, err := schema.For[routingDecision]()
+	if err != nil {
+		return nil, err
 	}
 
 	// [개선 2] Instruction: 역할을 '분류자(Classifier)'로 명확히 정의
@@ -69,28 +173,18 @@ Classify the input into one of the following destinations:
 4. 'escalate_to_human': Complex complaints, legal issues, or when the user is very angry.
 
 Analyze the user's input carefully and determine the destination, priority, and a summary of their intent.
+Rate your confidence honestly: use a low value when the message is vague or fits several destinations,
+and in that case write the clarifying question you would ask. If the user is answering your earlier question, use the whole conversation.
 `
 
-	routerAgent, err := llmagent.New(llmagent.Config{
+	return llmagent.New(llmagent.Config{
 		Name:         "router_agent", // 이름도 역할에 맞게 변경
-		Model:        model,
+		Model:        llm,
 		Description:  "Analyzes user input and routes it to the appropriate specialized agent.",
 		Instruction:  instruction,
 		OutputSchema: outputSchema,
+		// 라우팅은 아래 워크플로가 하므로, 라우터가 직접 다른 에이전트로 넘기지 않게 합니다.
+		DisallowTransferToParent: true,
+		DisallowTransferToPeers:  true,
 	})
-
-	if err != nil {
-		log.Fatalf("Failed to create agent: %v", err)
-	}
-
-	config := &launcher.Config{
-		AgentLoader: agent.NewSingleLoader(routerAgent),
-	}
-
-	l := full.NewLauncher()
-
-	// 실행 시 인자 예시: "내 신용카드 결제가 두 번 되었어, 환불해줘" -> billing_inquiry
-	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
-		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
-	}
 }
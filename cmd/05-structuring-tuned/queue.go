@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"awesomeProject2/internal/handoff"
+)
+
+const defaultHandoffFile = "data/handoff.jsonl"
+
+// runQueue는 상담원을 기다리는 티켓을 우선순위 순으로 보여줍니다.
+//
+//	go run . queue --handoff_file data/handoff.jsonl
+func runQueue(args []string) {
+	fs := flag.NewFlagSet("queue", flag.ExitOnError)
+	handoffFile := fs.String("handoff_file", defaultHandoffFile, "Handoff ticket file written by the router")
+	_ = fs.Parse(args)
+
+	queue, err := handoff.Open(*handoffFile)
+	if err != nil {
+		log.Fatalf("Failed to open handoff queue: %v", err)
+	}
+	defer queue.Close()
+
+	tickets := queue.Pending("")
+	if len(tickets) == 0 {
+		fmt.Println("(상담원을 기다리는 요청이 없습니다)")
+		return
+	}
+	fmt.Printf("상담원을 기다리는 요청 %d건\n", len(tickets))
+	for _, t := range tickets {
+		status := string(t.Status)
+		if t.Operator != "" {
+			status += " by " + t.Operator
+		}
+		fmt.Printf("\n[%s] %s  %s  %s  (사용자 %s, 세션 %s)\n", t.ID, t.Priority, status, t.CreatedAt.Format(time.DateTime), t.UserID, t.SessionID)
+		fmt.Printf("  요약: %s\n  메시지: %s\n", t.Summary, t.Message)
+		if t.Reason != "" {
+			fmt.Printf("  이유: %s\n", t.Reason)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 분류를 어느 단계가 했는지 (상태의 routed_by, 평가 보고서의 레이어)
+const (
+	layerRules = "rules"
+	layerLLM   = "llm"
+)
+
+// 규칙 점수: 키워드 하나는 1점, 정규식 하나는 2점입니다.
+const (
+	keywordScore = 1
+	patternScore = 2
+)
+
+// routingRule은 목적지 하나로 보내는 단서들입니다. 같은 목적지에 규칙이 여러 개 있으면 점수를 더합니다.
+type routingRule struct {
+	Destination string   `json:"destination"`
+	Priority    string   `json:"priority,omitempty"` // 맞았을 때의 우선순위 (기본 medium)
+	Keywords    []string `json:"keywords,omitempty"` // 메시지에 들어 있으면 keywordScore (대소문자 무시)
+	Patterns    []string `json:"patterns,omitempty"` // 메시지에 맞으면 patternScore (대소문자 무시 정규식)
+
+	compiled []*regexp.Regexp
+}
+
+// defaultRules는 한국어/영어로 자주 들어오는, 누가 봐도 분명한 요청들입니다.
+// 애매한 메시지는 일부러 맞히지 않고 LLM 라우터에 넘깁니다.
+var defaultRules = []routingRule{
+	{
+		Destination: destEscalate,
+		Priority:    "high",
+		Keywords:    []string{"상담원", "책임자", "법적", "소송", "고소", "변호사", "human agent", "real person", "lawyer", "lawsuit", "legal action"},
+		Patterns: []string{
+			`(상담원|사람|담당자|책임자).{0,10}(연결|바꿔|불러)`,
+			`(speak|talk)\s+(to|with)\s+(a\s+)?(human|real person|manager|supervisor)`,
+		},
+	},
+	{
+		Destination: destBilling,
+		Priority:    "medium",
+		Keywords:    []string{"환불", "결제", "요금", "청구", "구독", "인보이스", "세금계산서", "refund", "invoice", "billing", "charged", "subscription", "payment", "pricing"},
+		Patterns:    []string{`(요금제|플랜|plan).{0,10}(가격|얼마|cost|price)`},
+	},
+	{
+		// 돈이 두 번 나간 경우는 급한 결제 문의입니다.
+		Destination: destBilling,
+		Priority:    "high",
+		Patterns:    []string{`(두\s*번|중복|이중)\s*(결제|청구)|(결제|청구)\S*\s*(두\s*번|중복)`, `charged\s+(twice|two times)|double[- ]charged`},
+	},
+	{
+		Destination: destTechnical,
+		Priority:    "medium",
+		Keywords:    []string{"에러", "오류", "버그", "설치", "배포", "타임아웃", "크래시", "error", "bug", "crash", "install", "timeout", "exception", "panic", "stack trace"},
+	},
+	{
+		// 서비스가 멈춘 경우는 급한 기술 지원입니다.
+		Destination: destTechnical,
+		Priority:    "high",
+		Patterns:    []string{`(서버|서비스|production|prod).{0,15}(다운|down|죽|장애)`, `\b5\d\d\b.{0,10}(에러|오류|error)`},
+	},
+	{
+		// 인사만 하는 짧은 메시지 (다른 말이 붙으면 맞지 않습니다)
+		Destination: destGeneral,
+		Priority:    "low",
+		Patterns:    []string{`^\s*(안녕|안녕하세요|반가워요|고마워요?|감사합니다|hi|hello|hey|thanks|thank you)(\s+there)?[\s!.~?]*$`},
+	},
+}
+
+// preRouter는 LLM 라우터 앞에서 규칙으로 먼저 분류합니다.
+// 한 목적지의 점수가 minScore 이상이고 다른 목적지의 단서가 하나도 없을 때만 확실하다고 보고 바로 보냅니다.
+type preRouter struct {
+	rules    []routingRule
+	minScore int
+}
+
+// newPreRouter는 규칙의 정규식을 컴파일하고 목적지/우선순위를 검사합니다.
+func newPreRouter(rules []routingRule, minScore int) (*preRouter, error) {
+	p := &preRouter{minScore: minScore}
+	for i, r := range rules {
+		if !slices.Contains(destinations, r.Destination) {
+			return nil, fmt.Errorf("rule %d: unknown destination %q", i+1, r.Destination)
+		}
+		if r.Priority == "" {
+			r.Priority = "medium"
+		}
+		if !slices.Contains(priorities, r.Priority) {
+			return nil, fmt.Errorf("rule %d: unknown priority %q", i+1, r.Priority)
+		}
+		r.compiled = nil
+		for _, pattern := range r.Patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			r.compiled = append(r.compiled, re)
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// openPreRouter는 플래그 값으로 preRouter를 만듭니다. enabled가 false면 nil(규칙 없이 항상 LLM)을 반환합니다.
+// rulesFile이 비어 있으면 defaultRules를 씁니다.
+func openPreRouter(enabled bool, rulesFile string, minScore int) (*preRouter, error) {
+	if !enabled {
+		return nil, nil
+	}
+	rules := defaultRules
+	if rulesFile != "" {
+		data, err := os.ReadFile(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = nil
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", rulesFile, err)
+		}
+	}
+	return newPreRouter(rules, minScore)
+}
+
+// Route는 text를 규칙으로 분류합니다. 확실하지 않으면 ok가 false이고, 그때는 LLM 라우터가 분류해야 합니다.
+func (p *preRouter) Route(text string) (d routingDecision, ok bool) {
+	lower := strings.ToLower(text)
+	scores := make(map[string]int)
+	matches := make(map[string][]string)
+	best := make(map[string]string) // 목적지별로 맞은 규칙 중 가장 높은 우선순위
+	for _, r := range p.rules {
+		score := 0
+		for _, kw := range r.Keywords {
+			if strings.Contains(lower, strings.ToLower(kw)) {
+				score += keywordScore
+				matches[r.Destination] = append(matches[r.Destination], kw)
+			}
+		}
+		for _, re := range r.compiled {
+			if m := re.FindString(text); m != "" {
+				score += patternScore
+				matches[r.Destination] = append(matches[r.Destination], strings.TrimSpace(m))
+			}
+		}
+		if score == 0 {
+			continue
+		}
+		scores[r.Destination] += score
+		if cur, seen := best[r.Destination]; !seen || slices.Index(priorities, r.Priority) < slices.Index(priorities, cur) {
+			best[r.Destination] = r.Priority
+		}
+	}
+
+	// 단서가 두 목적지 이상에 걸쳐 있으면(예: "환불 안 해주면 소송할게요") 판단을 LLM에 맡깁니다.
+	if len(scores) != 1 {
+		return routingDecision{}, false
+	}
+	for dest, score := range scores {
+		if score < p.minScore {
+			return routingDecision{}, false
+		}
+		return routingDecision{
+			Destination:   dest,
+			Priority:      best[dest],
+			Reasoning:     fmt.Sprintf("rule match (score %d): %s", score, strings.Join(matches[dest], ", ")),
+			IntentSummary: snippet(text, 200),
+			// 규칙은 확실할 때만 보내므로 되묻지 않습니다.
+			Confidence: 1,
+			Agreement:  1,
+			RoutedBy:   layerRules,
+		}, true
+	}
+	return routingDecision{}, false
+}
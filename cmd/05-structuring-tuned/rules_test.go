@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreRouterDefaultRules(t *testing.T) {
+	p, err := newPreRouter(defaultRules, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text     string
+		wantOK   bool
+		dest     string
+		priority string
+	}{
+		// 두 목적지의 단서가 섞이면 LLM에 맡깁니다.
+		{text: "환불 안 해주면 소송할게요", wantOK: false},
+		// 키워드 하나(1점)는 minScore 2에 못 미칩니다.
+		{text: "환불 가능한가요?", wantOK: false},
+		{text: "이거 왜 이래요?", wantOK: false},
+		{text: "요금 청구 내역을 보고 싶어요", wantOK: true, dest: destBilling, priority: "medium"},
+		// 같은 목적지 안에서는 가장 높은 우선순위를 씁니다.
+		{text: "결제가 두 번 됐어요", wantOK: true, dest: destBilling, priority: "high"},
+		{text: "I was charged twice this month", wantOK: true, dest: destBilling, priority: "high"},
+		{text: "설치하다가 에러가 나요", wantOK: true, dest: destTechnical, priority: "medium"},
+		{text: "서버가 다운됐어요", wantOK: true, dest: destTechnical, priority: "high"},
+		{text: "상담원 연결해 주세요", wantOK: true, dest: destEscalate, priority: "high"},
+		{text: "I want to talk to a human", wantOK: true, dest: destEscalate, priority: "high"},
+		// 인사 패턴은 인사만 있을 때만 맞습니다.
+		{text: "안녕하세요", wantOK: true, dest: destGeneral, priority: "low"},
+		{text: "  Hello there! ", wantOK: true, dest: destGeneral, priority: "low"},
+		{text: "안녕하세요 환불해 주세요", wantOK: false},
+		{text: "안녕하세요, 설치하다가 에러가 나요", wantOK: true, dest: destTechnical, priority: "medium"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			d, ok := p.Route(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v (decision %+v)", ok, tt.wantOK, d)
+			}
+			if !ok {
+				return
+			}
+			if d.Destination != tt.dest || d.Priority != tt.priority {
+				t.Errorf("routed to %s/%s, want %s/%s (%s)", d.Destination, d.Priority, tt.dest, tt.priority, d.Reasoning)
+			}
+			if d.RoutedBy != layerRules || d.Confidence != 1 || d.Agreement != 1 || d.Clarify {
+				t.Errorf("rule decision = %+v", d)
+			}
+		})
+	}
+}
+
+func TestPreRouterMinScore(t *testing.T) {
+	// "요금 청구"는 키워드 2개(2점)입니다.
+	for minScore, want := range map[int]bool{1: true, 2: true, 3: false} {
+		p, err := newPreRouter(defaultRules, minScore)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := p.Route("요금 청구 내역"); ok != want {
+			t.Errorf("minScore %d: ok = %v, want %v", minScore, ok, want)
+		}
+	}
+}
+
+func TestNewPreRouterRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule routingRule
+		want string
+	}{
+		{"unknown destination", routingRule{Destination: "sales", Keywords: []string{"가격"}}, "unknown destination"},
+		{"unknown priority", routingRule{Destination: destBilling, Priority: "urgent"}, "unknown priority"},
+		{"bad regex", routingRule{Destination: destBilling, Patterns: []string{"(결제"}}, "rule 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newPreRouter([]routingRule{tt.rule}, 2); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// 우선순위를 비워 두면 medium입니다.
+	p, err := newPreRouter([]routingRule{{Destination: destBilling, Keywords: []string{"환불"}}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := p.Route("환불"); !ok || d.Priority != "medium" {
+		t.Errorf("default priority: %+v, %v", d, ok)
+	}
+}
+
+func TestOpenPreRouter(t *testing.T) {
+	if p, err := openPreRouter(false, "", 2); p != nil || err != nil {
+		t.Errorf("disabled: %v, %v, want nil, nil", p, err)
+	}
+
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p, err := openPreRouter(true, write("ok.json", `[{"destination": "billing_inquiry", "priority": "high", "keywords": ["환불"]}]`), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := p.Route("환불해 주세요"); !ok || d.Destination != destBilling || d.Priority != "high" {
+		t.Errorf("rules from file: %+v, %v", d, ok)
+	}
+	// 파일의 규칙이 기본 규칙을 대신합니다.
+	if _, ok := p.Route("상담원 연결해 주세요"); ok {
+		t.Error("default rules are still used with a rules file")
+	}
+
+	for name, path := range map[string]string{
+		"missing file":        filepath.Join(dir, "missing.json"),
+		"malformed JSON":      write("bad.json", `[{"destination":`),
+		"unknown destination": write("dest.json", `[{"destination": "sales"}]`),
+		"unknown priority":    write("prio.json", `[{"destination": "general_chat", "priority": "urgent"}]`),
+		"bad regex":           write("regex.json", `[{"destination": "general_chat", "patterns": ["(안녕"]}]`),
+	} {
+		if _, err := openPreRouter(true, path, 2); err == nil {
+			t.Errorf("%s: openPreRouter succeeded", name)
+		}
+	}
+}
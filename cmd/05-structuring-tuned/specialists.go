@@ -0,0 +1,39 @@
+package main
+
+// specialistSpec은 라우터의 목적지 하나를 맡는 전문 에이전트입니다. 에이전트 이름은 목적지와 같습니다.
+type specialistSpec struct {
+	destination string
+	description string
+	instruction string
+}
+
+var specialistSpecs = []specialistSpec{
+	{
+		destination: destTechnical,
+		description: "Helps with code, bugs, installation and technical errors.",
+		instruction: `You are a technical support engineer.
+Diagnose the user's technical problem step by step: ask for missing details (error messages, versions, logs) only when they are really needed,
+then suggest concrete things to check or commands to run.`,
+	},
+	{
+		destination: destBilling,
+		description: "Answers questions about payments, invoices, pricing and subscriptions.",
+		instruction: `You are a billing specialist.
+Explain charges, invoices, plans and refunds clearly. You cannot see the user's account, so never invent amounts or dates;
+tell the user exactly which information (invoice number, payment date) the billing team needs to check their case.`,
+	},
+	{
+		destination: destGeneral,
+		description: "Handles greetings, small talk and general questions.",
+		instruction: `You are a friendly assistant for general conversation.
+Answer briefly and warmly. If the user seems to need technical or billing help, invite them to describe the problem.`,
+	},
+}
+
+// specialistContext는 모든 전문 에이전트의 지시문 끝에 붙습니다. {intent_summary}와 {priority}는 워크플로가 상태에 넣은 값으로 바뀝니다.
+const specialistContext = `
+
+The request router already analyzed the latest message:
+- Intent summary: {intent_summary}
+- Priority: {priority}
+Use the summary to focus your answer, and reply in the same language the user wrote in.`
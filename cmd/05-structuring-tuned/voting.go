@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/adk/agent"
+)
+
+// --confidence_threshold와 --min_agreement의 기본값입니다. 워크플로와 eval이 같은 기준을 쓰도록 한곳에 둡니다.
+const (
+	defaultConfidenceThreshold = 0.6
+	defaultMinAgreement        = 0.6
+)
+
+// validateVoting은 다수결 설정을 검사합니다. samples는 1 이상, 두 기준은 0~1이어야 합니다.
+// 기준이 1보다 크면 모든 메시지를 되묻고, 음수면 0과 같아 실수로 보는 편이 안전합니다.
+func validateVoting(samples int, minConfidence, minAgreement float64) error {
+	if samples < 1 {
+		return fmt.Errorf("samples must be at least 1, got %d", samples)
+	}
+	if minConfidence < 0 || minConfidence > 1 {
+		return fmt.Errorf("confidence_threshold must be between 0 and 1, got %v", minConfidence)
+	}
+	if minAgreement < 0 || minAgreement > 1 {
+		return fmt.Errorf("min_agreement must be between 0 and 1, got %v", minAgreement)
+	}
+	return nil
+}
+
+// clarifyFallback은 라우터가 되물을 질문을 쓰지 않았을 때 대신 보내는 질문입니다.
+const clarifyFallback = "어떤 도움이 필요하신지 조금 더 자세히 알려주시겠어요? (예: 기술 문제, 결제, 상담원 연결)"
+
+// vote는 같은 메시지를 여러 번 분류한 결과로 다수결을 냅니다 (self-consistency).
+//
+//   - 목적지는 가장 많이 나온 것입니다. 표가 같으면 평균 확신도가 높은 것, 그래도 같으면 destinations 순서로 고릅니다.
+//   - 이유와 요약 등은 그 목적지를 고른 결정 중 확신도가 가장 높은 것을 씁니다.
+//   - Confidence는 그 목적지를 고른 결정들의 평균 확신도, Agreement는 그 목적지를 고른 비율입니다.
+//   - Confidence가 minConfidence보다 낮거나 Agreement가 minAgreement보다 낮으면 Clarify를 켭니다. 기준이 0이면 그 기준으로는 되묻지 않습니다.
+//
+// 결정이 하나뿐이면 그 결정에 Agreement 1을 붙인 것과 같습니다. samples는 비어 있으면 안 됩니다.
+func vote(samples []routingDecision, minConfidence, minAgreement float64) routingDecision {
+	counts := make(map[string]int)
+	sums := make(map[string]float64)
+	for _, s := range samples {
+		counts[s.Destination]++
+		sums[s.Destination] += s.Confidence
+	}
+	var winner string
+	for _, dest := range destinations {
+		n := counts[dest]
+		if n == 0 {
+			continue
+		}
+		// 표가 같을 때 확신도 합을 비교하는 것은 평균을 비교하는 것과 같습니다.
+		if winner == "" || n > counts[winner] || n == counts[winner] && sums[dest] > sums[winner] {
+			winner = dest
+		}
+	}
+
+	var d routingDecision
+	found := false
+	for _, s := range samples {
+		if s.Destination == winner && (!found || s.Confidence > d.Confidence) {
+			d, found = s, true
+		}
+	}
+	if d.ClarifyingQuestion == "" {
+		for _, s := range samples {
+			if s.ClarifyingQuestion != "" {
+				d.ClarifyingQuestion = s.ClarifyingQuestion
+				break
+			}
+		}
+	}
+	d.Confidence = sums[winner] / float64(counts[winner])
+	d.Agreement = ratio(counts[winner], len(samples))
+	d.Clarify = d.Confidence < minConfidence || d.Agreement < minAgreement
+	return d
+}
+
+// question은 사용자에게 되물을 질문입니다.
+func (d routingDecision) question() string {
+	if d.ClarifyingQuestion != "" {
+		return d.ClarifyingQuestion
+	}
+	return clarifyFallback
+}
+
+// sampleRouter는 router를 n번 동시에 실행해 결정을 모읍니다. 라우터의 이벤트는 세션에 남기지 않습니다.
+// 읽을 수 없는 답변은 버리고, 하나도 읽지 못했을 때만 오류를 반환합니다.
+func sampleRouter(ic agent.InvocationContext, router agent.Agent, n int) ([]routingDecision, error) {
+	decisions := make([]routingDecision, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var answer string
+			for event, err := range router.Run(ic) {
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				if event != nil && !event.Partial && event.Content != nil {
+					answer = contentText(event.Content)
+				}
+			}
+			decisions[i], errs[i] = parseDecision(answer)
+		}()
+	}
+	wg.Wait()
+	return collectSamples(decisions, errs, contentText(ic.UserContent()))
+}
+
+// withVoting은 워크플로와 같은 방식으로, classify를 n번 동시에 실행해 다수결한 결정을 돌려줍니다.
+// n이 1 이하여도 확신도 기준(minConfidence)은 적용합니다. 한 번만 물으면 일치율은 항상 1입니다.
+func withVoting(classify classifyFunc, n int, minConfidence, minAgreement float64) classifyFunc {
+	n = max(n, 1)
+	return func(ctx context.Context, query string) (routingDecision, error) {
+		decisions := make([]routingDecision, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				decisions[i], errs[i] = classify(ctx, query)
+			}()
+		}
+		wg.Wait()
+		samples, err := collectSamples(decisions, errs, query)
+		if err != nil {
+			return routingDecision{RoutedBy: layerLLM}, err
+		}
+		return vote(samples, minConfidence, minAgreement), nil
+	}
+}
+
+// collectSamples는 오류 없이 끝난 결정만 모읍니다.
+// 모두 실패했는데 그 이유가 전부 읽을 수 없는 답변이었다면 fallbackDecision 하나를, 아니면 오류들을 합쳐 반환합니다.
+func collectSamples(decisions []routingDecision, errs []error, query string) ([]routingDecision, error) {
+	var samples []routingDecision
+	invalid := 0
+	for i, d := range decisions {
+		switch {
+		case errs[i] == nil:
+			samples = append(samples, d)
+		case errors.Is(errs[i], errInvalidDecision):
+			invalid++
+		}
+	}
+	if len(samples) > 0 {
+		return samples, nil
+	}
+	err := fmt.Errorf("all %d router samples failed: %w", len(decisions), errors.Join(errs...))
+	if invalid == len(decisions) {
+		return []routingDecision{fallbackDecision(query, err)}, nil
+	}
+	return nil, err
+}
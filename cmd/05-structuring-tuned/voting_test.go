@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func decision(dest string, confidence float64) routingDecision {
+	return routingDecision{Destination: dest, Confidence: confidence}
+}
+
+func TestVoteTieBreaking(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []routingDecision
+		want    string
+	}{
+		{
+			name:    "표가 많은 목적지",
+			samples: []routingDecision{decision(destBilling, 0.5), decision(destBilling, 0.5), decision(destTechnical, 1)},
+			want:    destBilling,
+		},
+		{
+			name:    "표가 같으면 평균 확신도가 높은 목적지",
+			samples: []routingDecision{decision(destTechnical, 0.5), decision(destBilling, 0.9), decision(destTechnical, 0.6), decision(destBilling, 0.8)},
+			want:    destBilling,
+		},
+		{
+			name:    "확신도까지 같으면 destinations 순서",
+			samples: []routingDecision{decision(destEscalate, 0.7), decision(destGeneral, 0.7), decision(destBilling, 0.7)},
+			want:    destBilling,
+		},
+		{
+			name:    "샘플 순서와 상관없음",
+			samples: []routingDecision{decision(destBilling, 0.7), decision(destGeneral, 0.7), decision(destEscalate, 0.7)},
+			want:    destBilling,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vote(tt.samples, 0, 0).Destination; got != tt.want {
+				t.Errorf("destination = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoteKeepsBestDecisionOfWinner(t *testing.T) {
+	low := routingDecision{Destination: destTechnical, Confidence: 0.4, Reasoning: "low"}
+	high := routingDecision{Destination: destTechnical, Confidence: 0.8, Reasoning: "high"}
+	other := routingDecision{Destination: destBilling, Confidence: 0.9, ClarifyingQuestion: "결제 문제인가요?"}
+
+	d := vote([]routingDecision{low, other, high}, 0, 0)
+	if d.Reasoning != "high" {
+		t.Errorf("reasoning = %q, want the most confident sample of the winner", d.Reasoning)
+	}
+	if math.Abs(d.Confidence-0.6) > 1e-9 {
+		t.Errorf("confidence = %v, want average 0.6", d.Confidence)
+	}
+	if want := 2.0 / 3; d.Agreement != want {
+		t.Errorf("agreement = %v, want %v", d.Agreement, want)
+	}
+	// 이긴 쪽이 질문을 쓰지 않았으면 다른 샘플의 질문을 빌려 씁니다.
+	if d.question() != "결제 문제인가요?" {
+		t.Errorf("question = %q", d.question())
+	}
+}
+
+func TestVoteClarify(t *testing.T) {
+	// technical 2표(평균 0.3), billing 1표 → 확신도 0.3, 일치율 2/3
+	samples := []routingDecision{decision(destTechnical, 0.2), decision(destTechnical, 0.4), decision(destBilling, 0.9)}
+
+	tests := []struct {
+		name          string
+		minConfidence float64
+		minAgreement  float64
+		want          bool
+	}{
+		{"기준이 0이면 되묻지 않음", 0, 0, false},
+		{"확신도가 기준보다 낮음", 0.5, 0, true},
+		{"일치율이 기준보다 낮음", 0, 0.7, true},
+		{"둘 다 기준 이상", 0.25, 0.6, false},
+		{"기준이 1이면 확신도와 일치율이 모두 1이어야 함", 1, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vote(samples, tt.minConfidence, tt.minAgreement).Clarify; got != tt.want {
+				t.Errorf("clarify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 확신도 0인 답이 하나뿐이어도 기준이 0이면 그대로 보냅니다.
+	if d := vote([]routingDecision{decision(destGeneral, 0)}, 0, 0); d.Clarify {
+		t.Errorf("clarify with zero thresholds: %+v", d)
+	}
+	if d := vote([]routingDecision{decision(destGeneral, 0)}, 0, 0); d.question() != clarifyFallback {
+		t.Errorf("question = %q, want fallback", d.question())
+	}
+}
+
+func TestValidateVoting(t *testing.T) {
+	tests := []struct {
+		name          string
+		samples       int
+		minConfidence float64
+		minAgreement  float64
+		wantErr       bool
+	}{
+		{"기본값", 1, defaultConfidenceThreshold, defaultMinAgreement, false},
+		{"경계값", 5, 0, 1, false},
+		{"샘플 0번", 0, 0.6, 0.6, true},
+		{"음수 확신도 기준", 3, -0.1, 0.6, true},
+		{"1보다 큰 확신도 기준", 3, 1.5, 0.6, true},
+		{"음수 일치율 기준", 3, 0.6, -1, true},
+		{"1보다 큰 일치율 기준", 3, 0.6, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVoting(tt.samples, tt.minConfidence, tt.minAgreement)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/handoff"
+	"awesomeProject2/internal/schema"
+)
+
+// 라우터가 고를 수 있는 목적지
+const (
+	destTechnical = "technical_support"
+	destBilling   = "billing_inquiry"
+	destGeneral   = "general_chat"
+	destEscalate  = "escalate_to_human"
+)
+
+var destinations = []string{destTechnical, destBilling, destGeneral, destEscalate}
+
+// 우선순위 (높은 것부터)
+var priorities = []string{"high", "medium", "low"}
+
+// routingDecision은 router_agent의 답변입니다. OutputSchema도 이 구조체의 태그로 만듭니다(newRouterAgent).
+// destination의 enum은 destinations와 같아야 합니다.
+type routingDecision struct {
+	// 어디로 보낼지 (Enum으로 선택지를 고정해 엉뚱한 값을 막습니다)
+	Destination string `json:"destination" enum:"technical_support,billing_inquiry,general_chat,escalate_to_human" description:"The target agent or department to handle the user query."`
+	// 분류 이유 (디버깅 및 검증용)
+	Reasoning string `json:"reasoning" description:"Explanation of why this destination was chosen."`
+	// 사용자 의도 요약 (다음 에이전트에게 넘겨주기 위함)
+	IntentSummary string `json:"intent_summary" description:"A concise summary of what the user wants to achieve."`
+	// 난이도/우선순위 (비어 있으면 medium)
+	Priority string `json:"priority,omitempty" enum:"high,medium,low"`
+	// 확신도 (낮으면 보내지 않고 되묻습니다)
+	Confidence float64 `json:"confidence" minimum:"0" maximum:"1" description:"How sure you are about the destination, from 0.0 (guess) to 1.0 (certain)."`
+	// 애매할 때 사용자에게 할 질문
+	ClarifyingQuestion string `json:"clarifying_question,omitempty" description:"One short question, in the user's language, that would let you choose the destination with certainty."`
+
+	// 아래는 라우터의 출력이 아니라 워크플로가 채웁니다.
+	RoutedBy  string  `json:"-"` // 어느 단계(layerRules, layerLLM)가 분류했는지
+	Agreement float64 `json:"-"` // 여러 번 물었을 때 다수결 목적지를 고른 비율 (한 번만 물었으면 1)
+	Clarify   bool    `json:"-"` // 확신도나 일치율이 기준보다 낮아 보내지 않고 되물어야 하는지
+}
+
+// errInvalidDecision은 라우터의 답변이 routingDecision 스키마에 맞지 않을 때 반환됩니다.
+var errInvalidDecision = errors.New("router returned an invalid decision")
+
+// parseDecision은 라우터의 최종 답변(JSON)을 스키마로 검사해 읽습니다.
+func parseDecision(text string) (routingDecision, error) {
+	d, err := schema.Decode[routingDecision](text)
+	if err != nil {
+		return routingDecision{}, fmt.Errorf("%w: %w", errInvalidDecision, err)
+	}
+	if d.Priority == "" {
+		d.Priority = "medium"
+	}
+	d.RoutedBy = layerLLM
+	return d, nil
+}
+
+// fallbackDecision은 라우터의 답변을 읽을 수 없을 때 대신 쓰는 결정입니다. 그 턴을 실패시키지 않고,
+// 확신도를 0으로 두어 확신도 기준이 있으면 되묻고, 기준이 0이거나 바로 전에 되물었다면 general_chat으로 보냅니다.
+func fallbackDecision(query string, err error) routingDecision {
+	log.Printf("[router] falling back to %s: %v", destGeneral, err)
+	return routingDecision{
+		Destination:   destGeneral,
+		Priority:      "medium",
+		Reasoning:     err.Error(),
+		IntentSummary: snippet(query, 200),
+		RoutedBy:      layerLLM,
+	}
+}
+
+// layerStats는 워크플로가 실행되는 동안 각 단계가 분류한 메시지 수입니다.
+type layerStats struct {
+	mu     sync.Mutex
+	counts map[string]int
+	total  int
+}
+
+// add는 layer가 메시지 하나를 분류했다고 기록하고, 지금까지의 비율을 한 줄로 돌려줍니다.
+func (s *layerStats) add(layer string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.counts[layer]++
+	s.total++
+	var parts []string
+	for _, l := range []string{layerRules, layerLLM} {
+		parts = append(parts, fmt.Sprintf("%s %d/%d", l, s.counts[l], s.total))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// routingOptions는 워크플로가 메시지를 분류하는 방식입니다.
+type routingOptions struct {
+	Pre *preRouter // 규칙 라우터. nil이면 항상 router를 씁니다.
+	// Samples는 router에 몇 번 물어 다수결할지입니다. 1 이하면 한 번만 묻고 그 이벤트를 그대로 내보냅니다.
+	Samples int
+	// 확신도가 MinConfidence보다, 또는 일치율이 MinAgreement보다 낮으면 보내지 않고 되묻습니다. 0이면 그 기준으로는 되묻지 않습니다.
+	MinConfidence float64
+	MinAgreement  float64
+}
+
+// newRoutingWorkflow는 메시지를 분류하고, 그 결정(destination)에 맞는 전문 에이전트를 이어서 실행하는 에이전트를 만듭니다.
+//
+//  1. opts.Pre(규칙)가 확실하게 분류하면 그대로 씁니다. 아니면 router를 opts.Samples번 실행해 다수결(vote)합니다.
+//     한 번만 물을 때는 router의 이벤트를 그대로 내보내면서 마지막 답변(JSON)을 읽습니다.
+//     답변을 하나도 읽을 수 없으면 턴을 실패시키지 않고 fallbackDecision을 씁니다.
+//  2. 확신도나 일치율이 opts.MinConfidence보다 낮으면 보내지 않고 되묻습니다. 사용자가 답하면 다음 턴에 다시 분류하는데,
+//     연달아 되묻지는 않고 그때는 가장 나은 목적지로 보냅니다.
+//  3. 결정을 상태(intent_summary, destination, priority, routed_by, routing_confidence, routing_agreement)에 기록합니다.
+//     전문 에이전트는 지시문에서 {intent_summary}로 읽습니다.
+//  4. escalate_to_human이면 티켓을 만들어 queue에 넣고 접수 번호를 안내합니다. 그 밖에는 specialists[destination]을 실행합니다.
+func newRoutingWorkflow(router agent.Agent, specialists map[string]agent.Agent, queue *handoff.Queue, opts routingOptions) (agent.Agent, error) {
+	subAgents := []agent.Agent{router}
+	for _, dest := range destinations {
+		if a, ok := specialists[dest]; ok {
+			subAgents = append(subAgents, a)
+		}
+	}
+
+	const name = "support_workflow"
+	var stats layerStats
+	return agent.New(agent.Config{
+		Name:        name,
+		Description: "Routes the user's request with router_agent and hands it to the matching specialist agent or a human operator.",
+		SubAgents:   subAgents,
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				// 1. 분류 (규칙으로 확실하면 LLM 라우터를 건너뜁니다)
+				var decision routingDecision
+				ok := false
+				if opts.Pre != nil {
+					decision, ok = opts.Pre.Route(contentText(ic.UserContent()))
+				}
+				if !ok {
+					var samples []routingDecision
+					if opts.Samples <= 1 {
+						var answer string
+						for event, err := range router.Run(ic) {
+							if err != nil {
+								yield(nil, err)
+								return
+							}
+							if event != nil && !event.Partial && event.Content != nil {
+								answer = contentText(event.Content)
+							}
+							if !yield(event, nil) {
+								return
+							}
+						}
+						d, err := parseDecision(answer)
+						if err != nil {
+							d = fallbackDecision(contentText(ic.UserContent()), err)
+						}
+						samples = []routingDecision{d}
+					} else {
+						var err error
+						samples, err = sampleRouter(ic, router, opts.Samples)
+						if err != nil {
+							yield(nil, err)
+							return
+						}
+					}
+					decision = vote(samples, opts.MinConfidence, opts.MinAgreement)
+				}
+				log.Printf("[router] %s → %s (priority=%s, confidence=%.2f, agreement=%.2f): %s [%s]",
+					decision.RoutedBy, decision.Destination, decision.Priority, decision.Confidence, decision.Agreement, decision.Reasoning, stats.add(decision.RoutedBy))
+
+				event := session.NewEvent(ic.InvocationID())
+				event.Author = name
+				event.Branch = ic.Branch()
+
+				// 2. 확신이 없으면 되묻기 (바로 전 턴에 이미 되물었다면 건너뜁니다)
+				if decision.Clarify && !clarificationAsked(ic) {
+					log.Printf("[router] asking a clarifying question instead of routing")
+					event.Actions.StateDelta = map[string]any{
+						"clarification_asked": true,
+						"routing_confidence":  decision.Confidence,
+						"routing_agreement":   decision.Agreement,
+					}
+					event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(decision.question(), genai.RoleModel)}
+					yield(event, nil)
+					return
+				}
+
+				// 3. 결정을 상태에 기록
+				event.Actions.StateDelta = map[string]any{
+					"destination":         decision.Destination,
+					"intent_summary":      decision.IntentSummary,
+					"priority":            decision.Priority,
+					"routed_by":           decision.RoutedBy,
+					"routing_confidence":  decision.Confidence,
+					"routing_agreement":   decision.Agreement,
+					"clarification_asked": false,
+				}
+
+				// 4. 사람에게 넘기기 또는 전문 에이전트 실행 (담당 에이전트가 없는 목적지도 사람에게 넘깁니다)
+				specialist, ok := specialists[decision.Destination]
+				if decision.Destination == destEscalate || !ok {
+					s := ic.Session()
+					ticket, err := queue.Enqueue(handoff.Ticket{
+						AppName:   s.AppName(),
+						UserID:    s.UserID(),
+						SessionID: s.ID(),
+						Summary:   decision.IntentSummary,
+						Reason:    decision.Reasoning,
+						Priority:  decision.Priority,
+						Message:   contentText(ic.UserContent()),
+					})
+					if err != nil {
+						yield(nil, fmt.Errorf("failed to enqueue handoff ticket: %w", err))
+						return
+					}
+					event.Actions.StateDelta["handoff_ticket"] = ticket.ID
+					event.Actions.StateDelta["handoff_ticket_status"] = string(ticket.Status)
+					event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(
+						fmt.Sprintf("상담원에게 연결해 드릴게요. 접수 번호는 %s이고, 상담원의 답변은 이 대화로 전해 드리겠습니다.", ticket.ID), genai.RoleModel)}
+					yield(event, nil)
+					return
+				}
+				if !yield(event, nil) {
+					return
+				}
+				for event, err := range specialist.Run(ic) {
+					if !yield(event, err) {
+						return
+					}
+				}
+			}
+		},
+	})
+}
+
+// clarificationAsked는 바로 전 턴에 워크플로가 되물었는지 알려줍니다.
+func clarificationAsked(ic agent.InvocationContext) bool {
+	v, err := ic.Session().State().Get("clarification_asked")
+	asked, _ := v.(bool)
+	return err == nil && asked
+}
+
+// contentText는 Content의 텍스트 파트(생각 제외)를 이어 붙입니다.
+func contentText(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, p := range c.Parts {
+		if p.Text != "" && !p.Thought {
+			sb.WriteString(p.Text)
+		}
+	}
+	return sb.String()
+}
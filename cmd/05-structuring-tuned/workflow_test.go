@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/handoff"
+)
+
+// textEvent는 author가 text를 말한 이벤트를 만듭니다.
+func textEvent(ic agent.InvocationContext, author, text string) *session.Event {
+	ev := session.NewEvent(ic.InvocationID())
+	ev.Author = author
+	ev.Branch = ic.Branch()
+	ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)}
+	return ev
+}
+
+// fakeRouter는 LLM 대신 정해 둔 답변을 차례로 내놓는 라우터입니다. 답변이 떨어지면 마지막 답변을 되풀이합니다.
+type fakeRouter struct {
+	mu      sync.Mutex
+	answers []string
+	calls   int
+}
+
+func (f *fakeRouter) agent(t *testing.T) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name:        "router_agent",
+		Description: "fake router",
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				f.mu.Lock()
+				answer := f.answers[min(f.calls, len(f.answers)-1)]
+				f.calls++
+				f.mu.Unlock()
+				yield(textEvent(ic, "router_agent", answer), nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// fakeSpecialist는 자기 이름과 상태의 intent_summary를 답하는 전문 에이전트입니다.
+func fakeSpecialist(t *testing.T, name string) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name:        name,
+		Description: "fake " + name,
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				summary, _ := ic.Session().State().Get("intent_summary")
+				yield(textEvent(ic, name, fmt.Sprintf("%s: %v", name, summary)), nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func answer(dest string, confidence float64, question string) string {
+	return fmt.Sprintf(`{"destination": %q, "reasoning": "test", "intent_summary": "요약 %s", "priority": "high", "confidence": %v, "clarifying_question": %q}`,
+		dest, dest, confidence, question)
+}
+
+// workflowHarness는 워크플로 하나를 한 세션에서 여러 턴 실행합니다.
+type workflowHarness struct {
+	t        *testing.T
+	router   *fakeRouter
+	queue    *handoff.Queue
+	sessions session.Service
+	runner   *runner.Runner
+	id       string
+}
+
+// newHarness는 specialists에 적힌 목적지만 전문 에이전트를 둔 워크플로를 만듭니다.
+func newHarness(t *testing.T, answers []string, opts routingOptions, specialists ...string) *workflowHarness {
+	t.Helper()
+	h := &workflowHarness{t: t, router: &fakeRouter{answers: answers}, queue: handoff.NewInMemory(), sessions: session.InMemoryService()}
+	agents := make(map[string]agent.Agent)
+	for _, name := range specialists {
+		agents[name] = fakeSpecialist(t, name)
+	}
+	workflow, err := newRoutingWorkflow(h.router.agent(t), agents, h.queue, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.runner, err = runner.New(runner.Config{AppName: "support", Agent: workflow, SessionService: h.sessions}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := h.sessions.Create(t.Context(), &session.CreateRequest{AppName: "support", UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.id = created.Session.ID()
+	return h
+}
+
+// turn은 사용자 메시지 하나를 보내고, 워크플로와 전문 에이전트가 한 말을 "작성자: 내용"으로 돌려줍니다. 라우터의 이벤트는 뺍니다.
+func (h *workflowHarness) turn(text string) []string {
+	h.t.Helper()
+	var said []string
+	for event, err := range h.runner.Run(h.t.Context(), "alice", h.id, genai.NewContentFromText(text, genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			h.t.Fatalf("turn %q failed: %v", text, err)
+		}
+		if event.Author != "router_agent" && event.Content != nil {
+			said = append(said, event.Author+": "+contentText(event.Content))
+		}
+	}
+	return said
+}
+
+func (h *workflowHarness) state(key string) any {
+	h.t.Helper()
+	res, err := h.sessions.Get(h.t.Context(), &session.GetRequest{AppName: "support", UserID: "alice", SessionID: h.id})
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	v, _ := res.Session.State().Get(key)
+	return v
+}
+
+var allSpecialists = []string{destTechnical, destBilling, destGeneral}
+
+func TestWorkflowRunsMatchingSpecialist(t *testing.T) {
+	h := newHarness(t, []string{answer(destBilling, 0.9, "")}, routingOptions{MinConfidence: 0.6, MinAgreement: 0.6}, allSpecialists...)
+	said := h.turn("청구서가 이상해요")
+
+	if len(said) != 1 || said[0] != destBilling+": "+destBilling+": 요약 "+destBilling {
+		t.Errorf("said = %q, want only the billing specialist with the intent summary", said)
+	}
+	for key, want := range map[string]any{
+		"intent_summary": "요약 " + destBilling,
+		"destination":    destBilling,
+		"priority":       "high",
+		"routed_by":      layerLLM,
+	} {
+		if got := h.state(key); got != want {
+			t.Errorf("state %s = %v, want %v", key, got, want)
+		}
+	}
+	if n := len(h.queue.Pending("")); n != 0 {
+		t.Errorf("%d tickets created for a routed message", n)
+	}
+}
+
+func TestWorkflowCreatesTicket(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		specialists []string
+	}{
+		{"escalate_to_human", destEscalate, allSpecialists},
+		{"destination without a specialist", destBilling, []string{destTechnical, destGeneral}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t, []string{answer(tt.destination, 0.9, "")}, routingOptions{}, tt.specialists...)
+			said := h.turn("책임자 바꿔 주세요")
+
+			tickets := h.queue.Pending("")
+			if len(tickets) != 1 {
+				t.Fatalf("%d tickets, want 1", len(tickets))
+			}
+			ticket := tickets[0]
+			if ticket.SessionID != h.id || ticket.UserID != "alice" || ticket.Message != "책임자 바꿔 주세요" || ticket.Priority != "high" {
+				t.Errorf("ticket = %+v", ticket)
+			}
+			if len(said) != 1 || !strings.HasPrefix(said[0], "support_workflow: ") || !strings.Contains(said[0], ticket.ID) {
+				t.Errorf("said = %q, want the ticket number from the workflow", said)
+			}
+			if got := h.state("handoff_ticket"); got != ticket.ID {
+				t.Errorf("state handoff_ticket = %v, want %s", got, ticket.ID)
+			}
+		})
+	}
+}
+
+func TestWorkflowClarifiesOnce(t *testing.T) {
+	low := answer(destTechnical, 0.3, "어떤 오류가 나나요?")
+	h := newHarness(t, []string{low}, routingOptions{MinConfidence: 0.6, MinAgreement: 0.6}, allSpecialists...)
+
+	// 첫 턴: 확신이 없으므로 보내지 않고 되묻습니다.
+	if said := h.turn("이거 왜 이래요?"); len(said) != 1 || said[0] != "support_workflow: 어떤 오류가 나나요?" {
+		t.Errorf("first turn said %q, want the clarifying question", said)
+	}
+	if h.state("clarification_asked") != true {
+		t.Error("clarification_asked is not set")
+	}
+
+	// 다음 턴: 여전히 확신이 없어도 연달아 되묻지 않고 가장 나은 목적지로 보냅니다.
+	if said := h.turn("그냥 안 돼요"); len(said) != 1 || !strings.HasPrefix(said[0], destTechnical+": ") {
+		t.Errorf("second turn said %q, want the technical specialist", said)
+	}
+	if h.state("clarification_asked") != false {
+		t.Error("clarification_asked is not cleared after routing")
+	}
+
+	// 그다음 턴은 다시 되물을 수 있습니다.
+	if said := h.turn("또 안 돼요"); len(said) != 1 || said[0] != "support_workflow: 어떤 오류가 나나요?" {
+		t.Errorf("third turn said %q, want the clarifying question again", said)
+	}
+}
+
+func TestWorkflowFallsBackOnInvalidDecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples int
+		minConf float64
+		want    string
+	}{
+		{"한 번 묻고 되묻기", 1, 0.6, "support_workflow: " + clarifyFallback},
+		{"한 번 묻고 기준 0이면 general_chat", 1, 0, destGeneral + ": "},
+		{"여러 번 묻고 모두 읽을 수 없음", 3, 0.6, "support_workflow: " + clarifyFallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t, []string{"죄송하지만 분류할 수 없습니다"}, routingOptions{Samples: tt.samples, MinConfidence: tt.minConf}, allSpecialists...)
+			if said := h.turn("음..."); len(said) != 1 || !strings.HasPrefix(said[0], tt.want) {
+				t.Errorf("said = %q, want %q", said, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowSkipsRouterWhenRulesMatch(t *testing.T) {
+	pre, err := newPreRouter(defaultRules, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHarness(t, []string{answer(destGeneral, 0.9, "")}, routingOptions{Pre: pre}, allSpecialists...)
+	if said := h.turn("결제가 두 번 됐어요"); len(said) != 1 || !strings.HasPrefix(said[0], destBilling+": ") {
+		t.Errorf("said = %q, want the billing specialist", said)
+	}
+	if h.router.calls != 0 {
+		t.Errorf("router was called %d times for a rule match", h.router.calls)
+	}
+	if got := h.state("routed_by"); got != layerRules {
+		t.Errorf("routed_by = %v, want %s", got, layerRules)
+	}
+}
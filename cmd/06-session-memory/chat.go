@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/session"
+
+	"awesomeProject2/internal/memstore"
+	"awesomeProject2/internal/profilestore"
+	"awesomeProject2/internal/repl"
+	"awesomeProject2/internal/sessionstore"
+)
+
+// --- REPL 상태 (현재 사용자와 세션) ---
+
+// chat은 REPL에서 지금 대화 중인 사용자와 세션입니다. /user, /new, /resume으로 바꿉니다.
+// 기억과 프로필은 모두 사용자 ID로 나뉘어 있으므로, 사용자를 바꾸면 다른 사용자의 기억은 검색되지 않습니다.
+type chat struct {
+	appName   string
+	userID    string
+	sessionID string
+
+	sessions *sessionstore.Service
+	memory   *memstore.Service
+	profiles *profilestore.Store
+}
+
+// open은 현재 사용자의 sessionID 세션이 있으면 이어가고, 없으면 새로 만듭니다. sessionID가 비어 있으면 새 ID를 만듭니다.
+func (c *chat) open(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		sessionID = c.newSessionID(ctx)
+	}
+	if existing, err := c.sessions.Get(ctx, &session.GetRequest{AppName: c.appName, UserID: c.userID, SessionID: sessionID}); err == nil {
+		fmt.Printf(">>> [%s] 세션 %s을(를) 이어갑니다. (이전 이벤트 %d개)\n", c.userID, sessionID, existing.Session.Events().Len())
+	} else {
+		if _, err := c.sessions.Create(ctx, &session.CreateRequest{AppName: c.appName, UserID: c.userID, SessionID: sessionID}); err != nil {
+			return err
+		}
+		fmt.Printf(">>> [%s] 새 세션: %s\n", c.userID, sessionID)
+	}
+	c.sessionID = sessionID
+	return nil
+}
+
+// newSessionID는 session-20250101-120000 형식의 ID를 만듭니다. 같은 초에 이미 세션이 있으면 뒤에 번호를 붙입니다.
+func (c *chat) newSessionID(ctx context.Context) string {
+	base := "session-" + time.Now().Format("20060102-150405")
+	id := base
+	for n := 2; ; n++ {
+		if _, err := c.sessions.Get(ctx, &session.GetRequest{AppName: c.appName, UserID: c.userID, SessionID: id, NumRecentEvents: 1}); err != nil {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// commands는 사용자/세션 전환과 기억 관리 명령입니다. 명령은 에이전트를 거치지 않고 직접 처리합니다.
+func (c *chat) commands() []repl.Command {
+	return []repl.Command{
+		{Name: "/user", Usage: "/user <id>", Help: "사용자 바꾸기 (그 사용자의 새 세션 시작)", Run: c.switchUser},
+		{Name: "/new", Help: "새 세션 시작", Run: c.newSession},
+		{Name: "/sessions", Help: "내 세션 목록", Run: c.listSessions},
+		{Name: "/resume", Usage: "/resume <id>", Help: "이전 세션 이어가기", Run: c.resumeSession},
+		{Name: "/memories", Help: "기억 목록", Run: c.listMemories},
+		{Name: "/forget", Usage: "/forget <id|all>", Help: "기억 하나 삭제 (all: 전체 삭제)", Run: c.forget},
+		{Name: "/export", Usage: "/export [path]", Help: "기억과 프로필을 JSON으로 내보내기", Run: c.export},
+	}
+}
+
+func (c *chat) switchUser(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return repl.ErrUsage
+	}
+	prev := c.userID
+	c.userID = args[0]
+	if err := c.open(ctx, ""); err != nil {
+		c.userID = prev
+		return fmt.Errorf("사용자 전환 실패: %w", err)
+	}
+	return nil
+}
+
+func (c *chat) newSession(ctx context.Context, _ []string) error {
+	if err := c.open(ctx, ""); err != nil {
+		return fmt.Errorf("세션 생성 실패: %w", err)
+	}
+	return nil
+}
+
+func (c *chat) listSessions(ctx context.Context, _ []string) error {
+	resp, err := c.sessions.List(ctx, &session.ListRequest{AppName: c.appName, UserID: c.userID})
+	if err != nil {
+		return fmt.Errorf("세션 조회 실패: %w", err)
+	}
+	for _, s := range resp.Sessions {
+		marker := " "
+		if s.ID() == c.sessionID {
+			marker = "*"
+		}
+		fmt.Printf("%s %s  (마지막 대화 %s)\n", marker, s.ID(), s.LastUpdateTime().Format(time.DateTime))
+	}
+	fmt.Printf("(%s의 세션 %d개)\n", c.userID, len(resp.Sessions))
+	return nil
+}
+
+func (c *chat) resumeSession(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return repl.ErrUsage
+	}
+	// 다른 사용자의 세션은 찾을 수 없습니다(세션도 사용자별로 나뉘어 있습니다).
+	if _, err := c.sessions.Get(ctx, &session.GetRequest{AppName: c.appName, UserID: c.userID, SessionID: args[0], NumRecentEvents: 1}); err != nil {
+		return fmt.Errorf("%s의 세션 %s이(가) 없습니다. /sessions로 목록을 확인하세요", c.userID, args[0])
+	}
+	if err := c.open(ctx, args[0]); err != nil {
+		return fmt.Errorf("세션 전환 실패: %w", err)
+	}
+	return nil
+}
+
+func (c *chat) listMemories(ctx context.Context, _ []string) error {
+	entries, err := c.memory.List(ctx, c.appName, c.userID)
+	if err != nil {
+		return fmt.Errorf("기억 조회 실패: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("저장된 기억이 없습니다.")
+		return nil
+	}
+	for _, e := range entries {
+		m := toStoredMemory(e, 80)
+		fmt.Printf("[%s] %s %-5s %s\n", m.ID, m.Time, m.Author, m.Text)
+	}
+	fmt.Printf("(%d개)\n", len(entries))
+	return nil
+}
+
+func (c *chat) forget(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return repl.ErrUsage
+	}
+	n, err := forgetMemories(ctx, c.memory, c.appName, c.userID, args[0], args[0] == "all")
+	if err != nil {
+		return fmt.Errorf("삭제 실패: %w", err)
+	}
+	fmt.Printf("기억 %d개를 지웠습니다.\n", n)
+	return nil
+}
+
+func (c *chat) export(ctx context.Context, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	}
+	res, err := exportUserData(ctx, c.memory, c.profiles, c.appName, c.userID, path)
+	if err != nil {
+		return fmt.Errorf("내보내기 실패: %w", err)
+	}
+	fmt.Printf("기억 %d개, 프로필 %d개를 %s에 저장했습니다.\n", res.Memories, res.Facts, res.Path)
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/adk/session"
+
+	"awesomeProject2/internal/sessionstore"
+)
+
+// 프로세스를 다시 시작한 뒤 /resume 하면 그 세션의 이벤트와 상태가 그대로 돌아와야 합니다.
+func TestResumeRestoresSession(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	store, err := sessionstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &chat{appName: "memory_bot", userID: "alice", sessions: store}
+	if err := c.open(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := c.sessionID
+
+	res, err := store.Get(ctx, &session.GetRequest{AppName: c.appName, UserID: c.userID, SessionID: sessionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := session.NewEvent("inv-test")
+	ev.Author = "user"
+	ev.Actions.StateDelta = map[string]any{"trip": "부산", "user:name": "Alice"}
+	if err := store.AppendEvent(ctx, res.Session, ev); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// 다시 시작: 새 세션으로 시작한 뒤 이전 세션으로 돌아갑니다.
+	if store, err = sessionstore.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	c = &chat{appName: "memory_bot", userID: "alice", sessions: store}
+	if err := c.open(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.resumeSession(ctx, []string{sessionID}); err != nil {
+		t.Fatal(err)
+	}
+	if c.sessionID != sessionID {
+		t.Fatalf("current session = %s, want %s", c.sessionID, sessionID)
+	}
+	res, err = store.Get(ctx, &session.GetRequest{AppName: c.appName, UserID: c.userID, SessionID: c.sessionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := res.Session.Events().Len(); n != 1 {
+		t.Errorf("resumed session has %d events, want 1", n)
+	}
+	for k, want := range map[string]any{"trip": "부산", "user:name": "Alice"} {
+		if got, _ := res.Session.State().Get(k); got != want {
+			t.Errorf("state %s = %v, want %v", k, got, want)
+		}
+	}
+
+	// 다른 사용자는 그 세션을 이어갈 수 없습니다.
+	bob := &chat{appName: "memory_bot", userID: "bob", sessions: store}
+	if err := bob.resumeSession(ctx, []string{sessionID}); err == nil {
+		t.Error("bob resumed alice's session")
+	}
+}
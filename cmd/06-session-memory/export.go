@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"awesomeProject2/internal/transcript"
+)
+
+// --- 대화록 내보내기 (export 하위 명령) ---
+
+// runExport는 --session_store=file로 저장한 세션을 대화록으로 내보냅니다.
+//
+//	go run ./cmd/06-session-memory export --session_id session-20250101-120000 --format md,html
+func runExport(args []string) {
+	cmd := transcript.ExportCommand{AppName: "MemoryApp", SessionFile: "data/sessions.jsonl"}
+	if err := cmd.Run(context.Background(), args); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"strings"
+	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
@@ -18,6 +21,11 @@ import (
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
+
+	"awesomeProject2/internal/memstore"
+	"awesomeProject2/internal/profilestore"
+	"awesomeProject2/internal/repl"
+	"awesomeProject2/internal/sessionstore"
 )
 
 // --- Tool 정의 ---
@@ -30,80 +38,242 @@ type Args struct {
 // wtf is launcher exactly?
 // when the launcher runs, event happens
 
+// Memory는 검색된 기억 한 건입니다. Score는 BM25 점수로, 높을수록 질문과 관련이 깊습니다.
+type Memory struct {
+	ID     string  `json:"id"`
+	Text   string  `json:"text"`
+	Score  float64 `json:"score"`
+	Author string  `json:"author"`
+	Time   string  `json:"time"`
+}
+
 type Result struct {
-	Results []string `json:"results"`
+	Results []Memory `json:"results"`
+	Message string   `json:"message,omitempty"`
 }
 
-// memorySearchToolFunc: 단순 텍스트 검색을 수행하지만, 검색어를 띄어쓰기 단위로 쪼개서 유연하게 찾도록 개선
-func memorySearchToolFunc(tctx tool.Context, args Args) (Result, error) {
-	fmt.Printf("\n[Tool] 검색어: '%s'", args.Query)
+// 도구 결과에 담는 기억 한 건의 최대 길이 (긴 답변이 컨텍스트를 다 차지하지 않도록)
+const maxSnippetRunes = 300
 
-	// 1. 기본 검색 (라이브러리 제공 기능)
-	searchResults, err := tctx.SearchMemory(context.Background(), args.Query)
-	if err != nil {
-		log.Printf("Error searching memory: %v", err)
-		return Result{}, fmt.Errorf("failed memory search")
-	}
+// newMemorySearchTool은 BM25 점수 순으로 기억을 검색하는 도구를 만듭니다.
+// tctx.SearchMemory는 점수를 돌려주지 않으므로 memstore를 직접 검색합니다.
+func newMemorySearchTool(store *memstore.Service, opts memstore.SearchOptions) tool.Tool {
+	return must(functiontool.New(
+		functiontool.Config{
+			Name: "search_past_conversations",
+			// 검색 색인이 조사/어미를 정리하므로 "이름", "이름은", "이름이" 중 어느 형태로 검색해도 됩니다.
+			Description: "Searches past conversations and returns the most relevant snippets with relevance scores (higher is more relevant). " +
+				"Search with keywords in the language the user is speaking.",
+		},
+		func(tctx tool.Context, args Args) (Result, error) {
+			fmt.Printf("\n[Tool] 검색어: '%s'", args.Query)
 
-	var results []string
-	seen := make(map[string]bool) // 중복 제거용
+			scored, err := store.SearchScored(tctx, &memory.SearchRequest{
+				Query:   args.Query,
+				UserID:  tctx.UserID(),
+				AppName: tctx.AppName(),
+			}, opts)
+			if err != nil {
+				log.Printf("Error searching memory: %v", err)
+				return Result{}, fmt.Errorf("failed memory search")
+			}
 
-	for _, res := range searchResults.Memories {
-		if res.Content != nil {
-			text := strings.Join(textParts(res.Content), " ")
-			// 중복된 내용은 제외
-			if !seen[text] {
-				results = append(results, text)
-				seen[text] = true
+			// 기억은 이벤트마다 한 번만 저장되므로 같은 발화가 중복해서 나오지 않습니다.
+			var results []Memory
+			for _, se := range scored {
+				text := strings.Join(textParts(se.Content), " ")
+				results = append(results, Memory{
+					ID:     shortID(se.ID),
+					Text:   snippet(text, maxSnippetRunes),
+					Score:  math.Round(se.Score*100) / 100,
+					Author: se.Author,
+					Time:   se.Timestamp.Format(time.DateTime),
+				})
 			}
-		}
-	}
 
-	if len(results) == 0 {
-		fmt.Println(" -> 결과 없음")
-		return Result{Results: []string{"No relevant memories found."}}, nil
-	}
+			if len(results) == 0 {
+				fmt.Println(" -> 결과 없음")
+				return Result{Message: "No relevant memories found."}, nil
+			}
 
-	fmt.Printf(" -> %d개 찾음\n", len(results))
-	return Result{Results: results}, nil
+			fmt.Printf(" -> %d개 찾음 (최고 점수 %.2f)\n", len(results), results[0].Score)
+			return Result{Results: results}, nil
+		},
+	))
 }
 
-var memorySearchTool = must(functiontool.New(
-	functiontool.Config{
-		Name: "search_past_conversations",
-		// 설명(Description)에 한국어 검색을 강조합니다.
-		Description: "Searches past conversations. If the user speaks Korean, YOU MUST SEARCH IN KOREAN keywords (e.g., '이름', '좋아하는 것').",
-	},
-	memorySearchToolFunc,
-))
+// 모델 이름. replay 하위 명령의 --model로 다른 모델과 비교할 수 있습니다.
+const modelName = "gemini-3-pro-preview"
+
+// memoryInstruction은 root_agent의 지시문입니다. replay 하위 명령의 --instruction_file로 바꾼 지시문과 비교할 수 있습니다.
+// 한국어 검색 문제는 검색 색인(형태소 단위 토큰화)에서 해결하므로, 검색어 형태를 지정할 필요가 없습니다.
+// 다만 저장된 대화와 같은 언어로 검색해야 단어가 겹칩니다.
+const memoryInstruction = `You are a helpful assistant with a good memory.
+
+RULES FOR MEMORY:
+1. For personal info (name, preferences, dates), check 'get_user_profile' first.
+2. If the profile does not have it, or the user asks about past topics, use 'search_past_conversations'.
+3. Search in the same language as the conversation.
+4. If the user asks you to forget a fact about them, call 'forget_fact' with the fact's ID.
+5. If the user asks what you remember, use 'list_memories'. To forget a past message, call 'forget_memory' with its ID.
+   Only delete everything (all=true) when the user explicitly asks for it.
+6. If the user wants a copy of their data, call 'export_memories'.
+7. If a tool returns the information, answer naturally in the user's language.`
+
+// newRootAgent는 기억 도구들을 가진 root_agent를 만듭니다.
+func newRootAgent(llm model.LLM, instruction string, memoryService *memstore.Service, profiles *profilestore.Store, searchOpts memstore.SearchOptions) (agent.Agent, error) {
+	return llmagent.New(llmagent.Config{
+		Name:        "root_agent",
+		Model:       llm,
+		Instruction: instruction,
+		Tools: []tool.Tool{
+			newMemorySearchTool(memoryService, searchOpts),
+			newProfileTool(profiles),
+			newForgetFactTool(profiles),
+			newListMemoriesTool(memoryService),
+			newForgetMemoryTool(memoryService),
+			newExportTool(memoryService, profiles),
+		},
+	})
+}
 
 func main() {
+	// 하위 명령: 대화록 내보내기(export), 새 버전의 에이전트로 대화 다시 실행하기(replay)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "replay":
+			runReplay(os.Args[2:])
+			return
+		}
+	}
+
+	memoryBackend := flag.String("memory", "inmemory", "Memory backend: inmemory (lost on exit) or file (persisted)")
+	memoryFile := flag.String("memory_file", "data/memory.jsonl", "Memory file used when --memory=file")
+	topK := flag.Int("top_k", 5, "Maximum number of memories returned by the search tool")
+	minScore := flag.Float64("min_score", 0, "Memories scoring lower are treated as irrelevant (default depends on --search: bm25 0.5, vector 0.3, hybrid 0.2)")
+	searchMode := flag.String("search", "bm25", "Memory search: bm25 (keywords), vector (meaning) or hybrid (both)")
+	embedderName := flag.String("embedder", "gemini", "Embedder for vector/hybrid search: gemini or hashing (offline)")
+	vectorIndex := flag.String("vector_index", "bruteforce", "Vector index for vector/hybrid search: bruteforce or hnsw")
+	alpha := flag.Float64("alpha", memstore.DefaultAlpha, "Weight of the vector score in hybrid search (0-1)")
+	sessionBackend := flag.String("session_store", "inmemory", "Session backend: inmemory (lost on exit) or file (persisted)")
+	sessionFile := flag.String("session_file", "data/sessions.jsonl", "Session file used when --session_store=file")
+	userFlag := flag.String("user", "user1", "User ID to start with (switch later with /user <id>)")
+	resumeID := flag.String("session_id", "", "Resume this session if it exists (requires --session_store=file to survive restarts)")
+	maxAge := flag.Duration("max_age", 0, "Delete memories older than this, e.g. 720h (0 keeps them forever)")
+	maxMemories := flag.Int("max_memories", 0, "Keep at most this many memories per user, evicting the least recently retrieved (0 = unlimited)")
+	retentionInterval := flag.Duration("retention_interval", time.Minute, "How often the retention policy is applied in the background")
+	summarizeTokens := flag.Int("summarize_tokens", 8000, "Summarize older turns once the session exceeds about this many tokens (0 disables)")
+	keepRecent := flag.Int("keep_recent", 6, "Number of recent events kept verbatim when summarizing")
+	profileBackend := flag.String("profile", "inmemory", "User profile backend: inmemory (lost on exit) or file (persisted)")
+	profileFile := flag.String("profile_file", "data/profile.jsonl", "Profile file used when --profile=file")
+	extract := flag.Bool("extract_facts", true, "Extract durable user facts into the profile after each turn")
+	stream := flag.Bool("stream", true, "Stream the answer as it is generated")
+	historyFile := flag.String("history_file", "data/repl_history", "File keeping the REPL input history (empty disables)")
+	flag.Parse()
+
 	ctx := context.Background()
 
-	// 1. 모델 초기화 (gemini-1.5-flash 사용)
-	model, err := gemini.NewModel(ctx, "gemini-3-pro-preview", &genai.ClientConfig{})
+	mode := memstore.SearchMode(*searchMode)
+	minScoreSet := false
+	flag.Visit(func(f *flag.Flag) { minScoreSet = minScoreSet || f.Name == "min_score" })
+	if !minScoreSet {
+		*minScore = defaultMinScores[mode]
+	}
+	memoryOpts, err := vectorOptions(ctx, mode, *embedderName, *vectorIndex)
+	if err != nil {
+		log.Fatalf("Invalid memory search options: %v", err)
+	}
+	retention := memstore.RetentionPolicy{MaxAge: *maxAge, MaxEntriesPerUser: *maxMemories}
+	memoryOpts = append(memoryOpts, memstore.WithRetention(retention))
+
+	// 1. 모델 초기화
+	model, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{})
 	if err != nil {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
-	// 2. 서비스 초기화 (기본 InMemoryService 사용 - 컴파일 에러 방지)
-	sessionService := session.InMemoryService()
-	memoryService := memory.InMemoryService()
+	// 2. 서비스 초기화
+	// --session_store=file 이면 대화 자체를, --memory=file 이면 검색용 기억을 파일에 저장합니다.
+	// 긴 대화를 요약으로 압축(CompactEvents)하기 위해 메모리 모드에서도 sessionstore를 씁니다.
+	var sessionService *sessionstore.Service
+	switch *sessionBackend {
+	case "inmemory":
+		sessionService = sessionstore.NewInMemory()
+	case "file":
+		sessionService, err = sessionstore.Open(*sessionFile)
+		if err != nil {
+			log.Fatalf("Failed to open session file: %v", err)
+		}
+		defer sessionService.Close()
+		fmt.Printf(">>> 세션 파일: %s\n", *sessionFile)
+	default:
+		log.Fatalf("Unknown session backend %q (use inmemory or file)", *sessionBackend)
+	}
+
+	// 기억은 어느 쪽이든 memstore가 BM25로 검색합니다.
+	var memoryService *memstore.Service
+	switch *memoryBackend {
+	case "inmemory":
+		memoryService = memstore.NewInMemory(memoryOpts...)
+	case "file":
+		memoryService, err = memstore.Open(*memoryFile, memoryOpts...)
+		if err != nil {
+			log.Fatalf("Failed to open memory file: %v", err)
+		}
+		defer memoryService.Close()
+		fmt.Printf(">>> 기억 파일: %s\n", *memoryFile)
+	default:
+		log.Fatalf("Unknown memory backend %q (use inmemory or file)", *memoryBackend)
+	}
+	// 보관 정책: 시작할 때 한 번 적용하고, 이후에는 백그라운드에서 주기적으로 적용합니다.
+	if retention.MaxAge > 0 || retention.MaxEntriesPerUser > 0 {
+		n, err := memoryService.EnforceRetention(ctx)
+		if err != nil {
+			log.Fatalf("Failed to apply memory retention: %v", err)
+		}
+		fmt.Printf(">>> 보관 정책: 최대 %v, 사용자당 %d개 (오래된 기억 %d개 정리)\n", retention.MaxAge, retention.MaxEntriesPerUser, n)
+		memoryService.StartRetention(*retentionInterval)
+	}
+
+	// 사용자 프로필: 대화에서 뽑아낸 사실(이름, 취향, 날짜)을 key 하나에 값 하나로 보관합니다.
+	var profiles *profilestore.Store
+	switch *profileBackend {
+	case "inmemory":
+		profiles = profilestore.NewInMemory()
+	case "file":
+		profiles, err = profilestore.Open(*profileFile)
+		if err != nil {
+			log.Fatalf("Failed to open profile file: %v", err)
+		}
+		defer profiles.Close()
+		fmt.Printf(">>> 프로필 파일: %s\n", *profileFile)
+	default:
+		log.Fatalf("Unknown profile backend %q (use inmemory or file)", *profileBackend)
+	}
+	var sum *summarizer
+	if *summarizeTokens > 0 {
+		sum, err = newSummarizer(model)
+		if err != nil {
+			log.Fatalf("Failed to create summarizer: %v", err)
+		}
+	}
+	var extractor *factExtractor
+	if *extract {
+		extractor, err = newFactExtractor(model)
+		if err != nil {
+			log.Fatalf("Failed to create fact extractor: %v", err)
+		}
+	}
 
 	// 3. 에이전트 설정 (프롬프트로 언어 문제 해결)
-	rootAgent, err := llmagent.New(llmagent.Config{
-		Name:  "root_agent",
-		Model: model,
-		// ★핵심★: 에이전트에게 한국어 검색을 강제하는 Instruction
-		Instruction: `You are a helpful assistant with a good memory.
-		
-		RULES FOR MEMORY:
-		1. Always use 'search_past_conversations' tool when the user asks about personal info (name, past topics).
-		2. CRITICAL: If the conversation is in Korean, generate the search query IN KOREAN.
-		   - Bad Query: "user name"
-		   - Good Query: "내 이름", "사용자 이름", "이름은"
-		3. If the tool returns the information, answer naturally in Korean.`,
-		Tools: []tool.Tool{memorySearchTool},
+	rootAgent, err := newRootAgent(model, memoryInstruction, memoryService, profiles, memstore.SearchOptions{
+		TopK:     *topK,
+		MinScore: *minScore,
+		Mode:     mode,
+		Alpha:    *alpha,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
@@ -120,53 +290,52 @@ func main() {
 		log.Fatalf("Failed to create runner: %v", err)
 	}
 
-	sessionID := "session1"
-	userID := "user1"
+	// 새 대화는 실행할 때마다 새 세션 ID를 씁니다. --session_id로 이전 세션을 지정하면 그 대화를 이어갑니다.
+	// 대화 중에는 /user, /new, /resume으로 사용자와 세션을 바꿀 수 있습니다.
+	c := &chat{appName: "MemoryApp", userID: *userFlag, sessions: sessionService, memory: memoryService, profiles: profiles}
+	if err := c.open(ctx, *resumeID); err != nil {
+		log.Fatalf("Failed to open session: %v", err)
+	}
 
-	must(sessionService.Create(ctx, &session.CreateRequest{
-		UserID:    userID,
-		AppName:   "MemoryApp",
-		SessionID: sessionID,
-	}))
+	rl, err := repl.New(repl.Config{
+		Prompt:      func() string { return fmt.Sprintf("\n[%s] User: ", c.userID) },
+		HistoryFile: *historyFile,
+	})
+	if err != nil {
+		log.Fatalf("Failed to start REPL: %v", err)
+	}
+	defer rl.Close()
+	for _, cmd := range c.commands() {
+		rl.AddCommand(cmd)
+	}
 
-	fmt.Println(">>> 봇이 준비되었습니다. (종료: exit)")
-	scanner := bufio.NewScanner(os.Stdin)
+	runConfig := agent.RunConfig{}
+	if *stream {
+		runConfig.StreamingMode = agent.StreamingModeSSE
+	}
 
-	for {
-		fmt.Print("\nUser: ")
-		if !scanner.Scan() {
-			break
-		}
-		input := scanner.Text()
-		if strings.ToLower(input) == "exit" {
-			break
-		}
+	fmt.Println(">>> 봇이 준비되었습니다. (종료: exit 또는 Ctrl-D, 명령 목록: /help, 실행 취소: Ctrl-C)")
 
+	// 턴 하나: 에이전트 실행 → 기억 저장 → 사실 추출 → 대화 요약
+	// ctx는 Ctrl-C를 누르면 취소됩니다. 취소되기 전까지 세션에 저장된 이벤트는 다음 턴에 색인됩니다.
+	turn := func(ctx context.Context, input string) error {
 		userContent := genai.NewContentFromText(input, genai.RoleUser)
-		events := r.Run(ctx, userID, sessionID, userContent, agent.RunConfig{})
+		// 현재 사용자/세션 (명령으로 바뀔 수 있으므로 턴마다 읽습니다)
+		userID, sessionID := c.userID, c.sessionID
+		if err := rl.Render(r.Run(ctx, userID, sessionID, userContent, runConfig)); err != nil {
+			return err
+		}
 
-		for event, err := range events {
-			if err != nil {
-				log.Printf("Error: %v", err)
-				continue
-			}
-			if event.Content != nil {
-				for _, part := range event.Content.Parts {
-					fmt.Print(part.Text)
-				}
-			}
+		// 5. 기억 저장 (마지막으로 색인한 이벤트 이후만 가져오기)
+		// After는 그 시각의 이벤트도 포함하지만, 이미 색인한 이벤트는 AddSession이 건너뜁니다.
+		req := &session.GetRequest{AppName: "MemoryApp", UserID: userID, SessionID: sessionID}
+		lastIndexed, at, ok := memoryService.Cursor("MemoryApp", userID, sessionID)
+		if ok {
+			req.After = at
 		}
-		fmt.Println()
-
-		// 5. 기억 저장 (업데이트된 세션 가져오기)
-		latestSession, err := sessionService.Get(ctx, &session.GetRequest{
-			AppName:   "MemoryApp",
-			UserID:    userID,
-			SessionID: sessionID,
-		})
+		latestSession, err := sessionService.Get(ctx, req)
 		if err != nil {
-			log.Printf("세션 조회 실패: %v", err)
-			continue
+			return fmt.Errorf("세션 조회 실패: %w", err)
 		}
 
 		if err := memoryService.AddSession(ctx, latestSession.Session); err != nil {
@@ -174,9 +343,42 @@ func main() {
 		} else {
 			fmt.Println("--- [시스템] 기억 저장 완료 ---")
 		}
+
+		// 6. 사실 추출 (이번 턴에 새로 생긴 사용자 발화에서만)
+		if extractor != nil {
+			extractFacts(ctx, extractor, profiles, "MemoryApp", latestSession.Session, eventsAfter(latestSession.Session, lastIndexed))
+		}
+
+		// 7. 대화 요약 (세션이 길어지면 오래된 턴을 요약 이벤트 하나로 압축)
+		if sum != nil {
+			full, err := sessionService.Get(ctx, &session.GetRequest{AppName: "MemoryApp", UserID: userID, SessionID: sessionID})
+			if err == nil {
+				err = compactSession(ctx, sum, sessionService, memoryService, full.Session, *summarizeTokens, *keepRecent)
+			}
+			if err != nil {
+				log.Printf("대화 요약 실패: %v", err)
+			}
+		}
+		return nil
+	}
+
+	if err := rl.Run(ctx, turn); err != nil {
+		log.Printf("REPL stopped: %v", err)
 	}
 }
 
+// eventsAfter는 ID가 lastID인 이벤트 다음부터의 이벤트를 반환합니다. lastID가 없으면 모든 이벤트를 반환합니다.
+func eventsAfter(s session.Session, lastID string) []*session.Event {
+	var events []*session.Event
+	for event := range s.Events().All() {
+		events = append(events, event)
+		if event.ID == lastID {
+			events = events[:0]
+		}
+	}
+	return events
+}
+
 func must[T any](obj T, err error) T {
 	if err != nil {
 		panic(err)
@@ -184,6 +386,15 @@ func must[T any](obj T, err error) T {
 	return obj
 }
 
+// snippet은 text가 n 글자보다 길면 잘라서 "…"를 붙입니다.
+func snippet(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
+
 func textParts(content *genai.Content) []string {
 	var texts []string
 	if content == nil {
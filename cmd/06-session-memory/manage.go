@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"awesomeProject2/internal/memstore"
+	"awesomeProject2/internal/profilestore"
+)
+
+// --- 기억 관리 (목록 / 삭제 / 내보내기) ---
+
+// shortID는 목록에 보여줄 짧은 ID입니다. 이벤트 ID(UUID)는 앞 8글자만 보여줘도 대부분 구분됩니다.
+// 요약(summary-)이나 예전 형식(legacy-)처럼 접두어가 붙은 ID는 접두어를 남기고 그 뒤를 8글자로 줄입니다.
+// 접두어만 남기면 요약이 여럿일 때 모두 같은 ID로 보여 forget_memory로 지울 수 없습니다.
+func shortID(id string) string {
+	var prefix string
+	for _, p := range []string{summaryIDPrefix, "legacy-"} {
+		if strings.HasPrefix(id, p) {
+			prefix = p
+			break
+		}
+	}
+	if rest := id[len(prefix):]; len(rest) > 8 {
+		return prefix + rest[:8]
+	}
+	return id
+}
+
+// StoredMemory는 list_memories 도구가 돌려주는 기억 한 건입니다.
+type StoredMemory struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Author    string `json:"author"`
+	Time      string `json:"time"`
+	SessionID string `json:"session_id"`
+}
+
+type ListResult struct {
+	Memories []StoredMemory `json:"memories"`
+	Count    int            `json:"count"`
+}
+
+func toStoredMemory(e memstore.StoredEntry, maxRunes int) StoredMemory {
+	return StoredMemory{
+		ID:        shortID(e.ID),
+		Text:      snippet(strings.Join(textParts(e.Content), " "), maxRunes),
+		Author:    e.Author,
+		Time:      e.Timestamp.Format(time.DateTime),
+		SessionID: e.SessionID,
+	}
+}
+
+func newListMemoriesTool(store memstore.DeletableService) tool.Tool {
+	return must(functiontool.New(
+		functiontool.Config{
+			Name:        "list_memories",
+			Description: "Lists every stored memory of the user (oldest first) with its ID. Use it when the user asks what you remember about them.",
+		},
+		func(tctx tool.Context, _ struct{}) (ListResult, error) {
+			entries, err := store.List(tctx, tctx.AppName(), tctx.UserID())
+			if err != nil {
+				return ListResult{}, fmt.Errorf("failed to list memories: %w", err)
+			}
+			fmt.Printf("\n[Tool] 기억 목록 -> %d개\n", len(entries))
+			result := ListResult{Memories: make([]StoredMemory, 0, len(entries)), Count: len(entries)}
+			for _, e := range entries {
+				result.Memories = append(result.Memories, toStoredMemory(e, maxSnippetRunes))
+			}
+			return result, nil
+		},
+	))
+}
+
+type ForgetMemoryArgs struct {
+	ID  string `json:"id,omitempty" jsonschema:"The ID of the memory to delete (from list_memories or search_past_conversations)."`
+	All bool   `json:"all,omitempty" jsonschema:"Delete every memory of the user. Only when the user explicitly asks to forget everything."`
+}
+
+type ForgetMemoryResult struct {
+	Deleted int    `json:"deleted"`
+	Message string `json:"message"`
+}
+
+func newForgetMemoryTool(store *memstore.Service) tool.Tool {
+	return must(functiontool.New(
+		functiontool.Config{
+			Name:        "forget_memory",
+			Description: "Deletes one stored memory by ID, or all of the user's memories with all=true, when the user asks you to forget it.",
+		},
+		func(tctx tool.Context, args ForgetMemoryArgs) (ForgetMemoryResult, error) {
+			fmt.Printf("\n[Tool] 기억 삭제: id='%s' all=%v", args.ID, args.All)
+			n, err := forgetMemories(tctx, store, tctx.AppName(), tctx.UserID(), args.ID, args.All)
+			if errors.Is(err, memstore.ErrNotFound) || errors.Is(err, memstore.ErrAmbiguousID) {
+				fmt.Println(" -> 실패")
+				return ForgetMemoryResult{Message: err.Error() + ". Call list_memories to see memory IDs."}, nil
+			}
+			if err != nil {
+				return ForgetMemoryResult{}, err
+			}
+			fmt.Printf(" -> %d개 삭제\n", n)
+			return ForgetMemoryResult{Deleted: n, Message: fmt.Sprintf("%d memories were deleted.", n)}, nil
+		},
+	))
+}
+
+// forgetMemories는 ID(앞부분만 줘도 됨)로 기억 하나를, all이면 사용자의 모든 기억을 지웁니다.
+func forgetMemories(ctx context.Context, store *memstore.Service, appName, userID, id string, all bool) (int, error) {
+	if all {
+		return store.DeleteAll(ctx, appName, userID)
+	}
+	fullID, err := store.Resolve(appName, userID, id)
+	if err != nil {
+		return 0, err
+	}
+	return store.Delete(ctx, appName, userID, fullID)
+}
+
+type ExportResult struct {
+	Path     string `json:"path"`
+	Memories int    `json:"memories"`
+	Facts    int    `json:"facts"`
+}
+
+func newExportTool(store memstore.DeletableService, profiles *profilestore.Store) tool.Tool {
+	return must(functiontool.New(
+		functiontool.Config{
+			Name:        "export_memories",
+			Description: "Exports all of the user's memories and profile facts to a JSON file and returns its path.",
+		},
+		func(tctx tool.Context, _ struct{}) (ExportResult, error) {
+			res, err := exportUserData(tctx, store, profiles, tctx.AppName(), tctx.UserID(), "")
+			if err != nil {
+				return ExportResult{}, err
+			}
+			fmt.Printf("\n[Tool] 내보내기 -> %s\n", res.Path)
+			return res, nil
+		},
+	))
+}
+
+// userExport는 내보내기 파일의 형식입니다.
+type userExport struct {
+	AppName    string              `json:"app_name"`
+	UserID     string              `json:"user_id"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Memories   []exportedMemory    `json:"memories"`
+	Profile    []profilestore.Fact `json:"profile"`
+}
+
+type exportedMemory struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Author    string    `json:"author"`
+	Time      time.Time `json:"time"`
+	Text      string    `json:"text"`
+}
+
+// exportUserData는 사용자의 기억 전체(잘라내지 않은 원문)와 프로필을 JSON 파일로 씁니다.
+// path가 비어 있으면 data/export-<user>-<시각>.json에 씁니다.
+func exportUserData(ctx context.Context, store memstore.DeletableService, profiles *profilestore.Store, appName, userID, path string) (ExportResult, error) {
+	entries, err := store.List(ctx, appName, userID)
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("failed to list memories: %w", err)
+	}
+	out := userExport{
+		AppName:    appName,
+		UserID:     userID,
+		ExportedAt: time.Now(),
+		Memories:   make([]exportedMemory, 0, len(entries)),
+		Profile:    profiles.Profile(appName, userID),
+	}
+	for _, e := range entries {
+		out.Memories = append(out.Memories, exportedMemory{
+			ID:        e.ID,
+			SessionID: e.SessionID,
+			Author:    e.Author,
+			Time:      e.Timestamp,
+			Text:      strings.Join(textParts(e.Content), " "),
+		})
+	}
+
+	if path == "" {
+		path = filepath.Join("data", fmt.Sprintf("export-%s-%s.json", fileNamePart(userID), out.ExportedAt.Format("20060102-150405")))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return ExportResult{}, fmt.Errorf("failed to create export dir: %w", err)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("failed to encode export: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return ExportResult{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	return ExportResult{Path: path, Memories: len(out.Memories), Facts: len(out.Profile)}, nil
+}
+
+// fileNamePart는 사용자 ID를 파일 이름에 넣을 수 있게 글자, 숫자, '-', '_'만 남기고 나머지는 '_'로 바꿉니다.
+// 사용자 ID는 /user로 아무 값이나 줄 수 있으므로, "../x"처럼 경로 구분자나 ".."가 들어가면 data/ 밖에 쓰게 됩니다.
+func fileNamePart(userID string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, userID)
+	if name == "" {
+		return "user"
+	}
+	return name
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/memstore"
+	"awesomeProject2/internal/profilestore"
+)
+
+func TestShortID(t *testing.T) {
+	tests := []struct {
+		id, want string
+	}{
+		{"3f2a9c1e-7b4d-4e8a-9c1f-2d3e4f5a6b7c", "3f2a9c1e"},
+		{"summary-3f2a9c1e-7b4d-4e8a-9c1f-2d3e4f5a6b7c", "summary-3f2a9c1e"},
+		{"legacy-0badcafe", "legacy-0badcafe"},
+		{"short", "short"},
+	}
+	for _, tt := range tests {
+		if got := shortID(tt.id); got != tt.want {
+			t.Errorf("shortID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+// 요약이 둘 이상이어도 목록에 보여준 ID로 하나씩 지울 수 있어야 합니다.
+func TestListAndForgetTwoSummaries(t *testing.T) {
+	ctx := t.Context()
+	const app, user = "memory_bot", "user1"
+
+	sessions := session.InMemoryService()
+	res, err := sessions.Create(ctx, &session.CreateRequest{AppName: app, UserID: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range []string{"[이전 대화 요약]\n떡볶이를 좋아함", "[이전 대화 요약]\n서울에 삶"} {
+		ev := session.NewEvent("e-" + uuid.NewString())
+		ev.ID = summaryIDPrefix + uuid.NewString()
+		ev.Author = summaryAuthor
+		ev.Timestamp = time.Unix(1_700_000_000+int64(i), 0)
+		ev.LLMResponse.Content = genai.NewContentFromText(text, genai.RoleModel)
+		if err := sessions.AppendEvent(ctx, res.Session, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := sessions.Get(ctx, &session.GetRequest{AppName: app, UserID: user, SessionID: res.Session.ID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := memstore.NewInMemory()
+	if err := store.AddSession(ctx, got.Session); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := store.List(ctx, app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("listed %d memories, want 2", len(entries))
+	}
+	ids := []string{toStoredMemory(entries[0], 80).ID, toStoredMemory(entries[1], 80).ID}
+	if ids[0] == ids[1] {
+		t.Fatalf("both summaries are shown as %q", ids[0])
+	}
+
+	for i, id := range ids {
+		n, err := forgetMemories(ctx, store, app, user, id, false)
+		if err != nil || n != 1 {
+			t.Fatalf("forget %q: n=%d err=%v", id, n, err)
+		}
+		left, _ := store.List(ctx, app, user)
+		if len(left) != len(ids)-i-1 {
+			t.Errorf("after forgetting %q: %d memories left", id, len(left))
+		}
+	}
+}
+
+func TestFileNamePart(t *testing.T) {
+	tests := []struct {
+		userID, want string
+	}{
+		{"user1", "user1"},
+		{"민수_kim-2", "민수_kim-2"},
+		{"../x", "___x"},
+		{`..\..\x`, "______x"},
+		{"a/b c", "a_b_c"},
+		{"", "user"},
+	}
+	for _, tt := range tests {
+		if got := fileNamePart(tt.userID); got != tt.want {
+			t.Errorf("fileNamePart(%q) = %q, want %q", tt.userID, got, tt.want)
+		}
+	}
+}
+
+// 사용자 ID에 경로가 들어 있어도 기본 내보내기 파일은 data/ 안에 만들어져야 합니다.
+func TestExportUserDataStaysInDataDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	res, err := exportUserData(t.Context(), memstore.NewInMemory(), profilestore.NewInMemory(), "memory_bot", "../../escaped", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(res.Path) != "data" || !strings.HasPrefix(filepath.Base(res.Path), "export-______escaped-") {
+		t.Errorf("export path = %q, want a file directly in data/", res.Path)
+	}
+	if _, err := os.Stat(filepath.Join(dir, res.Path)); err != nil {
+		t.Errorf("export file: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "data" {
+		t.Errorf("files outside data/: %v", entries)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/profilestore"
+)
+
+// --- 사실 추출 (Fact Extraction) ---
+
+// extractedFact는 추출 에이전트가 돌려주는 사실 하나입니다.
+type extractedFact struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Category string `json:"category"`
+}
+
+// factExtractor는 사용자의 발화 하나에서 오래 기억할 만한 사실을 뽑아냅니다.
+// 답변 대신 OutputSchema에 맞는 JSON만 내놓는 별도의 에이전트를 자기 전용 세션 서비스로 실행합니다.
+type factExtractor struct {
+	runner   *runner.Runner
+	sessions session.Service
+}
+
+const extractorApp = "FactExtractor"
+
+func newFactExtractor(llm model.LLM) (*factExtractor, error) {
+	categories := make([]string, len(profilestore.Categories))
+	for i, c := range profilestore.Categories {
+		categories[i] = string(c)
+	}
+
+	extractor, err := llmagent.New(llmagent.Config{
+		Name:        "fact_extractor",
+		Model:       llm,
+		Description: "Extracts durable facts about the user from a single message.",
+		Instruction: `You extract durable facts about the user from ONE message the user wrote.
+
+Durable facts are things worth remembering in later conversations: name, job, where they live, family,
+likes and dislikes, birthdays, anniversaries, upcoming plans with dates.
+Ignore questions, greetings, small talk and anything temporary ("I'm tired right now").
+
+You are also given the user's current profile.
+- Reuse an existing key when the message updates that fact (e.g. a new favorite food → same key "favorite_food").
+- Otherwise use a short snake_case English key ("name", "favorite_food", "birthday", "hometown").
+- Write the value in the language the user used, as a short phrase.
+- If the message contains no durable facts, return an empty list.`,
+		OutputSchema: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"facts": {
+					Type: genai.TypeArray,
+					Items: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"key":      {Type: genai.TypeString, Description: "snake_case English key of the fact."},
+							"value":    {Type: genai.TypeString, Description: "The fact itself, in the user's language."},
+							"category": {Type: genai.TypeString, Enum: categories},
+						},
+						Required: []string{"key", "value", "category"},
+					},
+				},
+			},
+			Required: []string{"facts"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extractor agent: %w", err)
+	}
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: extractorApp, Agent: extractor, SessionService: sessions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extractor runner: %w", err)
+	}
+	return &factExtractor{runner: r, sessions: sessions}, nil
+}
+
+// Extract는 message에서 사실을 뽑습니다. 발화마다 새 세션을 써서 이전 발화가 결과에 섞이지 않게 합니다.
+func (x *factExtractor) Extract(ctx context.Context, userID string, profile []profilestore.Fact, message string) ([]extractedFact, error) {
+	created, err := x.sessions.Create(ctx, &session.CreateRequest{AppName: extractorApp, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	sessionID := created.Session.ID()
+	defer x.sessions.Delete(ctx, &session.DeleteRequest{AppName: extractorApp, UserID: userID, SessionID: sessionID})
+
+	current := make(map[string]string, len(profile))
+	for _, f := range profile {
+		current[f.Key] = f.Value
+	}
+	currentJSON, _ := json.Marshal(current)
+	prompt := fmt.Sprintf("Current profile: %s\n\nUser message:\n%s", currentJSON, message)
+
+	var output string
+	for event, err := range x.runner.Run(ctx, userID, sessionID, genai.NewContentFromText(prompt, genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return nil, err
+		}
+		if event.Content != nil && !event.Partial {
+			output = strings.Join(textParts(event.Content), "")
+		}
+	}
+	if output == "" {
+		return nil, errors.New("extractor returned no output")
+	}
+
+	var result struct {
+		Facts []extractedFact `json:"facts"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse extractor output: %w", err)
+	}
+	return result.Facts, nil
+}
+
+// extractFacts는 새로 생긴 사용자 이벤트마다 사실을 뽑아 프로필에 반영합니다.
+// 출처(provenance)로 세션 ID와 이벤트 ID를 기록하고, 이벤트 시각으로 충돌(같은 key의 다른 값)을 해결합니다.
+func extractFacts(ctx context.Context, x *factExtractor, profiles *profilestore.Store, appName string, s session.Session, events []*session.Event) {
+	for _, event := range events {
+		if event.Author != "user" {
+			continue
+		}
+		message := strings.Join(textParts(event.Content), " ")
+		if strings.TrimSpace(message) == "" {
+			continue
+		}
+
+		facts, err := x.Extract(ctx, s.UserID(), profiles.Profile(appName, s.UserID()), message)
+		if err != nil {
+			fmt.Printf("--- [시스템] 사실 추출 실패: %v ---\n", err)
+			continue
+		}
+		for _, ef := range facts {
+			f, change, err := profiles.Put(appName, s.UserID(), profilestore.Input{
+				Key:      ef.Key,
+				Value:    ef.Value,
+				Category: profilestore.Category(ef.Category),
+				Source:   profilestore.Source{SessionID: s.ID(), EventID: event.ID},
+				At:       event.Timestamp,
+			})
+			switch {
+			case err != nil:
+				fmt.Printf("--- [시스템] 프로필 저장 실패: %v ---\n", err)
+			case change == profilestore.Added:
+				fmt.Printf("--- [시스템] 프로필 추가: %s = %s ---\n", f.Key, f.Value)
+			case change == profilestore.Updated:
+				fmt.Printf("--- [시스템] 프로필 변경: %s = %s (이전: %s) ---\n", f.Key, f.Value, f.History[len(f.History)-1].Value)
+			}
+		}
+	}
+}
+
+// --- 프로필 도구 ---
+
+// ProfileFact는 get_user_profile 도구가 돌려주는 사실 하나입니다.
+type ProfileFact struct {
+	ID        string   `json:"id"`
+	Key       string   `json:"key"`
+	Value     string   `json:"value"`
+	Category  string   `json:"category"`
+	UpdatedAt string   `json:"updated_at"`
+	Previous  []string `json:"previous_values,omitempty"`
+}
+
+type ProfileResult struct {
+	Facts   []ProfileFact `json:"facts"`
+	Message string        `json:"message,omitempty"`
+}
+
+// newProfileTool은 사용자 프로필 전체를 돌려주는 도구를 만듭니다.
+func newProfileTool(profiles *profilestore.Store) tool.Tool {
+	return must(functiontool.New(
+		functiontool.Config{
+			Name:        "get_user_profile",
+			Description: "Returns durable facts known about the user (name, preferences, dates) with their IDs. Check this first for personal questions.",
+		},
+		func(tctx tool.Context, _ struct{}) (ProfileResult, error) {
+			facts := profiles.Profile(tctx.AppName(), tctx.UserID())
+			fmt.Printf("\n[Tool] 프로필 조회 -> %d개\n", len(facts))
+			if len(facts) == 0 {
+				return ProfileResult{Message: "No facts about the user are stored yet."}, nil
+			}
+
+			result := ProfileResult{Facts: make([]ProfileFact, 0, len(facts))}
+			for _, f := range facts {
+				pf := ProfileFact{
+					ID:        f.ID,
+					Key:       f.Key,
+					Value:     f.Value,
+					Category:  string(f.Category),
+					UpdatedAt: f.UpdatedAt.Format(time.DateTime),
+				}
+				for _, rev := range f.History {
+					pf.Previous = append(pf.Previous, rev.Value)
+				}
+				result.Facts = append(result.Facts, pf)
+			}
+			return result, nil
+		},
+	))
+}
+
+type ForgetArgs struct {
+	Fact string `json:"fact" jsonschema:"The ID or key of the fact to forget (from get_user_profile)."`
+}
+
+type ForgetResult struct {
+	Forgotten string `json:"forgotten,omitempty"`
+	Message   string `json:"message"`
+}
+
+// newForgetFactTool은 사용자가 잊어달라고 한 사실을 프로필에서 지우는 도구를 만듭니다.
+func newForgetFactTool(profiles *profilestore.Store) tool.Tool {
+	return must(functiontool.New(
+		functiontool.Config{
+			Name:        "forget_fact",
+			Description: "Deletes a fact from the user's profile when the user asks you to forget it. Takes the fact ID or key from get_user_profile.",
+		},
+		func(tctx tool.Context, args ForgetArgs) (ForgetResult, error) {
+			fmt.Printf("\n[Tool] 프로필 삭제: '%s'", args.Fact)
+			f, err := profiles.Forget(tctx.AppName(), tctx.UserID(), args.Fact)
+			if errors.Is(err, profilestore.ErrNotFound) {
+				fmt.Println(" -> 없음")
+				return ForgetResult{Message: "No such fact. Call get_user_profile to see fact IDs."}, nil
+			}
+			if err != nil {
+				return ForgetResult{}, fmt.Errorf("failed to forget fact: %w", err)
+			}
+			fmt.Printf(" -> %s = %s 삭제\n", f.Key, f.Value)
+			return ForgetResult{Forgotten: f.Key + " = " + f.Value, Message: "The fact was deleted."}, nil
+		},
+	))
+}
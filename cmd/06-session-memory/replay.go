@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/memstore"
+	"awesomeProject2/internal/profilestore"
+	"awesomeProject2/internal/replay"
+	"awesomeProject2/internal/sessionstore"
+	"awesomeProject2/internal/transcript"
+)
+
+// --- 대화 다시 실행하기 (replay 하위 명령) ---
+
+// runReplay는 export --format jsonl로 내보낸 대화(또는 세션 파일에 저장된 세션)의 사용자 발화를 새 지시문이나 모델로 다시 실행하고,
+// 이전 답변/도구 호출과 나란히 비교한 보고서를 씁니다. 도구 호출이 달라졌거나 오류가 난 턴이 있으면 종료 코드 1로 끝납니다.
+//
+//	go run ./cmd/06-session-memory replay --transcript data/transcript-s1.jsonl --instruction_file prompts/memory-v2.txt
+//	go run ./cmd/06-session-memory replay --session_file data/sessions.jsonl --session_id s1
+func runReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	transcriptFile := fs.String("transcript", "", "JSONL transcript written by the export subcommand")
+	sessionFile := fs.String("session_file", "", "Replay a session straight from a file written with --session_store=file (instead of --transcript)")
+	userID := fs.String("user", "", "With --session_file: only look at this user's sessions (default: all users)")
+	sessionID := fs.String("session_id", "", "With --session_file: session to replay (default: the most recently updated one)")
+	instructionFile := fs.String("instruction_file", "", "File with the new root_agent instruction (default: the current instruction)")
+	modelFlag := fs.String("model", modelName, "Model for the new agent version")
+	extract := fs.Bool("extract_facts", true, "Extract user facts after each turn, as the chat loop does")
+	out := fs.String("out", "", "Report file (default: data/replay-<session>-<time>.md)")
+	_ = fs.Parse(args)
+
+	if (*transcriptFile == "") == (*sessionFile == "") {
+		log.Fatalf("Exactly one of --transcript or --session_file is required")
+	}
+	old, err := loadTranscript(*transcriptFile, *sessionFile, *userID, *sessionID)
+	if err != nil {
+		log.Fatalf("Failed to read transcript: %v", err)
+	}
+
+	instruction, label := memoryInstruction, "현재 지시문"
+	if *instructionFile != "" {
+		data, err := os.ReadFile(*instructionFile)
+		if err != nil {
+			log.Fatalf("Failed to read instruction file: %v", err)
+		}
+		instruction, label = string(data), "지시문 "+*instructionFile
+	}
+	label += ", 모델 " + *modelFlag
+
+	ctx := context.Background()
+	llm, err := gemini.NewModel(ctx, *modelFlag, &genai.ClientConfig{})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	// 원래 기억/프로필/세션 파일을 건드리지 않도록 모두 메모리에서 새로 시작합니다.
+	// 대화가 진행되며 쌓이는 기억과 프로필은 원래 대화에서처럼 턴마다 채워집니다.
+	sessionService := sessionstore.NewInMemory()
+	memoryService := memstore.NewInMemory()
+	profiles := profilestore.NewInMemory()
+
+	rootAgent, err := newRootAgent(llm, instruction, memoryService, profiles, memstore.SearchOptions{
+		TopK:     5,
+		MinScore: defaultMinScores[memstore.ModeBM25],
+		Mode:     memstore.ModeBM25,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+	r, err := runner.New(runner.Config{AppName: old.AppName, Agent: rootAgent, SessionService: sessionService, MemoryService: memoryService})
+	if err != nil {
+		log.Fatalf("Failed to create runner: %v", err)
+	}
+	var extractor *factExtractor
+	if *extract {
+		extractor, err = newFactExtractor(llm)
+		if err != nil {
+			log.Fatalf("Failed to create fact extractor: %v", err)
+		}
+	}
+
+	fmt.Printf(">>> %s 세션을 %s(으)로 다시 실행합니다.\n", old.SessionID, label)
+	report, err := replay.Run(ctx, replay.Config{
+		Runner:   r,
+		Sessions: sessionService,
+		AppName:  old.AppName,
+		UserID:   old.UserID,
+		Label:    label,
+		AfterTurn: func(ctx context.Context, s session.Session) error {
+			lastIndexed, _, _ := memoryService.Cursor(old.AppName, old.UserID, s.ID())
+			if err := memoryService.AddSession(ctx, s); err != nil {
+				return err
+			}
+			if extractor != nil {
+				extractFacts(ctx, extractor, profiles, old.AppName, s, eventsAfter(s, lastIndexed))
+			}
+			return nil
+		},
+		OnTurn: func(i int, res replay.TurnResult) {
+			fmt.Printf("[턴 %d] %s  %s\n", i+1, res.Status(), snippet(res.Input, 40))
+		},
+	}, old)
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+
+	path := *out
+	if path == "" {
+		path = filepath.Join("data", fmt.Sprintf("replay-%s-%s.md", old.SessionID, report.StartedAt.Format("20060102-150405")))
+	}
+	if err := writeReport(report, path); err != nil {
+		log.Fatalf("Failed to write replay report: %v", err)
+	}
+	fmt.Printf(">>> %s\n>>> 보고서: %s\n", report.Summary(), path)
+	if n := report.Regressions(); n > 0 {
+		fmt.Printf(">>> 도구 호출이 달라졌거나 오류가 난 턴이 %d개 있습니다.\n", n)
+		os.Exit(1)
+	}
+}
+
+func writeReport(report *replay.Report, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteMarkdown(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadTranscript는 다시 실행할 대화를 JSONL 대화록이나 세션 파일에서 읽습니다.
+func loadTranscript(transcriptFile, sessionFile, userID, sessionID string) (*transcript.Transcript, error) {
+	if transcriptFile != "" {
+		f, err := os.Open(transcriptFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return transcript.ReadJSONL(f)
+	}
+	return transcript.LoadFile(context.Background(), sessionFile, transcript.Options{AppName: "MemoryApp", UserID: userID, SessionID: sessionID})
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/memstore"
+)
+
+// 검색 방식별 기본 점수 기준 (--min_score를 주지 않았을 때)
+// BM25 점수는 상한이 없고, 벡터/하이브리드 점수는 0~1 사이라서 기준이 서로 다릅니다.
+var defaultMinScores = map[memstore.SearchMode]float64{
+	memstore.ModeBM25:   0.5,
+	memstore.ModeVector: 0.3,
+	memstore.ModeHybrid: 0.2,
+}
+
+// vectorOptions는 검색 방식에 맞는 memstore 옵션을 만듭니다. BM25만 쓸 때는 벡터를 만들지 않습니다.
+func vectorOptions(ctx context.Context, mode memstore.SearchMode, embedderName, indexName string) ([]memstore.Option, error) {
+	if _, ok := defaultMinScores[mode]; !ok {
+		return nil, fmt.Errorf("unknown search mode %q (use bm25, vector or hybrid)", mode)
+	}
+	if mode == memstore.ModeBM25 {
+		return nil, nil
+	}
+
+	var embedder memstore.Embedder
+	switch embedderName {
+	case "gemini":
+		e, err := memstore.NewGeminiEmbedder(ctx, "gemini-embedding-001", &genai.ClientConfig{})
+		if err != nil {
+			return nil, err
+		}
+		embedder = e
+	case "hashing":
+		embedder = memstore.NewHashingEmbedder(256)
+	default:
+		return nil, fmt.Errorf("unknown embedder %q (use gemini or hashing)", embedderName)
+	}
+
+	var newIndex func() memstore.VectorIndex
+	switch indexName {
+	case "bruteforce":
+		newIndex = func() memstore.VectorIndex { return memstore.NewBruteForceIndex() }
+	case "hnsw":
+		newIndex = func() memstore.VectorIndex { return memstore.NewHNSWIndex() }
+	default:
+		return nil, fmt.Errorf("unknown vector index %q (use bruteforce or hnsw)", indexName)
+	}
+	return []memstore.Option{memstore.WithVectorSearch(embedder, newIndex)}, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/memstore"
+	"awesomeProject2/internal/sessionstore"
+)
+
+// --- 대화 요약 (Conversation Summarization) ---
+
+// 요약 이벤트의 작성자. 다른 에이전트가 쓴 이벤트는 모델에게 "For context: [conversation_summarizer] said: ..."로 전달됩니다.
+const summaryAuthor = "conversation_summarizer"
+
+// 요약 이벤트 ID의 접두어. 뒤에는 요약한 마지막 이벤트의 ID가 붙습니다.
+const summaryIDPrefix = "summary-"
+
+// summarizer는 오래된 대화를 요약하는 에이전트를 자기 전용 세션 서비스로 실행합니다.
+type summarizer struct {
+	runner   *runner.Runner
+	sessions session.Service
+}
+
+const summarizerApp = "Summarizer"
+
+func newSummarizer(llm model.LLM) (*summarizer, error) {
+	a, err := llmagent.New(llmagent.Config{
+		Name:        summaryAuthor,
+		Model:       llm,
+		Description: "Summarizes the older part of a conversation.",
+		Instruction: `You summarize the older part of a conversation between a user and an assistant so the assistant can continue it.
+
+- Keep every fact the user shared about themselves (name, preferences, dates, plans) and every decision or promise made.
+- Keep open questions and tasks that are not finished yet.
+- Drop greetings, small talk and tool call details.
+- If the transcript starts with an earlier summary, merge it into the new summary.
+- Write in the language of the conversation, as short bullet points. Output only the summary.`,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summarizer agent: %w", err)
+	}
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: summarizerApp, Agent: a, SessionService: sessions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summarizer runner: %w", err)
+	}
+	return &summarizer{runner: r, sessions: sessions}, nil
+}
+
+// Summarize는 이벤트들을 대화록(transcript)으로 만들어 요약합니다.
+func (x *summarizer) Summarize(ctx context.Context, userID string, events []*session.Event) (string, error) {
+	created, err := x.sessions.Create(ctx, &session.CreateRequest{AppName: summarizerApp, UserID: userID})
+	if err != nil {
+		return "", err
+	}
+	sessionID := created.Session.ID()
+	defer x.sessions.Delete(ctx, &session.DeleteRequest{AppName: summarizerApp, UserID: userID, SessionID: sessionID})
+
+	var transcript strings.Builder
+	for _, e := range events {
+		if text := strings.Join(textParts(e.Content), " "); text != "" {
+			fmt.Fprintf(&transcript, "[%s] %s\n", e.Author, text)
+		}
+	}
+
+	var summary string
+	for event, err := range x.runner.Run(ctx, userID, sessionID, genai.NewContentFromText(transcript.String(), genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return "", err
+		}
+		if event.Content != nil && !event.Partial {
+			summary = strings.Join(textParts(event.Content), "")
+		}
+	}
+	if strings.TrimSpace(summary) == "" {
+		return "", errors.New("summarizer returned no output")
+	}
+	return summary, nil
+}
+
+// estimateTokens는 토큰 수를 대략 셉니다. 한글은 글자당 약 1토큰, 그 밖의 문자는 4글자당 약 1토큰으로 봅니다.
+// 정확한 값은 모델의 CountTokens API로 알 수 있지만, 요약할 시점을 정하는 데는 이 정도로 충분합니다.
+func estimateTokens(events []*session.Event) int {
+	var hangul, other int
+	for _, e := range events {
+		if e.Content == nil {
+			continue
+		}
+		for _, p := range e.Content.Parts {
+			text := p.Text
+			if p.FunctionCall != nil {
+				text = fmt.Sprint(p.FunctionCall.Args)
+			} else if p.FunctionResponse != nil {
+				text = fmt.Sprint(p.FunctionResponse.Response)
+			}
+			for _, r := range text {
+				if unicode.Is(unicode.Hangul, r) {
+					hangul++
+				} else {
+					other++
+				}
+			}
+		}
+	}
+	return hangul + (other+3)/4
+}
+
+// compactSession은 세션이 maxTokens를 넘으면 최근 keepRecent개 정도의 이벤트만 그대로 두고 나머지를 요약 이벤트 하나로 바꿉니다.
+// 남기는 부분은 사용자 발화에서 시작하도록 맞춰서, 도구 호출과 그 결과가 요약과 원문으로 갈라지지 않게 합니다.
+// 요약은 기억(memory)에도 색인합니다. 요약된 원래 이벤트들은 이미 색인되어 있으므로 기억에서 사라지지 않습니다.
+func compactSession(ctx context.Context, x *summarizer, store *sessionstore.Service, memoryService *memstore.Service, s session.Session, maxTokens, keepRecent int) error {
+	var events []*session.Event
+	for e := range s.Events().All() {
+		events = append(events, e)
+	}
+	tokens := estimateTokens(events)
+	if tokens <= maxTokens {
+		return nil
+	}
+
+	cut := max(len(events)-keepRecent, 1)
+	for cut < len(events) && events[cut].Author != "user" {
+		cut++
+	}
+	if cut >= len(events) {
+		return nil // 남길 사용자 발화가 없으면 다음 턴에 다시 봅니다.
+	}
+	if cut == 1 && events[0].Author == summaryAuthor {
+		return nil // 이미 요약만 남아 있으면 더 줄일 것이 없습니다.
+	}
+
+	summary, err := x.Summarize(ctx, s.UserID(), events[:cut])
+	if err != nil {
+		return err
+	}
+	// 요약 이벤트는 에이전트 실행 밖에서 만들므로 새 호출(invocation) ID를 씁니다.
+	// 이벤트 ID는 요약한 마지막 이벤트로 정해 두어, 같은 구간을 다시 요약해도 기억에 한 번만 색인되게 합니다.
+	ev := session.NewEvent("e-" + uuid.NewString())
+	ev.ID = summaryIDPrefix + events[cut-1].ID
+	ev.Author = summaryAuthor
+	ev.Timestamp = events[cut-1].Timestamp
+	ev.LLMResponse.Content = genai.NewContentFromText("[이전 대화 요약]\n"+summary, genai.RoleModel)
+	ev.LLMResponse.CustomMetadata = map[string]any{"summarized_events": cut}
+
+	if err := store.CompactEvents(ctx, s, ev, events[cut].ID); err != nil {
+		return err
+	}
+	fmt.Printf("--- [시스템] 이전 대화 %d개 이벤트를 요약했습니다 (약 %d → %d 토큰) ---\n", cut, tokens, estimateTokens(append([]*session.Event{ev}, events[cut:]...)))
+
+	// 요약 이벤트는 이미 색인한 이벤트보다 시각이 앞서므로, 압축된 세션 전체를 넘겨 색인합니다.
+	return memoryService.AddSession(ctx, s)
+}
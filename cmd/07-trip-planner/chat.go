@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/repl"
+)
+
+// chatAppName은 chat에서 한 대화를 저장하는 앱 이름입니다. 콘솔(console)과 같게 두어 export의 기본값으로 내보낼 수 있습니다.
+const chatAppName = "console_app"
+
+// runChat은 런처의 console 대신 internal/repl로 대화합니다. 입력 기록, 여러 줄 입력,
+// Ctrl-C로 실행 중인 계획만 취소하기를 지원합니다.
+//
+//	go run ./cmd/07-trip-planner chat [--history_file data/trip_history]
+func runChat(ctx context.Context, planner agent.Agent, sessionService session.Service, args []string) error {
+	fs := flag.NewFlagSet("chat", flag.ContinueOnError)
+	historyFile := fs.String("history_file", "data/trip_history", "File keeping the REPL input history (empty disables)")
+	userID := fs.String("user", "user1", "User ID the sessions belong to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := runner.New(runner.Config{AppName: chatAppName, Agent: planner, SessionService: sessionService})
+	if err != nil {
+		return fmt.Errorf("failed to create runner: %w", err)
+	}
+	newSession := func(ctx context.Context) (string, error) {
+		resp, err := sessionService.Create(ctx, &session.CreateRequest{AppName: chatAppName, UserID: *userID})
+		if err != nil {
+			return "", fmt.Errorf("failed to create session: %w", err)
+		}
+		fmt.Printf(">>> 새 세션: %s\n", resp.Session.ID())
+		return resp.Session.ID(), nil
+	}
+	sessionID, err := newSession(ctx)
+	if err != nil {
+		return err
+	}
+
+	rl, err := repl.New(repl.Config{
+		Prompt:      func() string { return "\n여행지: " },
+		HistoryFile: *historyFile,
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+	// 세션에는 앞선 계획의 조사 결과(restaurant_list, activity_list)가 남으므로, 다른 도시는 새 세션에서 계획합니다.
+	rl.AddCommand(repl.Command{Name: "/new", Help: "새 세션 시작", Run: func(ctx context.Context, _ []string) error {
+		id, err := newSession(ctx)
+		if err == nil {
+			sessionID = id
+		}
+		return err
+	}})
+
+	fmt.Println(`>>> 여행지를 입력하세요. 예: Plan a trip to Tokyo  (/help: 명령 목록)`)
+	return rl.Run(ctx, func(ctx context.Context, input string) error {
+		msg := genai.NewContentFromText(input, genai.RoleUser)
+		return rl.Render(r.Run(ctx, *userID, sessionID, msg, agent.RunConfig{}))
+	})
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"awesomeProject2/internal/transcript"
+)
+
+// runExport는 --session_file로 저장한 세션을 대화록으로 내보냅니다. 콘솔(console)에서 한 대화는 console_app 앱에,
+// 웹 UI에서 한 대화는 에이전트 이름(TripPlannerPipeline) 앱에 저장되므로 웹 UI의 대화는 --app으로 고릅니다.
+//
+//	go run ./cmd/07-trip-planner export --session_file data/trip-sessions.jsonl --format md,html
+func runExport(args []string) {
+	cmd := transcript.ExportCommand{AppName: "console_app"}
+	if err := cmd.Run(context.Background(), args); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 
@@ -16,9 +17,23 @@ import (
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/geminitool"
 	"google.golang.org/genai"
+
+	"awesomeProject2/internal/sessionstore"
 )
 
 func main() {
+	// 대화록 내보내기: go run ./cmd/07-trip-planner export --session_file data/trip-sessions.jsonl [options]
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		runExport(os.Args[2:])
+		return
+	}
+
+	// 런처가 쓰지 않는 옵션만 먼저 읽고, 나머지 인자는 런처에 그대로 넘깁니다.
+	// 첫 인자가 chat이면 런처 대신 internal/repl로 대화합니다: go run ./cmd/07-trip-planner [--session_file ...] chat
+	fs := flag.NewFlagSet("trip-planner", flag.ExitOnError)
+	sessionFile := fs.String("session_file", "", "Persist sessions to this file so conversations survive restarts (default: in-memory)")
+	_ = fs.Parse(os.Args[1:])
+
 	ctx := context.Background()
 	// 1. Initialize Model (Use 2.0-flash if 2.5 is not available)
 	model, err := gemini.NewModel(ctx, "gemini-3-pro-preview", &genai.ClientConfig{})
@@ -85,7 +100,16 @@ func main() {
 	})
 
 	// 6. Run with Explicit Runner
-	sessionService := session.InMemoryService()
+	// --session_file을 주면 세션을 파일에 저장하므로, 서버를 다시 켜도 웹 UI에서 같은 세션 ID로 대화를 이어갈 수 있습니다.
+	var sessionService session.Service = session.InMemoryService()
+	if *sessionFile != "" {
+		store, err := sessionstore.Open(*sessionFile)
+		if err != nil {
+			log.Fatalf("Failed to open session file: %v", err)
+		}
+		defer store.Close()
+		sessionService = store
+	}
 	//r, err := runner.New(runner.Config{
 	//	AppName:        "TripPlannerApp",
 	//	Agent:          tripPlanner,
@@ -98,6 +122,13 @@ func main() {
 	// Create Session
 	//sess, _ := sessionService.Create(ctx, &session.CreateRequest{UserID: "user1", AppName: "TripPlannerApp"})
 
+	if fs.Arg(0) == "chat" {
+		if err := runChat(ctx, tripPlanner, sessionService, fs.Args()[1:]); err != nil {
+			log.Fatalf("Chat failed: %v", err)
+		}
+		return
+	}
+
 	config := &launcher.Config{
 		AgentLoader:    agent.NewSingleLoader(tripPlanner),
 		SessionService: sessionService,
@@ -105,7 +136,7 @@ func main() {
 
 	l := full.NewLauncher()
 
-	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+	if err = l.Execute(ctx, config, fs.Args()); err != nil {
 		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
 	}
 
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/remoteagent"
+
+	"awesomeProject2/internal/a2aauth"
+	"awesomeProject2/internal/a2aregistry"
+)
+
+// 원격 에이전트 주소를 지정하는 환경 변수 (쉼표로 구분)
+const agentURLsEnv = "A2A_AGENT_URLS"
+
+// 아무 설정도 없을 때 사용하는 기본 서버 주소 (prime 서버가 8001 포트)
+const defaultAgentURL = "http://localhost:8001"
+
+// 카드 하나를 가져오는 데 허용하는 최대 시간
+const cardFetchTimeout = 5 * time.Second
+
+// registryFile은 --agents_file로 전달되는 레지스트리 파일의 형식입니다.
+//
+//	{"agents": ["http://localhost:8001", "http://localhost:8002"]}
+type registryFile struct {
+	Agents []string `json:"agents"`
+}
+
+// remoteAgentInfo는 카드에서 읽어온 원격 에이전트 정보입니다.
+type remoteAgentInfo struct {
+	Source string
+	Card   *a2a.AgentCard
+	Agent  agent.Agent
+}
+
+// resolveAgentURLs는 플래그 > 레지스트리 파일 > 환경 변수 > 기본값 순서로 카드 주소 목록을 결정합니다.
+func resolveAgentURLs(flagValue, filePath string) ([]string, error) {
+	if urls := splitURLs(flagValue); len(urls) > 0 {
+		return urls, nil
+	}
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent registry file %q: %w", filePath, err)
+		}
+		var reg registryFile
+		if err := json.Unmarshal(data, &reg); err != nil {
+			return nil, fmt.Errorf("malformed agent registry file %q: %w", filePath, err)
+		}
+		if len(reg.Agents) == 0 {
+			return nil, fmt.Errorf("agent registry file %q lists no agents", filePath)
+		}
+		return reg.Agents, nil
+	}
+	if urls := splitURLs(os.Getenv(agentURLsEnv)); len(urls) > 0 {
+		return urls, nil
+	}
+	return []string{defaultAgentURL}, nil
+}
+
+func splitURLs(value string) []string {
+	var urls []string
+	for _, u := range strings.Split(value, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// discovery는 원격 에이전트를 찾고 연결할 때 쓰는 자격 증명과 HTTP 클라이언트를 묶어둡니다.
+// 카드 조회와 A2A 호출 모두 같은 클라이언트를 사용하므로, 인증 헤더와 TLS 설정이 함께 적용됩니다.
+type discovery struct {
+	creds      a2aauth.ClientCredentials
+	resilience resilienceConfig
+	httpClient *http.Client
+}
+
+func newDiscovery(creds a2aauth.ClientCredentials, resilience resilienceConfig) (*discovery, error) {
+	client, err := a2aauth.NewHTTPClient(creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure A2A credentials: %w", err)
+	}
+	return &discovery{creds: creds, resilience: resilience, httpClient: client}, nil
+}
+
+// clientFor는 원격 에이전트마다 자기만의 서킷 브레이커와 재시도 설정을 가진 HTTP 클라이언트를 만듭니다.
+// 한 서버가 죽어도 다른 원격 에이전트 호출에는 영향을 주지 않습니다.
+func (d *discovery) clientFor(name string) *http.Client {
+	return &http.Client{Transport: &retryTransport{
+		next:    d.httpClient.Transport,
+		cfg:     d.resilience,
+		breaker: newCircuitBreaker(name, d.resilience.BreakerFailures, d.resilience.BreakerCooldown),
+	}}
+}
+
+// discoverRemoteAgents는 시작 시점에 모든 카드를 가져와 원격 에이전트를 만듭니다.
+// 하나라도 실패하면 어떤 주소가 왜 실패했는지 알려주고 바로 종료합니다(fail fast).
+func (d *discovery) discoverRemoteAgents(ctx context.Context, urls []string) ([]remoteAgentInfo, error) {
+	var (
+		found []remoteAgentInfo
+		errs  []error
+	)
+	for _, u := range urls {
+		card, err := d.fetchAgentCard(ctx, u)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		found = append(found, remoteAgentInfo{Source: u, Card: card})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return d.newRemoteAgents(found)
+}
+
+// discoverFromRegistry는 레지스트리에서 조건에 맞는 살아있는 에이전트를 찾아 원격 에이전트를 만듭니다.
+// 레지스트리가 카드를 함께 돌려주므로 각 서버에 다시 카드를 요청하지 않습니다.
+func (d *discovery) discoverFromRegistry(ctx context.Context, registryURL string, q a2aregistry.Query) ([]remoteAgentInfo, error) {
+	entries, err := a2aregistry.NewClient(registryURL).Find(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("registry %s is unreachable: %w", registryURL, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("registry %s has no live agents matching skill=%q tag=%q", registryURL, q.Skill, q.Tag)
+	}
+
+	var (
+		found []remoteAgentInfo
+		errs  []error
+	)
+	for _, e := range entries {
+		if e.Card == nil {
+			errs = append(errs, fmt.Errorf("registry entry %q has no agent card", e.Name))
+			continue
+		}
+		if err := validateCard(e.Card); err != nil {
+			errs = append(errs, fmt.Errorf("agent card of %q from registry is malformed: %w", e.Name, err))
+			continue
+		}
+		found = append(found, remoteAgentInfo{Source: e.CardURL, Card: e.Card})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return d.newRemoteAgents(found)
+}
+
+// newRemoteAgents는 카드마다 remoteagent를 만듭니다.
+// 이름과 설명은 카드에서 그대로 가져옵니다. 손으로 중복 작성할 필요가 없습니다.
+func (d *discovery) newRemoteAgents(found []remoteAgentInfo) ([]remoteAgentInfo, error) {
+	var errs []error
+	seen := make(map[string]string)
+	for i, info := range found {
+		if prev, ok := seen[info.Card.Name]; ok {
+			errs = append(errs, fmt.Errorf("agent card at %s: name %q is already used by %s", info.Source, info.Card.Name, prev))
+			continue
+		}
+		seen[info.Card.Name] = info.Source
+
+		// 카드가 요구하는 인증 방식을 만족하는 자격 증명이 없으면 호출할 때까지 기다리지 않고 바로 알려줍니다.
+		if !d.creds.Satisfies(info.Card) {
+			errs = append(errs, fmt.Errorf("agent %q at %s requires one of %s, but no matching credentials are configured",
+				info.Card.Name, info.Source, requiredSchemes(info.Card)))
+			continue
+		}
+
+		remote, err := remoteagent.NewA2A(remoteagent.A2AConfig{
+			Name:            info.Card.Name,
+			Description:     describeCard(info.Card),
+			AgentCard:       info.Card,
+			AgentCardSource: info.Source,
+			ClientFactory:   a2aclient.NewFactory(a2aclient.WithJSONRPCTransport(d.clientFor(info.Card.Name))),
+		})
+		if err == nil {
+			remote, err = newResilientAgent(remote, d.resilience.CallTimeout)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("agent card at %s: %w", info.Source, err))
+			continue
+		}
+		found[i].Agent = remote
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return found, nil
+}
+
+func (d *discovery) fetchAgentCard(ctx context.Context, baseURL string) (*a2a.AgentCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, cardFetchTimeout)
+	defer cancel()
+
+	card, err := agentcard.NewResolver(d.httpClient).Resolve(ctx, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("agent card at %s is unreachable or invalid: %w", baseURL, err)
+	}
+	if err := validateCard(card); err != nil {
+		return nil, fmt.Errorf("agent card at %s is malformed: %w", baseURL, err)
+	}
+	return card, nil
+}
+
+func validateCard(card *a2a.AgentCard) error {
+	var missing []string
+	if strings.TrimSpace(card.Name) == "" {
+		missing = append(missing, "name")
+	}
+	if strings.TrimSpace(card.URL) == "" {
+		missing = append(missing, "url")
+	}
+	if strings.TrimSpace(card.Description) == "" && len(card.Skills) == 0 {
+		missing = append(missing, "description or skills")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// describeCard는 카드의 설명과 스킬 목록을 합쳐 에이전트 Description을 만듭니다.
+// 메인 에이전트는 이 설명을 보고 작업을 위임할지 결정합니다.
+func describeCard(card *a2a.AgentCard) string {
+	desc := strings.TrimSpace(card.Description)
+	var skills []string
+	for _, s := range card.Skills {
+		if s.Description != "" {
+			skills = append(skills, s.Description)
+		} else if s.Name != "" {
+			skills = append(skills, s.Name)
+		}
+	}
+	if len(skills) == 0 {
+		return desc
+	}
+	if desc == "" {
+		return "Skills: " + strings.Join(skills, "; ")
+	}
+	return desc + " Skills: " + strings.Join(skills, "; ")
+}
+
+func requiredSchemes(card *a2a.AgentCard) string {
+	var names []string
+	for _, req := range card.Security {
+		var and []string
+		for name := range req {
+			and = append(and, string(name))
+		}
+		names = append(names, strings.Join(and, "+"))
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
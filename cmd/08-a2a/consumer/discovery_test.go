@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+
+	"awesomeProject2/internal/a2aauth"
+	"awesomeProject2/internal/a2aregistry"
+)
+
+func TestResolveAgentURLs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	listed := writeFile("agents.json", `{"agents": ["http://file-a", "http://file-b"]}`)
+	empty := writeFile("empty.json", `{"agents": []}`)
+	malformed := writeFile("malformed.json", `{"agents": `)
+
+	tests := []struct {
+		name    string
+		flag    string
+		file    string
+		env     string
+		want    []string
+		wantErr string
+	}{
+		{name: "플래그가 가장 먼저", flag: " http://flag-a , ,http://flag-b", file: listed, env: "http://env", want: []string{"http://flag-a", "http://flag-b"}},
+		{name: "플래그가 없으면 파일", file: listed, env: "http://env", want: []string{"http://file-a", "http://file-b"}},
+		{name: "빈 플래그는 없는 것과 같음", flag: " , ", file: listed, want: []string{"http://file-a", "http://file-b"}},
+		{name: "파일이 없으면 환경 변수", env: "http://env-a,http://env-b", want: []string{"http://env-a", "http://env-b"}},
+		{name: "아무것도 없으면 기본값", want: []string{defaultAgentURL}},
+		{name: "파일을 읽을 수 없음", file: filepath.Join(dir, "missing.json"), env: "http://env", wantErr: "failed to read"},
+		{name: "파일 형식이 틀림", file: malformed, wantErr: "malformed"},
+		{name: "파일에 에이전트가 없음", file: empty, env: "http://env", wantErr: "lists no agents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(agentURLsEnv, tt.env)
+			got, err := resolveAgentURLs(tt.flag, tt.file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("urls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCard(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    a2a.AgentCard
+		wantErr string
+	}{
+		{name: "설명이 있음", card: a2a.AgentCard{Name: "prime", URL: "http://prime", Description: "Checks primes"}},
+		{name: "스킬만 있음", card: a2a.AgentCard{Name: "prime", URL: "http://prime", Skills: []a2a.AgentSkill{{ID: "check_prime"}}}},
+		{name: "이름 없음", card: a2a.AgentCard{URL: "http://prime", Description: "d"}, wantErr: "missing name"},
+		{name: "공백뿐인 주소", card: a2a.AgentCard{Name: "prime", URL: " ", Description: "d"}, wantErr: "missing url"},
+		{name: "모두 없음", card: a2a.AgentCard{}, wantErr: "missing name, url, description or skills"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCard(&tt.card)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("err = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// serveCard는 card를 Agent Card 경로로 돌려주는 서버를 띄웁니다. card의 URL이 "self"면 서버 주소로 바꿉니다.
+func serveCard(t *testing.T, card *a2a.AgentCard) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if card.URL == "self" {
+		card.URL = srv.URL
+	}
+	mux.HandleFunc("GET "+a2asrv.WellKnownAgentCardPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(card)
+	})
+	return srv.URL
+}
+
+func testDiscovery(t *testing.T, creds a2aauth.ClientCredentials) *discovery {
+	t.Helper()
+	d, err := newDiscovery(creds, resilienceConfig{CallTimeout: time.Second, BreakerFailures: 1, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func bearerCard(name string) *a2a.AgentCard {
+	card := &a2a.AgentCard{Name: name, URL: "self", Description: "Needs a token"}
+	a2aauth.Advertise(card, []a2aauth.Authenticator{a2aauth.NewBearer(nil)})
+	return card
+}
+
+func TestDiscoverRemoteAgents(t *testing.T) {
+	prime := serveCard(t, &a2a.AgentCard{Name: "prime_agent", URL: "self", Skills: []a2a.AgentSkill{{ID: "check_prime", Description: "Checks primes"}}})
+	echo := serveCard(t, &a2a.AgentCard{Name: "echo_agent", URL: "self", Description: "Echoes"})
+
+	t.Run("카드에서 이름과 설명을 채움", func(t *testing.T) {
+		remotes, err := testDiscovery(t, a2aauth.ClientCredentials{}).discoverRemoteAgents(t.Context(), []string{prime, echo})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(remotes) != 2 {
+			t.Fatalf("remotes = %+v", remotes)
+		}
+		for i, want := range []struct{ name, source, desc string }{
+			{"prime_agent", prime, "Skills: Checks primes"},
+			{"echo_agent", echo, "Echoes"},
+		} {
+			r := remotes[i]
+			if r.Card.Name != want.name || r.Source != want.source || r.Agent == nil {
+				t.Errorf("remote %d = %+v, want %s at %s", i, r, want.name, want.source)
+				continue
+			}
+			if r.Agent.Name() != want.name || r.Agent.Description() != want.desc {
+				t.Errorf("agent %d = %q (%q), want %q (%q)", i, r.Agent.Name(), r.Agent.Description(), want.name, want.desc)
+			}
+		}
+	})
+
+	t.Run("실패한 주소를 모두 모아서 알려줌", func(t *testing.T) {
+		missing := httptest.NewServer(http.NotFoundHandler())
+		defer missing.Close()
+		noURL := serveCard(t, &a2a.AgentCard{Name: "broken", Description: "No URL"})
+
+		_, err := testDiscovery(t, a2aauth.ClientCredentials{}).discoverRemoteAgents(t.Context(), []string{prime, missing.URL, noURL})
+		if err == nil {
+			t.Fatal("discovery succeeded with broken cards")
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("error has %d lines, want one per failing URL:\n%v", len(lines), err)
+		}
+		if !strings.Contains(lines[0], missing.URL) || !strings.Contains(lines[0], "unreachable or invalid") {
+			t.Errorf("first error = %q, want the unreachable card", lines[0])
+		}
+		if !strings.Contains(lines[1], noURL) || !strings.Contains(lines[1], "malformed: missing url") {
+			t.Errorf("second error = %q, want the malformed card", lines[1])
+		}
+	})
+
+	t.Run("이름 중복과 맞지 않는 자격 증명", func(t *testing.T) {
+		dup := serveCard(t, &a2a.AgentCard{Name: "prime_agent", URL: "self", Description: "Another prime"})
+		secured := serveCard(t, bearerCard("secure_agent"))
+
+		_, err := testDiscovery(t, a2aauth.ClientCredentials{}).discoverRemoteAgents(t.Context(), []string{prime, dup, secured})
+		if err == nil {
+			t.Fatal("discovery succeeded")
+		}
+		msg := err.Error()
+		if !strings.Contains(msg, `name "prime_agent" is already used by `+prime) {
+			t.Errorf("err = %v, want the duplicate name", err)
+		}
+		if !strings.Contains(msg, `agent "secure_agent" at `+secured+" requires one of [bearer]") {
+			t.Errorf("err = %v, want the missing credentials", err)
+		}
+
+		// 토큰이 있으면 같은 카드를 받아들입니다.
+		if _, err := testDiscovery(t, a2aauth.ClientCredentials{BearerToken: "token"}).discoverRemoteAgents(t.Context(), []string{secured}); err != nil {
+			t.Errorf("with a bearer token: %v", err)
+		}
+	})
+}
+
+func TestDiscoverFromRegistry(t *testing.T) {
+	reg := a2aregistry.NewInMemory()
+	srv := httptest.NewServer(a2aregistry.NewHandler(reg, nil))
+	defer srv.Close()
+
+	register := func(url string, card *a2a.AgentCard) {
+		t.Helper()
+		if _, err := reg.Register("", url, card, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register("http://prime", &a2a.AgentCard{Name: "prime_agent", URL: "http://prime", Skills: []a2a.AgentSkill{{ID: "check_prime", Tags: []string{"math"}}}})
+	register("http://echo", &a2a.AgentCard{Name: "echo_agent", URL: "http://echo", Description: "Echoes"})
+
+	d := testDiscovery(t, a2aauth.ClientCredentials{})
+	ctx := context.Background()
+
+	remotes, err := d.discoverFromRegistry(ctx, srv.URL, a2aregistry.Query{Tag: "math"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remotes) != 1 || remotes[0].Card.Name != "prime_agent" || remotes[0].Source != "http://prime" || remotes[0].Agent == nil {
+		t.Errorf("remotes = %+v, want only prime_agent", remotes)
+	}
+
+	if _, err := d.discoverFromRegistry(ctx, srv.URL, a2aregistry.Query{Skill: "translate"}); err == nil || !strings.Contains(err.Error(), "no live agents") {
+		t.Errorf("no matching agents: err = %v", err)
+	}
+
+	// 등록된 카드가 잘못되었으면 다른 에이전트도 쓰지 않고 실패합니다.
+	register("http://broken", &a2a.AgentCard{Name: "broken_agent", Description: "No URL"})
+	if _, err := d.discoverFromRegistry(ctx, srv.URL, a2aregistry.Query{}); err == nil || !strings.Contains(err.Error(), `agent card of "broken_agent" from registry is malformed: missing url`) {
+		t.Errorf("malformed registry card: err = %v", err)
+	}
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	if _, err := d.discoverFromRegistry(ctx, down.URL, a2aregistry.Query{}); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("registry down: err = %v", err)
+	}
+}
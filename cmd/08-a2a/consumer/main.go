@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
-	"google.golang.org/adk/agent/remoteagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
+
+	"awesomeProject2/internal/a2aauth"
+	"awesomeProject2/internal/a2aregistry"
 )
 
 // 에이전트 워크플로우
@@ -36,28 +42,80 @@ func main() {
 
 	sessionService := session.InMemoryService()
 
-	// 2. 원격 에이전트(A2A) 정의 수정
-	// 이전에는 "RemotePrimeAgent"였으나, 이제는 수학 전반을 다루므로 이름을 변경하고
-	// Description에 새로운 능력(팩토리얼, GCD)을 명시해야 합니다.
-	remoteMathAgent, err := remoteagent.NewA2A(remoteagent.A2AConfig{
-		Name: "RemoteMathHelper", // 이름 변경
-		// [중요] 이 설명(Description)을 보고 메인 에이전트가 작업을 위임할지 결정합니다.
-		Description:     "Can check prime numbers, calculate factorials, and find GCD of two numbers.",
-		AgentCardSource: "http://localhost:8001", // 서버 주소 (앞서 만든 서버가 8001 포트)
-	})
+	// 2. 원격 에이전트(A2A) 탐색
+	// 카드 주소를 코드에 박아두는 대신 플래그/레지스트리 파일/환경 변수로 받습니다.
+	// 시작할 때 각 주소의 Agent Card를 가져와서 Name과 Description을 카드에서 채웁니다.
+	// 예: go run ./cmd/08-a2a/consumer --agents http://localhost:8001 console
+	//     go run ./cmd/08-a2a/consumer --registry http://localhost:8000 --skill check_prime console
+	fs := flag.NewFlagSet("consumer", flag.ExitOnError)
+	agentsFlag := fs.String("agents", "", "Comma-separated list of A2A agent card URLs (env: "+agentURLsEnv+")")
+	agentsFile := fs.String("agents_file", "", `JSON registry file listing agent card URLs, e.g. {"agents": ["http://localhost:8001"]}`)
+	registryURL := fs.String("registry", os.Getenv("A2A_REGISTRY_URL"), "A2A registry URL; when set, agents are discovered from the registry")
+	skill := fs.String("skill", "", "Only use registry agents that have this skill (ID or name)")
+	tag := fs.String("tag", "", "Only use registry agents that have a skill with this tag")
+	// 원격 서버가 인증을 요구할 때 사용할 자격 증명
+	bearerToken := fs.String("bearer_token", os.Getenv("A2A_BEARER_TOKEN"), "Bearer token sent to remote agents")
+	hmacKey := fs.String("hmac_key", os.Getenv("A2A_HMAC_KEY"), "HMAC signing key for remote agents, e.g. alice:secret1")
+	tlsCA := fs.String("tls_ca", "", "CA used to verify https remote agents")
+	tlsCert := fs.String("tls_cert", "", "Client certificate for mTLS")
+	tlsKey := fs.String("tls_key", "", "Client private key for mTLS")
+	// 원격 서버가 느리거나 죽어 있을 때의 동작
+	resilience := resilienceConfig{BaseBackoff: 200 * time.Millisecond, MaxBackoff: 2 * time.Second}
+	fs.DurationVar(&resilience.CallTimeout, "call_timeout", 60*time.Second, "Deadline for a single remote agent call, including retries")
+	fs.IntVar(&resilience.MaxRetries, "retries", 2, "Retries for requests that never reached the remote agent")
+	fs.IntVar(&resilience.BreakerFailures, "breaker_failures", 5, "Consecutive failures that open the circuit breaker")
+	fs.DurationVar(&resilience.BreakerCooldown, "breaker_cooldown", 30*time.Second, "How long an open circuit breaker rejects calls")
+	_ = fs.Parse(os.Args[1:])
+	if err := resilience.validate(); err != nil {
+		log.Fatalf("Invalid resilience options: %v", err)
+	}
+
+	creds := a2aauth.ClientCredentials{BearerToken: *bearerToken, CAFile: *tlsCA, CertFile: *tlsCert, KeyFile: *tlsKey}
+	if *hmacKey != "" {
+		keyID, secret, ok := strings.Cut(*hmacKey, ":")
+		if !ok {
+			log.Fatalf("Invalid --hmac_key, want <keyId>:<secret>")
+		}
+		creds.HMACKeyID, creds.HMACSecret = keyID, []byte(secret)
+	}
+	d, err := newDiscovery(creds, resilience)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// 3. 메인 에이전트(MathTutor) 수정
-	mathTutor, _ := llmagent.New(llmagent.Config{
+	var remotes []remoteAgentInfo
+	if *registryURL != "" {
+		// 레지스트리에서 스킬/태그로 검색해서 SubAgents를 동적으로 구성
+		remotes, err = d.discoverFromRegistry(ctx, *registryURL, a2aregistry.Query{Skill: *skill, Tag: *tag})
+	} else {
+		var urls []string
+		urls, err = resolveAgentURLs(*agentsFlag, *agentsFile)
+		if err == nil {
+			remotes, err = d.discoverRemoteAgents(ctx, urls)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to discover remote agents:\n%v", err)
+	}
+
+	var subAgents []agent.Agent
+	for _, r := range remotes {
+		log.Printf("Discovered remote agent %q at %s", r.Card.Name, r.Source)
+		subAgents = append(subAgents, r.Agent)
+	}
+
+	// 3. 메인 에이전트(MathTutor) 설정
+	mathTutor, err := llmagent.New(llmagent.Config{
 		Name:  "MathTutor",
 		Model: model,
-		// 지시문 수정: 소수뿐만 아니라 다른 수학 질문도 원격 에이전트에게 물어보라고 지시
-		Instruction: "You are a math tutor. If the user asks about checking primes, calculating factorials, or finding the GCD, delegate the task to the RemoteMathHelper.",
+		// 지시문도 발견한 원격 에이전트 목록으로 만들어서, 카드가 바뀌어도 코드를 고칠 필요가 없습니다.
+		Instruction: tutorInstruction(remotes),
 		// SubAgents에 원격 에이전트 등록
-		SubAgents: []agent.Agent{remoteMathAgent},
+		SubAgents: subAgents,
 	})
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
 
 	// 4. 런처 실행 설정
 	config := &launcher.Config{
@@ -69,7 +127,18 @@ func main() {
 	l := full.NewLauncher()
 
 	// 실행 (터미널에서 질문 입력 가능)
-	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+	if err = l.Execute(ctx, config, fs.Args()); err != nil {
 		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
 	}
 }
+
+// tutorInstruction은 원격 에이전트 카드 정보를 바탕으로 MathTutor의 지시문을 만듭니다.
+func tutorInstruction(remotes []remoteAgentInfo) string {
+	var b strings.Builder
+	b.WriteString("You are a math tutor. When the user asks for something one of the following remote agents can do, delegate the task to that agent.\n")
+	for _, r := range remotes {
+		fmt.Fprintf(&b, "- %s: %s\n", r.Card.Name, describeCard(r.Card))
+	}
+	b.WriteString("If a remote agent reports that it is unavailable, tell the user the capability is temporarily unavailable instead of guessing the answer.\n")
+	return b.String()
+}
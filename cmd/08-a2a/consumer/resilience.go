@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// resilienceConfig는 원격 에이전트 호출을 얼마나 참고 기다릴지 정합니다.
+type resilienceConfig struct {
+	// CallTimeout은 원격 에이전트 호출 한 번(재시도 포함)에 허용하는 최대 시간입니다.
+	CallTimeout time.Duration
+	// MaxRetries는 요청이 서버에 전달되지 못한 실패(연결 실패, 429, Retry-After가 붙은 503)에 대한 재시도 횟수입니다.
+	MaxRetries int
+	// BaseBackoff부터 두 배씩 늘려 MaxBackoff까지 기다립니다.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// BreakerFailures번 연속 실패하면 BreakerCooldown 동안 호출을 막습니다.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+}
+
+// validate는 설정 값이 쓸 수 있는 범위인지 확인합니다.
+// 실패 기준이 0이면 첫 호출이 실패하기도 전에 서킷이 열린 것과 같으므로 받지 않습니다.
+func (c resilienceConfig) validate() error {
+	switch {
+	case c.CallTimeout <= 0:
+		return fmt.Errorf("call_timeout must be positive, got %s", c.CallTimeout)
+	case c.MaxRetries < 0:
+		return fmt.Errorf("retries must not be negative, got %d", c.MaxRetries)
+	case c.BreakerFailures <= 0:
+		return fmt.Errorf("breaker_failures must be positive, got %d", c.BreakerFailures)
+	case c.BreakerCooldown < 0:
+		return fmt.Errorf("breaker_cooldown must not be negative, got %s", c.BreakerCooldown)
+	}
+	return nil
+}
+
+var errCircuitOpen = errors.New("circuit breaker is open")
+
+// --- Circuit Breaker ---
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker는 원격 에이전트마다 하나씩 두고, 계속 실패하는 서버로 요청을 보내지 않도록 막습니다.
+// 쿨다운이 지나면 한 번만 시험 삼아 호출(half-open)해보고, 성공하면 다시 닫습니다.
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{name: name, threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow는 지금 요청을 보내도 되는지 확인합니다.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return errCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		return nil
+	case breakerHalfOpen:
+		// 시험 호출이 끝날 때까지는 다른 요청을 막습니다.
+		return errCircuitOpen
+	}
+	return nil
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerClosed {
+		log.Printf("[breaker] %s: closed", b.name)
+	}
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		if b.state != breakerOpen {
+			log.Printf("[breaker] %s: open for %s after %d failure(s)", b.name, b.cooldown, b.failures)
+		}
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// --- 재시도 Transport ---
+
+// retryTransport는 요청이 서버에서 처리되지 않았다고 확신할 수 있는 실패만 재시도합니다.
+// 연결 자체가 안 된 경우(dial 실패)와 서버가 받지 않았다고 알려준 경우(429, Retry-After가 붙은 503)뿐입니다.
+// 본문을 보낸 뒤 연결이 끊긴 경우(connection reset, EOF)나 502/504는 서버가 작업을 이미 실행했을 수 있으므로,
+// message/send가 두 번 실행되지 않도록 재시도하지 않습니다.
+// 서킷 브레이커에는 재시도 횟수와 관계없이 RoundTrip 한 번을 성공 또는 실패 하나로 기록합니다.
+type retryTransport struct {
+	next    http.RoundTripper
+	cfg     resilienceConfig
+	breaker *circuitBreaker
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.allow(); err != nil {
+		return nil, fmt.Errorf("%s: %w", t.breaker.name, err)
+	}
+	resp, err := t.send(req)
+	if err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		t.breaker.failure()
+	} else {
+		t.breaker.success()
+	}
+	return resp, err
+}
+
+// send는 요청을 보내고, 재시도할 수 있는 실패면 MaxRetries번까지 다시 보냅니다.
+func (t *retryTransport) send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if req.GetBody == nil && req.Body != nil {
+				return nil, fmt.Errorf("cannot retry request without GetBody")
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req = req.Clone(req.Context())
+				req.Body = body
+			}
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		retryAfter, retryable := classify(resp, err)
+		if !retryable || attempt >= t.cfg.MaxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+		wait := t.backoff(attempt, retryAfter)
+		// 기다리는 동안 호출 마감 시간이 지나 버린다면 기다리지 않고 지금 받은 실패를 그대로 돌려줍니다.
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		log.Printf("[retry] %s %s failed (%s), retrying in %s", req.Method, req.URL, describeFailure(resp, err), wait)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// classify는 요청이 서버에 전달되지 않았다고 확신할 수 있는 실패인지 판단합니다.
+func classify(resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		// 연결을 맺지 못했다면(거부, DNS 실패 등) 요청은 한 바이트도 나가지 않았습니다.
+		var opErr *net.OpError
+		return 0, errors.Is(err, syscall.ECONNREFUSED) || (errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		secs, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(secs) * time.Second, true
+	case http.StatusServiceUnavailable:
+		// Retry-After가 있는 503은 서버가 요청을 받지 않고 돌려보냈다는 뜻입니다(예: prime의 대기열 가득 참).
+		v := resp.Header.Get("Retry-After")
+		if v == "" {
+			return 0, false
+		}
+		secs, _ := strconv.Atoi(v)
+		return time.Duration(secs) * time.Second, true
+	}
+	return 0, false
+}
+
+// backoff는 attempt번째 재시도 전에 기다릴 시간입니다. 서버가 Retry-After로 알려준 시간이 더 길면 그만큼 기다립니다.
+// 그보다 일찍 보내면 서버가 또 거절할 것이므로 Retry-After는 MaxBackoff로 줄이지 않습니다.
+func (t *retryTransport) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	wait := t.cfg.BaseBackoff << attempt
+	if wait > t.cfg.MaxBackoff || wait <= 0 {
+		wait = t.cfg.MaxBackoff
+	}
+	// 여러 클라이언트가 동시에 재시도하지 않도록 ±20% 흔들어 줍니다.
+	wait += time.Duration((rand.Float64() - 0.5) * 0.4 * float64(wait))
+	return max(wait, retryAfter)
+}
+
+func describeFailure(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// --- 원격 에이전트 래퍼 ---
+
+// newResilientAgent는 원격 에이전트 호출에 시간 제한을 걸고,
+// 끝내 실패하면 에러 대신 "지금은 사용할 수 없다"는 안내 메시지를 남기는 에이전트로 감쌉니다.
+// 덕분에 원격 서버가 죽어 있어도 MathTutor의 실행 전체가 실패하지 않습니다.
+func newResilientAgent(remote agent.Agent, callTimeout time.Duration) (agent.Agent, error) {
+	return agent.New(agent.Config{
+		Name:        remote.Name(),
+		Description: remote.Description(),
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				ctx, cancel := context.WithTimeout(ic, callTimeout)
+				defer cancel()
+
+				answered := false
+				for event, err := range remote.Run(deadlineContext{InvocationContext: ic, ctx: ctx}) {
+					// remoteagent는 호출 실패를 ErrorMessage가 담긴 이벤트로 알려줍니다.
+					if err != nil || (event != nil && event.ErrorMessage != "") {
+						var reason string
+						if err != nil {
+							reason = err.Error()
+						} else {
+							reason = event.ErrorMessage
+						}
+						log.Printf("[remote] %s failed: %s", remote.Name(), reason)
+						yield(unavailableEvent(ic, remote.Name(), answered), nil)
+						return
+					}
+					if event == nil {
+						continue
+					}
+					answered = answered || event.Content != nil
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		},
+	})
+}
+
+// unavailableEvent는 원격 호출 실패를 사용자에게 알리는 메시지 이벤트를 만듭니다.
+func unavailableEvent(ic agent.InvocationContext, name string, partial bool) *session.Event {
+	text := fmt.Sprintf("죄송합니다. 지금은 원격 계산 기능(%s)을 사용할 수 없어요. 잠시 후 다시 시도해 주세요.", name)
+	if partial {
+		text = fmt.Sprintf("원격 계산 기능(%s)의 응답이 중간에 끊겼어요. 위 결과가 완전하지 않을 수 있으니 잠시 후 다시 시도해 주세요.", name)
+	}
+	event := session.NewEvent(ic.InvocationID())
+	event.Author = name
+	event.Branch = ic.Branch()
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)}
+	return event
+}
+
+// deadlineContext는 InvocationContext는 그대로 두고, 취소/마감 시간만 ctx의 것을 쓰게 합니다.
+type deadlineContext struct {
+	agent.InvocationContext
+	ctx context.Context
+}
+
+func (c deadlineContext) Deadline() (time.Time, bool) { return c.ctx.Deadline() }
+func (c deadlineContext) Done() <-chan struct{}       { return c.ctx.Done() }
+func (c deadlineContext) Err() error                  { return c.ctx.Err() }
+func (c deadlineContext) Value(key any) any           { return c.ctx.Value(key) }
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func response(status int, header http.Header) *http.Response {
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Header: header, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func TestRetryTransportRetriesOnlyUndelivered(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+	tests := []struct {
+		name     string
+		resp     *http.Response
+		err      error
+		attempts int // MaxRetries가 2일 때 보내는 횟수
+	}{
+		{"dial refused", nil, dialErr, 3},
+		{"429", response(http.StatusTooManyRequests, nil), nil, 3},
+		{"503 with Retry-After", response(http.StatusServiceUnavailable, http.Header{"Retry-After": {"0"}}), nil, 3},
+		{"503 without Retry-After", response(http.StatusServiceUnavailable, nil), nil, 1},
+		{"502", response(http.StatusBadGateway, nil), nil, 1},
+		{"504", response(http.StatusGatewayTimeout, nil), nil, 1},
+		{"reset after write", nil, resetErr, 1},
+		{"EOF after write", nil, io.ErrUnexpectedEOF, 1},
+		{"200", response(http.StatusOK, nil), nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			rt := &retryTransport{
+				next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+					attempts++
+					return tt.resp, tt.err
+				}),
+				cfg:     resilienceConfig{MaxRetries: 2, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+				breaker: newCircuitBreaker("prime", 10, time.Minute),
+			}
+			req, _ := http.NewRequest(http.MethodPost, "http://prime.local/", strings.NewReader("{}"))
+			rt.RoundTrip(req)
+			if attempts != tt.attempts {
+				t.Errorf("sent %d time(s), want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCountsOneFailurePerCall(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	rt := &retryTransport{
+		next:    roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, dialErr }),
+		cfg:     resilienceConfig{MaxRetries: 3, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		breaker: newCircuitBreaker("prime", 3, time.Minute),
+	}
+	for call := 1; call <= 3; call++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://prime.local/", nil)
+		_, err := rt.RoundTrip(req)
+		if errors.Is(err, errCircuitOpen) {
+			t.Fatalf("call %d: breaker opened after %d failed call(s), want threshold 3", call, call-1)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://prime.local/", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("call 4: err = %v, want errCircuitOpen", err)
+	}
+}
+
+func TestResilienceConfigValidate(t *testing.T) {
+	valid := resilienceConfig{CallTimeout: time.Minute, MaxRetries: 2, BreakerFailures: 5, BreakerCooldown: 30 * time.Second}
+	tests := []struct {
+		name   string
+		modify func(*resilienceConfig)
+		ok     bool
+	}{
+		{"defaults", func(*resilienceConfig) {}, true},
+		{"no retries", func(c *resilienceConfig) { c.MaxRetries = 0 }, true},
+		{"negative retries", func(c *resilienceConfig) { c.MaxRetries = -1 }, false},
+		{"zero breaker_failures", func(c *resilienceConfig) { c.BreakerFailures = 0 }, false},
+		{"zero call_timeout", func(c *resilienceConfig) { c.CallTimeout = 0 }, false},
+		{"negative breaker_cooldown", func(c *resilienceConfig) { c.BreakerCooldown = -time.Second }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if err := cfg.validate(); (err == nil) != tt.ok {
+				t.Errorf("validate() = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newCircuitBreaker("prime", 2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	if err := b.allow(); err != nil {
+		t.Fatalf("opened after one failure: %v", err)
+	}
+	b.failure()
+	if err := b.allow(); !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("after two failures: %v, want errCircuitOpen", err)
+	}
+
+	// 쿨다운이 지나면 시험 호출 하나만 보냅니다.
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil || b.state != breakerHalfOpen {
+		t.Fatalf("after cooldown: %v, state %v, want half-open", err, b.state)
+	}
+	if err := b.allow(); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("second call while half-open: %v, want errCircuitOpen", err)
+	}
+	// 시험 호출이 실패하면 기준 횟수와 관계없이 다시 열고, 쿨다운도 처음부터 다시 잽니다.
+	b.failure()
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("after a failed probe: %v, want errCircuitOpen", err)
+	}
+
+	// 다음 시험 호출이 성공하면 닫히고, 실패 횟수도 처음부터 셉니다.
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("second probe: %v", err)
+	}
+	b.success()
+	b.failure()
+	for i := range 3 {
+		if err := b.allow(); err != nil {
+			t.Errorf("call %d after closing: %v", i+1, err)
+		}
+	}
+}
+
+func TestBackoffHonoursRetryAfter(t *testing.T) {
+	rt := &retryTransport{cfg: resilienceConfig{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}}
+	for attempt := range 6 {
+		wait := rt.backoff(attempt, 0)
+		base := min(100*time.Millisecond<<attempt, time.Second)
+		if wait < base*8/10 || wait > base*12/10 {
+			t.Errorf("attempt %d: wait %v, want %v ±20%%", attempt, wait, base)
+		}
+	}
+	// 서버가 알려준 시간이 MaxBackoff보다 길어도 그만큼 기다립니다.
+	if wait := rt.backoff(0, 5*time.Second); wait != 5*time.Second {
+		t.Errorf("Retry-After 5s: wait %v", wait)
+	}
+	if wait := rt.backoff(3, 10*time.Millisecond); wait < 800*time.Millisecond*8/10 {
+		t.Errorf("short Retry-After shortened the backoff to %v", wait)
+	}
+}
+
+func TestRetryTransportGivesUpBeforeDeadline(t *testing.T) {
+	attempts := 0
+	rt := &retryTransport{
+		next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			attempts++
+			return response(http.StatusTooManyRequests, http.Header{"Retry-After": {"10"}}), nil
+		}),
+		cfg:     resilienceConfig{MaxRetries: 2, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		breaker: newCircuitBreaker("prime", 10, time.Minute),
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://prime.local/", nil)
+
+	start := time.Now()
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("RoundTrip = %v, %v, want the 429 response", resp, err)
+	}
+	// 10초를 기다리면 1초 마감을 넘기므로 바로 돌려줍니다.
+	if attempts != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("sent %d time(s) in %v, want 1 without waiting", attempts, time.Since(start))
+	}
+}
+
+// fakeRemote는 run이 정한 대로 이벤트나 오류를 내놓는 원격 에이전트입니다.
+func fakeRemote(t *testing.T, run func(ic agent.InvocationContext, yield func(*session.Event, error) bool)) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name:        "prime_agent",
+		Description: "fake remote",
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) { run(ic, yield) }
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func remoteEvent(ic agent.InvocationContext, text, errorMessage string) *session.Event {
+	ev := session.NewEvent(ic.InvocationID())
+	ev.Author = "prime_agent"
+	if text != "" {
+		ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)}
+	}
+	ev.ErrorMessage = errorMessage
+	return ev
+}
+
+func TestResilientAgentFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ic agent.InvocationContext, yield func(*session.Event, error) bool)
+		want []string
+	}{
+		{
+			name: "성공하면 그대로 전달",
+			run: func(ic agent.InvocationContext, yield func(*session.Event, error) bool) {
+				yield(remoteEvent(ic, "17은 소수입니다", ""), nil)
+			},
+			want: []string{"17은 소수입니다"},
+		},
+		{
+			name: "오류면 안내 메시지",
+			run: func(ic agent.InvocationContext, yield func(*session.Event, error) bool) {
+				yield(nil, errors.New("connection refused"))
+			},
+			want: []string{"지금은 원격 계산 기능(prime_agent)을 사용할 수 없어요"},
+		},
+		{
+			name: "ErrorMessage 이벤트도 실패",
+			run: func(ic agent.InvocationContext, yield func(*session.Event, error) bool) {
+				yield(remoteEvent(ic, "", "circuit breaker is open"), nil)
+			},
+			want: []string{"지금은 원격 계산 기능(prime_agent)을 사용할 수 없어요"},
+		},
+		{
+			name: "답하다가 끊기면 중간에 끊겼다고 안내",
+			run: func(ic agent.InvocationContext, yield func(*session.Event, error) bool) {
+				if yield(remoteEvent(ic, "계산 중...", ""), nil) {
+					yield(nil, io.ErrUnexpectedEOF)
+				}
+			},
+			want: []string{"계산 중...", "응답이 중간에 끊겼어요"},
+		},
+		{
+			name: "시간 초과",
+			run: func(ic agent.InvocationContext, yield func(*session.Event, error) bool) {
+				<-ic.Done()
+				yield(nil, ic.Err())
+			},
+			want: []string{"지금은 원격 계산 기능(prime_agent)을 사용할 수 없어요"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped, err := newResilientAgent(fakeRemote(t, tt.run), 50*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessions := session.InMemoryService()
+			r, err := runner.New(runner.Config{AppName: "consumer", Agent: wrapped, SessionService: sessions})
+			if err != nil {
+				t.Fatal(err)
+			}
+			created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "consumer", UserID: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for event, err := range r.Run(t.Context(), "alice", created.Session.ID(), genai.NewContentFromText("17은 소수야?", genai.RoleUser), agent.RunConfig{}) {
+				if err != nil {
+					t.Fatalf("run failed: %v", err)
+				}
+				if event.Content != nil {
+					got = append(got, event.Content.Parts[0].Text)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("events = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("event %d = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
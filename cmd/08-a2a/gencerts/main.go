@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 로컬 테스트용 인증서 생성기
+// prime 서버를 https + mTLS로 띄워보기 위해 자체 서명 CA와 서버/클라이언트 인증서를 만듭니다.
+// 실제 서비스에서는 사내 CA나 인증서 관리 도구를 사용하세요.
+//
+//	go run ./cmd/08-a2a/gencerts --out certs
+//	-> certs/ca.pem, certs/server.pem, certs/server-key.pem, certs/client.pem, certs/client-key.pem
+
+func main() {
+	out := flag.String("out", "certs", "Output directory")
+	clientName := flag.String("client_name", "consumer", "Common Name of the client certificate (used as caller identity)")
+	validFor := flag.Duration("valid_for", 30*24*time.Hour, "Certificate lifetime")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("Failed to create output dir: %v", err)
+	}
+
+	// 1. CA
+	caKey, caCert := mustIssue(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "A2A Local Dev CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}, nil, nil, *validFor)
+	writePEM(*out, "ca.pem", "CERTIFICATE", caCert.Raw)
+
+	// 2. 서버 인증서 (localhost)
+	serverKey, serverCert := mustIssue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, caKey, *validFor)
+	writePEM(*out, "server.pem", "CERTIFICATE", serverCert.Raw)
+	writeKey(*out, "server-key.pem", serverKey)
+
+	// 3. 클라이언트 인증서 (mTLS)
+	clientKey, clientCert := mustIssue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: *clientName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey, *validFor)
+	writePEM(*out, "client.pem", "CERTIFICATE", clientCert.Raw)
+	writeKey(*out, "client-key.pem", clientKey)
+
+	fmt.Printf("Certificates written to %s\n", *out)
+}
+
+// mustIssue는 tmpl로 인증서를 발급합니다. parent가 nil이면 자체 서명합니다.
+func mustIssue(tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, validFor time.Duration) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		log.Fatalf("Failed to generate serial: %v", err)
+	}
+	tmpl.SerialNumber = serial
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(validFor)
+
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		log.Fatalf("Failed to create certificate %q: %v", tmpl.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		log.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return key, cert
+}
+
+func writeKey(dir, name string, key *ecdsa.PrivateKey) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		log.Fatalf("Failed to marshal key: %v", err)
+	}
+	writePEM(dir, name, "EC PRIVATE KEY", der)
+}
+
+func writePEM(dir, name, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		log.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
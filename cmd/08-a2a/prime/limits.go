@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"awesomeProject2/internal/a2aauth"
+)
+
+// limitConfig는 서버 보호를 위한 제한 값입니다.
+type limitConfig struct {
+	// Rate/Burst는 호출자마다의 토큰 버킷 설정입니다 (초당 요청 수 / 순간 최대 요청 수).
+	Rate  float64
+	Burst int
+	// MaxInFlight는 동시에 실행할 수 있는 작업(message/send, message/stream) 수입니다.
+	MaxInFlight int
+	// MaxQueue는 자리가 날 때까지 기다릴 수 있는 요청 수, QueueTimeout은 최대 대기 시간입니다.
+	MaxQueue     int
+	QueueTimeout time.Duration
+	// WriteTimeout은 자리를 얻은 뒤 작업 하나가 응답을 다 쓸 때까지 주는 시간입니다.
+	// 서버의 쓰기 마감 시간은 요청을 읽을 때부터 흐르므로, 대기열에서 기다린 시간만큼 작업 시간이 줄지 않게 자리를 얻은 시점부터 다시 잽니다.
+	WriteTimeout time.Duration
+}
+
+// validate는 제한 값이 쓸 수 있는 범위인지 확인합니다.
+// 0이면 토큰이 다시 차지 않거나(rate), 자리가 하나도 없어(max_inflight) 모든 요청이 거절되므로 받지 않습니다.
+func (c limitConfig) validate() error {
+	switch {
+	case c.Rate <= 0:
+		return fmt.Errorf("rate must be positive, got %v", c.Rate)
+	case c.Burst <= 0:
+		return fmt.Errorf("burst must be positive, got %d", c.Burst)
+	case c.MaxInFlight <= 0:
+		return fmt.Errorf("max_inflight must be positive, got %d", c.MaxInFlight)
+	case c.MaxQueue < 0:
+		return fmt.Errorf("max_queue must not be negative, got %d", c.MaxQueue)
+	case c.QueueTimeout <= 0:
+		return fmt.Errorf("queue_timeout must be positive, got %s", c.QueueTimeout)
+	}
+	return nil
+}
+
+// 작업을 실제로 실행(= Gemini 호출)하는 JSON-RPC 메서드
+var taskMethods = map[string]bool{
+	"message/send":   true,
+	"message/stream": true,
+}
+
+// id/method 확인을 위해 읽어들이는 요청 본문의 최대 크기
+const maxRequestBody = 10 << 20
+
+// 오래 쓰이지 않은 호출자 버킷을 정리하는 기준
+const bucketIdleTTL = 10 * time.Minute
+
+// --- 토큰 버킷 ---
+
+type tokenBucket struct {
+	tokens   float64
+	last     time.Time
+	lastUsed time.Time
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), now: time.Now, buckets: make(map[string]*tokenBucket)}
+}
+
+// allow는 client의 버킷에서 토큰 하나를 꺼냅니다. 토큰이 없으면 다음 토큰까지 기다려야 하는 시간을 반환합니다.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweepLocked(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last, b.lastUsed = now, now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+func (l *rateLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if now.Sub(b.lastUsed) > bucketIdleTTL {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// --- 동시 실행 제한 + 대기열 ---
+
+type errLimit struct {
+	kind       string // 지표 라벨: rate_limit, queue_full, queue_timeout
+	reason     string
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *errLimit) Error() string { return e.reason }
+
+type concurrencyLimiter struct {
+	slots        chan struct{}
+	maxQueue     int64
+	queueTimeout time.Duration
+	queued       atomic.Int64
+}
+
+func newConcurrencyLimiter(maxInFlight, maxQueue int, queueTimeout time.Duration) *concurrencyLimiter {
+	return &concurrencyLimiter{slots: make(chan struct{}, maxInFlight), maxQueue: int64(maxQueue), queueTimeout: queueTimeout}
+}
+
+// acquire는 실행 자리를 얻을 때까지 기다립니다. 얻으면 반환된 함수로 자리를 돌려줘야 합니다.
+func (c *concurrencyLimiter) acquire(r *http.Request) (func(), error) {
+	release := func() { <-c.slots }
+
+	select {
+	case c.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if c.queued.Add(1) > c.maxQueue {
+		c.queued.Add(-1)
+		return nil, &errLimit{kind: "queue_full", reason: "server is at capacity", status: http.StatusServiceUnavailable, retryAfter: time.Second}
+	}
+	defer c.queued.Add(-1)
+
+	timer := time.NewTimer(c.queueTimeout)
+	defer timer.Stop()
+	select {
+	case c.slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, &errLimit{kind: "queue_timeout", reason: "timed out waiting for a free execution slot", status: http.StatusServiceUnavailable, retryAfter: time.Second}
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+}
+
+func (c *concurrencyLimiter) inFlight() int { return len(c.slots) }
+
+// --- 지표 (Metrics) ---
+
+// 요청 수 지표에 따로 보여줄 최대 호출자 수. 인증이 없으면 호출자가 접속 IP이므로 끝없이 늘 수 있어,
+// 이보다 많아지면 처음 보는 호출자는 otherClients 하나로 묶어 셉니다.
+const maxClientLabels = 100
+
+const otherClients = "other"
+
+type limitMetrics struct {
+	mu       sync.Mutex
+	requests map[string]int64 // 호출자별 전체 요청 수 (최대 maxClientLabels명 + otherClients)
+	rejected map[string]int64 // 거절 사유(kind)별 거절 수
+}
+
+func newLimitMetrics() *limitMetrics {
+	return &limitMetrics{requests: make(map[string]int64), rejected: make(map[string]int64)}
+}
+
+func (m *limitMetrics) request(client string) {
+	m.mu.Lock()
+	if _, ok := m.requests[client]; !ok && len(m.requests) >= maxClientLabels {
+		client = otherClients
+	}
+	m.requests[client]++
+	m.mu.Unlock()
+}
+
+func (m *limitMetrics) reject(kind string) {
+	m.mu.Lock()
+	m.rejected[kind]++
+	m.mu.Unlock()
+}
+
+// --- 미들웨어 ---
+
+// limiter는 A2A 호출 경로 앞에서 호출자별 요청 속도와 전체 동시 실행 수를 제한합니다.
+type limiter struct {
+	rate         *rateLimiter
+	concurrency  *concurrencyLimiter
+	metrics      *limitMetrics
+	writeTimeout time.Duration
+}
+
+func newLimiter(cfg limitConfig) *limiter {
+	return &limiter{
+		rate:         newRateLimiter(cfg.Rate, cfg.Burst),
+		concurrency:  newConcurrencyLimiter(cfg.MaxInFlight, cfg.MaxQueue, cfg.QueueTimeout),
+		metrics:      newLimitMetrics(),
+		writeTimeout: cfg.WriteTimeout,
+	}
+}
+
+func (l *limiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != a2aInvokePath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := clientID(r)
+		l.metrics.request(client)
+
+		// 본문을 읽기 전에 속도부터 확인해, 제한에 걸린 호출자가 큰 본문으로 서버 자원을 쓰지 못하게 합니다.
+		// 아직 요청 id를 모르므로 JSON-RPC 규칙대로 id를 null로 응답합니다.
+		if ok, wait := l.rate.allow(client); !ok {
+			l.reject(w, nil, client, &errLimit{kind: "rate_limit", reason: "rate limit exceeded", status: http.StatusTooManyRequests, retryAfter: wait})
+			return
+		}
+
+		// JSON-RPC 요청의 id와 method를 알아야 올바른 에러 응답을 만들 수 있습니다.
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+		if err != nil {
+			http.Error(w, "failed to read request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var rpc struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.Unmarshal(body, &rpc)
+
+		if taskMethods[rpc.Method] {
+			release, err := l.concurrency.acquire(r)
+			if err != nil {
+				if le, ok := err.(*errLimit); ok {
+					l.reject(w, rpc.ID, client, le)
+				}
+				return
+			}
+			defer release()
+
+			// 대기열에서 기다리는 동안 서버의 쓰기 마감 시간이 흘렀으므로, 작업 시간을 처음부터 다시 줍니다.
+			if l.writeTimeout > 0 {
+				err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(l.writeTimeout))
+				if err != nil && !errors.Is(err, http.ErrNotSupported) {
+					log.Printf("[limits] failed to extend write deadline: %v", err)
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject는 A2A 클라이언트가 이해할 수 있도록 JSON-RPC 에러(-32000 server error)로 응답합니다.
+// HTTP 상태와 Retry-After 헤더도 함께 설정해 클라이언트가 언제 다시 시도할지 알 수 있게 합니다.
+func (l *limiter) reject(w http.ResponseWriter, id any, client string, le *errLimit) {
+	l.metrics.reject(le.kind)
+	log.Printf("[limits] rejected %s: %s", client, le.reason)
+
+	retryAfter := int(math.Ceil(le.retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(le.status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]any{
+			"code":    -32000,
+			"message": "server error",
+			"data":    map[string]any{"error": le.reason},
+		},
+	})
+}
+
+// metricsHandler는 Prometheus 텍스트 형식으로 지표를 보여줍니다.
+func (l *limiter) metricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.metrics.mu.Lock()
+		defer l.metrics.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintln(w, "# TYPE a2a_requests_total counter")
+		for _, client := range sortedKeys(l.metrics.requests) {
+			fmt.Fprintf(w, "a2a_requests_total{client=%q} %d\n", client, l.metrics.requests[client])
+		}
+		fmt.Fprintln(w, "# TYPE a2a_rejected_total counter")
+		for _, reason := range sortedKeys(l.metrics.rejected) {
+			fmt.Fprintf(w, "a2a_rejected_total{reason=%q} %d\n", reason, l.metrics.rejected[reason])
+		}
+		fmt.Fprintln(w, "# TYPE a2a_inflight_tasks gauge")
+		fmt.Fprintf(w, "a2a_inflight_tasks %d\n", l.concurrency.inFlight())
+		fmt.Fprintln(w, "# TYPE a2a_queued_tasks gauge")
+		fmt.Fprintf(w, "a2a_queued_tasks %d\n", l.concurrency.queued.Load())
+	})
+}
+
+// clientID는 인증된 호출자 이름을, 없으면 접속 IP를 호출자 식별자로 씁니다.
+func clientID(r *http.Request) string {
+	if client, ok := a2aauth.ClientFrom(r.Context()); ok && client != "" {
+		return client
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimitConfigValidate(t *testing.T) {
+	valid := limitConfig{Rate: 1, Burst: 5, MaxInFlight: 4, MaxQueue: 16, QueueTimeout: 10 * time.Second}
+	tests := []struct {
+		name   string
+		modify func(*limitConfig)
+		ok     bool
+	}{
+		{"defaults", func(*limitConfig) {}, true},
+		{"no queue", func(c *limitConfig) { c.MaxQueue = 0 }, true},
+		{"zero rate", func(c *limitConfig) { c.Rate = 0 }, false},
+		{"zero burst", func(c *limitConfig) { c.Burst = 0 }, false},
+		{"zero max_inflight", func(c *limitConfig) { c.MaxInFlight = 0 }, false},
+		{"negative max_queue", func(c *limitConfig) { c.MaxQueue = -1 }, false},
+		{"zero queue_timeout", func(c *limitConfig) { c.QueueTimeout = 0 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if err := cfg.validate(); (err == nil) != tt.ok {
+				t.Errorf("validate() = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newRateLimiter(2, 2) // 초당 2개, 최대 2개
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.allow("alice"); !ok {
+			t.Fatalf("request %d within the burst was rejected", i+1)
+		}
+	}
+	ok, wait := l.allow("alice")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("over the burst: ok = %v, wait = %v, want false, 500ms", ok, wait)
+	}
+	// 호출자마다 버킷이 따로 있습니다.
+	if ok, _ := l.allow("bob"); !ok {
+		t.Error("bob was limited by alice's bucket")
+	}
+
+	// 0.25초 뒤에는 토큰이 반 개뿐이라 아직 안 되고, 남은 시간만큼 기다리라고 알려줍니다.
+	now = now.Add(250 * time.Millisecond)
+	if ok, wait := l.allow("alice"); ok || wait != 250*time.Millisecond {
+		t.Errorf("after 250ms: ok = %v, wait = %v, want false, 250ms", ok, wait)
+	}
+	now = now.Add(250 * time.Millisecond)
+	if ok, _ := l.allow("alice"); !ok {
+		t.Error("token was not refilled after 500ms")
+	}
+	// 오래 쉬어도 burst보다 많이 쌓이지 않습니다.
+	now = now.Add(time.Hour)
+	for i := range 3 {
+		if ok, _ := l.allow("alice"); ok != (i < 2) {
+			t.Errorf("after an hour, request %d: ok = %v", i+1, ok)
+		}
+	}
+	// 오래 쓰지 않은 버킷은 정리됩니다.
+	if _, ok := l.buckets["bob"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+}
+
+// testLimiter는 next 앞에 요청 제한 미들웨어를 붙입니다. next는 block이 닫힐 때까지 붙잡혀 있고, started로 받은 본문을 알립니다.
+type testLimiter struct {
+	lim     *limiter
+	handler http.Handler
+	started chan string
+	block   chan struct{}
+}
+
+func newTestLimiter(cfg limitConfig) *testLimiter {
+	tl := &testLimiter{lim: newLimiter(cfg), started: make(chan string, 16), block: make(chan struct{})}
+	tl.handler = tl.lim.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		select {
+		case tl.started <- string(body):
+		default: // 아무도 기다리지 않는 시작 알림은 버립니다.
+		}
+		<-tl.block
+		w.WriteHeader(http.StatusOK)
+	}))
+	return tl
+}
+
+func rpcBody(id int, method string) string {
+	return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "method": %q, "params": {}}`, id, method)
+}
+
+func (tl *testLimiter) do(client, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, a2aInvokePath, strings.NewReader(body))
+	req.RemoteAddr = client + ":1234"
+	rec := httptest.NewRecorder()
+	tl.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// assertRejected는 응답이 JSON-RPC -32000 오류와 Retry-After 헤더를 담고 있는지 확인합니다.
+func assertRejected(t *testing.T, rec *httptest.ResponseRecorder, status int, id any, reason, retryAfter string) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("status = %d, want %d", rec.Code, status)
+	}
+	if got := rec.Header().Get("Retry-After"); got != retryAfter {
+		t.Errorf("Retry-After = %q, want %q", got, retryAfter)
+	}
+	var resp struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      any    `json:"id"`
+		Error   struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Data    struct {
+				Error string `json:"error"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, rec.Body)
+	}
+	if resp.JSONRPC != "2.0" || resp.ID != id || resp.Error.Code != -32000 || resp.Error.Message != "server error" || resp.Error.Data.Error != reason {
+		t.Errorf("response = %+v, want id %v and reason %q", resp, id, reason)
+	}
+}
+
+func TestMiddlewareRateLimit(t *testing.T) {
+	tl := newTestLimiter(limitConfig{Rate: 0.5, Burst: 1, MaxInFlight: 4, QueueTimeout: time.Second})
+	close(tl.block)
+
+	// 허용된 요청은 본문을 그대로 다음 핸들러에 넘깁니다.
+	if rec := tl.do("10.0.0.1", rpcBody(1, "tasks/get")); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status %d", rec.Code)
+	}
+	if body := <-tl.started; body != rpcBody(1, "tasks/get") {
+		t.Errorf("handler got body %q", body)
+	}
+
+	// 본문을 읽기 전에 거절하므로 id는 null입니다. 토큰은 2초 뒤에 찹니다.
+	assertRejected(t, tl.do("10.0.0.1", rpcBody(2, "message/send")), http.StatusTooManyRequests, nil, "rate limit exceeded", "2")
+
+	// 다른 호출자는 영향을 받지 않습니다.
+	if rec := tl.do("10.0.0.2", rpcBody(3, "tasks/get")); rec.Code != http.StatusOK {
+		t.Errorf("other client: status %d", rec.Code)
+	}
+}
+
+func TestMiddlewareQueue(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxQueue int
+		reason   string
+	}{
+		{"queue_full", 0, "server is at capacity"},
+		{"queue_timeout", 1, "timed out waiting for a free execution slot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLimiter(limitConfig{Rate: 100, Burst: 100, MaxInFlight: 1, MaxQueue: tt.maxQueue, QueueTimeout: 20 * time.Millisecond})
+
+			// 첫 작업이 자리를 차지하고 있는 동안
+			var wg sync.WaitGroup
+			wg.Go(func() { tl.do("10.0.0.1", rpcBody(1, "message/send")) })
+			<-tl.started
+
+			assertRejected(t, tl.do("10.0.0.2", rpcBody(7, "message/stream")), http.StatusServiceUnavailable, float64(7), tt.reason, "1")
+			// 작업이 아닌 메서드는 자리를 기다리지 않습니다.
+			go tl.do("10.0.0.2", rpcBody(8, "tasks/get"))
+			select {
+			case <-tl.started:
+			case <-time.After(time.Second):
+				t.Error("tasks/get waited for an execution slot")
+			}
+
+			if got := tl.lim.metrics.rejected[tt.name]; got != 1 {
+				t.Errorf("rejected[%s] = %d, want 1", tt.name, got)
+			}
+			close(tl.block)
+			wg.Wait()
+		})
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	tl := newTestLimiter(limitConfig{Rate: 0.001, Burst: 1, MaxInFlight: 1, QueueTimeout: time.Second})
+	close(tl.block)
+	tl.do("10.0.0.1", rpcBody(1, "tasks/get"))
+	tl.do("10.0.0.1", rpcBody(2, "tasks/get"))
+	tl.do("10.0.0.2", rpcBody(3, "tasks/get"))
+
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		tl.lim.metricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return rec.Body.String()
+	}
+	out := scrape()
+	for _, want := range []string{
+		`a2a_requests_total{client="10.0.0.1"} 2`,
+		`a2a_requests_total{client="10.0.0.2"} 1`,
+		`a2a_rejected_total{reason="rate_limit"} 1`,
+		"a2a_inflight_tasks 0",
+		"a2a_queued_tasks 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, out)
+		}
+	}
+
+	// 접속 IP가 아무리 많아도 호출자 라벨은 maxClientLabels개를 넘지 않고, 나머지는 other로 셉니다.
+	for i := range 2 * maxClientLabels {
+		tl.do(fmt.Sprintf("10.1.%d.%d", i/256, i%256), rpcBody(i, "tasks/get"))
+	}
+	out = scrape()
+	if n := strings.Count(out, "a2a_requests_total{"); n != maxClientLabels+1 {
+		t.Errorf("%d client labels, want %d", n, maxClientLabels+1)
+	}
+	if want := fmt.Sprintf(`a2a_requests_total{client="other"} %d`, maxClientLabels+2); !strings.Contains(out, want) {
+		t.Errorf("metrics do not contain %q", want)
+	}
+}
@@ -2,21 +2,26 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"strconv"
+	"time"
 
 	// ADK(Agent Development Kit) 및 관련 라이브러리 임포트
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/cmd/launcher"
-	"google.golang.org/adk/cmd/launcher/web"
-	"google.golang.org/adk/cmd/launcher/web/a2a"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 	"google.golang.org/genai"
+
+	"awesomeProject2/internal/a2aauth"
+	"awesomeProject2/internal/a2aregistry"
 )
 
 // checkPrime은 에이전트가 실제로 호출할 Go 함수입니다.
@@ -58,9 +63,35 @@ func calculateGCD(ctx tool.Context, args struct{ A, B int }) (string, error) {
 	return strconv.Itoa(a), nil
 }
 
+// writeTimeout은 응답을 쓰는 데 주는 시간입니다. 대기열에서 자리를 얻은 작업은 그 시점부터 이 시간을 다시 받습니다.
+const writeTimeout = 15 * time.Second
+
 func main() {
 	ctx := context.Background()
 
+	port := flag.Int("port", 8001, "Port for the A2A server")
+	// 레지스트리 주소가 있으면 시작할 때 자기 카드를 등록하고 하트비트를 보냅니다.
+	registryURL := flag.String("registry", os.Getenv("A2A_REGISTRY_URL"), "A2A registry URL to self-register with (optional)")
+	registryToken := flag.String("registry_token", os.Getenv("A2A_REGISTRY_TOKEN"), "Token for registering with the A2A registry")
+	// 인증 설정: 설정한 방식 중 하나만 통과하면 됩니다. 아무것도 없으면 누구나 호출할 수 있습니다.
+	bearerTokens := flag.String("bearer_tokens", os.Getenv("A2A_BEARER_TOKENS"), "Accepted static bearer tokens, e.g. alice:token1,bob:token2")
+	hmacKeys := flag.String("hmac_keys", os.Getenv("A2A_HMAC_KEYS"), "Accepted HMAC signing keys, e.g. alice:secret1")
+	tlsCert := flag.String("tls_cert", "", "Server certificate (enables https)")
+	tlsKey := flag.String("tls_key", "", "Server private key")
+	clientCA := flag.String("client_ca", "", "CA for client certificates (enables mTLS auth, requires --tls_cert)")
+	// 요청 제한: 작업 하나하나가 Gemini를 호출하므로 호출자별 속도와 전체 동시 실행 수를 제한합니다.
+	var limits limitConfig
+	flag.Float64Var(&limits.Rate, "rate", 1, "Requests per second allowed per client")
+	flag.IntVar(&limits.Burst, "burst", 5, "Burst size of the per-client rate limit")
+	flag.IntVar(&limits.MaxInFlight, "max_inflight", 4, "Maximum number of tasks executing at once")
+	flag.IntVar(&limits.MaxQueue, "max_queue", 16, "Maximum number of tasks waiting for a free slot")
+	flag.DurationVar(&limits.QueueTimeout, "queue_timeout", 10*time.Second, "How long a task may wait for a free slot")
+	flag.Parse()
+	if err := limits.validate(); err != nil {
+		log.Fatalf("Invalid limits: %v", err)
+	}
+	limits.WriteTimeout = writeTimeout
+
 	// 1. Gemini 모델 초기화
 	// 지정된 모델명("gemini-3-pro-preview")을 사용하여 클라이언트를 생성합니다.
 	model, _ := gemini.NewModel(ctx, "gemini-3-pro-preview", &genai.ClientConfig{})
@@ -94,28 +125,79 @@ func main() {
 		Tools: []tool.Tool{primeTool, factorialTool, gcdTool},
 	})
 
-	// 4. 웹 서버 런처 설정
-	// A2A(Agent-to-Agent) 통신을 지원하는 웹 런처를 생성합니다.
-	port := 8001
-	webLauncher := web.NewLauncher(a2a.NewLauncher())
-
-	// 서버 포트 및 A2A 에이전트 URL 설정 (커맨드 라인 인자를 코드로 파싱)
-	webLauncher.Parse([]string{
-		"--port", strconv.Itoa(port),
-		"a2a",
-		"--a2a_agent_url", fmt.Sprintf("http://localhost:%d", port),
-	})
+	// 4. 인증 방식 구성
+	var auths []a2aauth.Authenticator
+	if *bearerTokens != "" {
+		tokens, err := a2aauth.ParseBearerTokens(*bearerTokens)
+		if err != nil {
+			log.Fatalf("Invalid --bearer_tokens: %v", err)
+		}
+		auths = append(auths, a2aauth.NewBearer(tokens))
+	}
+	if *hmacKeys != "" {
+		keys, err := a2aauth.ParseHMACKeys(*hmacKeys)
+		if err != nil {
+			log.Fatalf("Invalid --hmac_keys: %v", err)
+		}
+		auths = append(auths, a2aauth.NewHMAC(keys))
+	}
+	if *clientCA != "" {
+		if *tlsCert == "" {
+			log.Fatalf("--client_ca requires --tls_cert and --tls_key")
+		}
+		auths = append(auths, a2aauth.MutualTLS{})
+	}
 
-	// 5. 런처 구성
+	// 5. 서버 구성
 	// 단일 에이전트 로더와 인메모리 세션 저장소를 설정합니다.
 	config := &launcher.Config{
 		AgentLoader:    agent.NewSingleLoader(mathAgent), // 위에서 만든 primeAgent 하나만 로드
 		SessionService: session.InMemoryService(),        // 세션 데이터를 메모리에 저장 (재시작 시 초기화됨)
 	}
 
-	log.Printf("Starting Prime Server on port %d...", port)
+	// a2a 런처 대신 카드와 핸들러를 직접 만들어, 카드에 인증 방식을 광고하고 인증 미들웨어를 붙입니다.
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+	}
+	agentURL := fmt.Sprintf("%s://localhost:%d", scheme, *port)
+	card, err := newAgentCard(config, agentURL, auths)
+	if err != nil {
+		log.Fatalf("Failed to build agent card: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", *port),
+		Handler:      newA2AHandler(config, card, auths, newLimiter(limits)),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  60 * time.Second,
+	}
+	if *tlsCert != "" {
+		srv.TLSConfig, err = a2aauth.ServerTLSConfig(*tlsCert, *tlsKey, *clientCA)
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+	}
+
+	log.Printf("Starting Prime Server on %s...", agentURL)
+	for _, a := range auths {
+		log.Printf("  auth: %s", a.SchemeName())
+	}
+
+	// 자기 카드를 레지스트리에 등록하고, 살아있다는 신호(하트비트)를 계속 보냅니다.
+	if *registryURL != "" {
+		rc := a2aregistry.NewClient(*registryURL)
+		rc.Token = *registryToken
+		go rc.KeepAlive(ctx, agentURL, card, a2aregistry.DefaultTTL)
+	}
 
 	// 6. 서버 실행
 	// 설정된 내용으로 웹 서버를 시작하고 요청을 대기합니다.
-	webLauncher.Run(ctx, config)
+	if *tlsCert != "" {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	log.Fatalf("Server failed: %v", err)
 }
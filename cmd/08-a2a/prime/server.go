@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	a2acore "github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adka2a"
+
+	"awesomeProject2/internal/a2aauth"
+)
+
+// A2A 호출 경로 (a2a 런처와 같은 값)
+const a2aInvokePath = "/a2a/invoke"
+
+// newAgentCard는 a2a 런처가 만드는 것과 같은 Agent Card를 만들고, 인증 방식을 security 항목에 광고합니다.
+func newAgentCard(config *launcher.Config, agentURL string, auths []a2aauth.Authenticator) (*a2acore.AgentCard, error) {
+	invokeURL, err := url.JoinPath(agentURL, a2aInvokePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent url %q: %w", agentURL, err)
+	}
+
+	rootAgent := config.AgentLoader.RootAgent()
+	card := &a2acore.AgentCard{
+		Name:               rootAgent.Name(),
+		Description:        rootAgent.Description(),
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+		URL:                invokeURL,
+		PreferredTransport: a2acore.TransportProtocolJSONRPC,
+		Skills:             adka2a.BuildAgentSkills(rootAgent),
+		Capabilities:       a2acore.AgentCapabilities{Streaming: true},
+	}
+	a2aauth.Advertise(card, auths)
+	return card, nil
+}
+
+// newA2AHandler는 a2a 런처 대신 카드와 JSON-RPC 핸들러를 직접 구성합니다.
+// 카드에 인증 방식을 광고하고, 호출 경로 앞에 인증과 요청 제한 미들웨어를 끼워 넣기 위해서입니다.
+// 미들웨어는 인증 → 요청 제한 순서로 실행되어, 요청 제한은 인증된 호출자 단위로 적용됩니다.
+func newA2AHandler(config *launcher.Config, card *a2acore.AgentCard, auths []a2aauth.Authenticator, lim *limiter) http.Handler {
+	router := web.BuildBaseRouter()
+	router.Use(a2aauth.Middleware(auths))
+	router.Use(lim.middleware)
+
+	router.Handle("/metrics", lim.metricsHandler())
+
+	router.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(card))
+
+	rootAgent := config.AgentLoader.RootAgent()
+	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
+		RunnerConfig: runner.Config{
+			AppName:         rootAgent.Name(),
+			Agent:           rootAgent,
+			SessionService:  config.SessionService,
+			ArtifactService: config.ArtifactService,
+		},
+	})
+	router.Handle(a2aInvokePath, a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(executor, config.A2AOptions...)))
+	return router
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"awesomeProject2/internal/a2aauth"
+	"awesomeProject2/internal/a2aregistry"
+)
+
+// 레지스트리 서버
+// 원격 에이전트가 여러 개가 되면 주소를 일일이 적어줄 수 없으므로,
+// 서버(prime 등)는 여기에 자기 카드를 등록하고 consumer는 스킬/태그로 찾아서 씁니다.
+
+func main() {
+	ctx := context.Background()
+
+	port := flag.Int("port", 8000, "Port for the registry server")
+	file := flag.String("file", "", "JSON file to persist registrations (empty = in-memory only)")
+	sweep := flag.Duration("sweep", 5*time.Second, "How often dead agents are expired")
+	// 등록/하트비트/해제에는 토큰이 필요합니다. 토큰의 이름이 곧 등록한 주인이 되어, 다른 주인의 카드는 바꿀 수 없습니다.
+	tokens := flag.String("tokens", os.Getenv("A2A_REGISTRY_TOKENS"), "Registration tokens, e.g. prime:token1,weather:token2")
+	insecure := flag.Bool("insecure", false, "Accept registrations without a token (local demos only)")
+	flag.Parse()
+
+	var auths []a2aauth.Authenticator
+	switch {
+	case *tokens != "":
+		parsed, err := a2aauth.ParseBearerTokens(*tokens)
+		if err != nil {
+			log.Fatalf("Invalid --tokens: %v", err)
+		}
+		auths = append(auths, a2aauth.NewBearer(parsed))
+	case *insecure:
+		log.Printf("WARNING: --insecure: anyone can register, replace or remove agents")
+	default:
+		log.Fatalf("Registration tokens are required: set --tokens (or A2A_REGISTRY_TOKENS), or pass --insecure for a local demo")
+	}
+
+	// 1. 저장소 선택: 파일 경로가 있으면 재시작해도 목록이 유지됩니다.
+	var reg *a2aregistry.Registry
+	if *file != "" {
+		var err error
+		reg, err = a2aregistry.NewFileBacked(*file)
+		if err != nil {
+			log.Fatalf("Failed to open registry: %v", err)
+		}
+	} else {
+		reg = a2aregistry.NewInMemory()
+	}
+
+	// 2. 하트비트가 끊긴 에이전트를 주기적으로 정리
+	go a2aregistry.RunExpiry(ctx, reg, *sweep)
+
+	log.Printf("Starting A2A registry on port %d...", *port)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", *port), a2aregistry.NewHandler(reg, auths)); err != nil {
+		log.Fatalf("Registry server failed: %v", err)
+	}
+}
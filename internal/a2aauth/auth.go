@@ -0,0 +1,107 @@
+// Package a2aauth는 A2A 서버의 인증(inbound)과 클라이언트의 자격 증명 주입(outbound)을 제공합니다.
+//
+// 서버는 하나 이상의 Authenticator를 Middleware로 묶어 사용하고, 같은 목록을 Advertise로
+// Agent Card의 security 항목에 광고합니다. 여러 방식이 설정되면 그중 하나만 통과해도 됩니다(OR).
+// 클라이언트는 NewHTTPClient로 만든 http.Client를 remoteagent에 넘겨 자격 증명을 자동으로 붙입니다.
+package a2aauth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+// ErrNoCredentials는 요청에 해당 방식의 자격 증명이 아예 없을 때 반환됩니다.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator는 하나의 인증 방식입니다.
+type Authenticator interface {
+	// SchemeName은 Agent Card의 securitySchemes에 쓰일 이름입니다.
+	SchemeName() a2a.SecuritySchemeName
+	// Scheme은 Agent Card에 광고할 방식 설명입니다.
+	Scheme() a2a.SecurityScheme
+	// Authenticate는 요청을 검증하고 호출자 식별자를 반환합니다.
+	// 자격 증명이 없으면 ErrNoCredentials, 있지만 틀리면 그 밖의 에러를 반환합니다.
+	Authenticate(r *http.Request) (string, error)
+}
+
+type clientKey struct{}
+
+// ClientFrom은 Middleware가 인증한 호출자 식별자를 꺼냅니다.
+func ClientFrom(ctx context.Context) (string, bool) {
+	client, ok := ctx.Value(clientKey{}).(string)
+	return client, ok
+}
+
+// Middleware는 auths 중 하나라도 통과한 요청만 next로 넘깁니다.
+// Agent Card 경로는 클라이언트가 인증 방식을 알아내야 하므로 항상 공개합니다.
+func Middleware(auths []Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(auths) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == a2asrv.WellKnownAgentCardPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var failures []string
+			for _, a := range auths {
+				client, err := a.Authenticate(r)
+				if err == nil {
+					ctx := context.WithValue(r.Context(), clientKey{}, client)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+				if !errors.Is(err, ErrNoCredentials) {
+					failures = append(failures, string(a.SchemeName())+": "+err.Error())
+				}
+			}
+
+			if len(failures) > 0 {
+				log.Printf("[auth] rejected %s %s from %s: %s", r.Method, r.URL.Path, r.RemoteAddr, strings.Join(failures, "; "))
+			}
+			w.Header().Set("WWW-Authenticate", challenge(auths))
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		})
+	}
+}
+
+func challenge(auths []Authenticator) string {
+	var schemes []string
+	for _, a := range auths {
+		if h, ok := a.Scheme().(a2a.HTTPAuthSecurityScheme); ok {
+			schemes = append(schemes, h.Scheme)
+		}
+	}
+	if len(schemes) == 0 {
+		return "Bearer"
+	}
+	return strings.Join(schemes, ", ")
+}
+
+// Advertise는 auths를 카드의 securitySchemes와 security에 기록합니다.
+// security는 OR 목록이므로 방식마다 요구 사항 하나씩을 추가합니다.
+func Advertise(card *a2a.AgentCard, auths []Authenticator) {
+	if len(auths) == 0 {
+		return
+	}
+	if card.SecuritySchemes == nil {
+		card.SecuritySchemes = a2a.NamedSecuritySchemes{}
+	}
+	for _, a := range auths {
+		name := a.SchemeName()
+		card.SecuritySchemes[name] = a.Scheme()
+		req := a2a.SecurityRequirements{name: a2a.SecuritySchemeScopes{}}
+		if !slices.ContainsFunc(card.Security, func(s a2a.SecurityRequirements) bool { _, ok := s[name]; return ok }) {
+			card.Security = append(card.Security, req)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package a2aauth
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+func TestParseBearerTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "이름과 토큰", spec: "alice:token1, bob:token2", want: map[string]string{"token1": "alice", "token2": "bob"}},
+		{name: "토큰만 적으면 순번 이름", spec: "token1,,token3", want: map[string]string{"token1": "client-1", "token3": "client-3"}},
+		{name: "빈 토큰", spec: "alice:", wantErr: true},
+		{name: "토큰 없음", spec: " , ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBearerTokens(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("tokens = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBearerAuthenticate(t *testing.T) {
+	b := NewBearer(map[string]string{"token1": "alice"})
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+		wantNoCreds   bool
+		wantErr       bool
+	}{
+		{name: "맞는 토큰", authorization: "Bearer token1", want: "alice"},
+		{name: "방식 이름은 대소문자 무시", authorization: "bearer token1", want: "alice"},
+		{name: "모르는 토큰", authorization: "Bearer nope", wantErr: true},
+		{name: "헤더 없음", wantNoCreds: true},
+		{name: "다른 방식", authorization: "Basic YWxpY2U6cHc=", wantNoCreds: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/invoke", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			got, err := b.Authenticate(r)
+			switch {
+			case tt.wantNoCreds:
+				if err != ErrNoCredentials {
+					t.Errorf("err = %v, want ErrNoCredentials", err)
+				}
+			case tt.wantErr:
+				if err == nil || err == ErrNoCredentials {
+					t.Errorf("err = %v, want a rejection", err)
+				}
+			case err != nil || got != tt.want:
+				t.Errorf("Authenticate = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// echoClient는 Middleware가 넘겨준 호출자 이름을 그대로 응답합니다.
+var echoClient = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	client, ok := ClientFrom(r.Context())
+	fmt.Fprintf(w, "%s %v", client, ok)
+})
+
+func serve(t *testing.T, h http.Handler, path, authorization string) (*http.Response, string) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, path, nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	body, _ := io.ReadAll(w.Result().Body)
+	return w.Result(), string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	auths := []Authenticator{
+		NewBearer(map[string]string{"token1": "alice"}),
+		NewHMAC(map[string][]byte{"bob": []byte("secret")}),
+	}
+	h := Middleware(auths)(echoClient)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{name: "맞는 토큰", path: "/invoke", authorization: "Bearer token1", wantStatus: http.StatusOK, wantBody: "alice true"},
+		{name: "자격 증명 없음", path: "/invoke", wantStatus: http.StatusUnauthorized},
+		{name: "틀린 토큰", path: "/invoke", authorization: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "모르는 방식", path: "/invoke", authorization: "Basic YWxpY2U6cHc=", wantStatus: http.StatusUnauthorized},
+		{name: "Agent Card는 공개", path: a2asrv.WellKnownAgentCardPath, wantStatus: http.StatusOK, wantBody: " false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := serve(t, h, tt.path, tt.authorization)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized {
+				// 401에는 받을 수 있는 방식을 알려주는 헤더가 붙고, next는 호출되지 않습니다.
+				if got := resp.Header.Get("WWW-Authenticate"); got != "Bearer, HMAC-SHA256" {
+					t.Errorf("WWW-Authenticate = %q", got)
+				}
+				if body == "alice true" || body == " false" {
+					t.Errorf("next handler ran: %q", body)
+				}
+				return
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+
+	// 인증 방식이 없으면 모든 요청을 그대로 넘깁니다.
+	if resp, body := serve(t, Middleware(nil)(echoClient), "/invoke", ""); resp.StatusCode != http.StatusOK || body != " false" {
+		t.Errorf("no authenticators: status %d, body %q", resp.StatusCode, body)
+	}
+}
+
+func TestAdvertise(t *testing.T) {
+	card := &a2a.AgentCard{}
+	auths := []Authenticator{NewBearer(nil), MutualTLS{}}
+	Advertise(card, auths)
+	Advertise(card, auths)
+
+	if got := slices.Sorted(maps.Keys(card.SecuritySchemes)); !slices.Equal(got, []a2a.SecuritySchemeName{"bearer", "mtls"}) {
+		t.Errorf("securitySchemes = %v", got)
+	}
+	// 같은 방식을 두 번 광고해도 요구 사항은 방식마다 하나입니다.
+	if len(card.Security) != 2 {
+		t.Errorf("security = %v, want one requirement per scheme", card.Security)
+	}
+	if !(ClientCredentials{BearerToken: "token1"}).Satisfies(card) {
+		t.Error("bearer credentials do not satisfy the advertised card")
+	}
+}
@@ -0,0 +1,71 @@
+package a2aauth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// Bearer는 미리 정해둔 정적 토큰(Authorization: Bearer <token>)으로 인증합니다.
+type Bearer struct {
+	// tokens는 토큰 → 호출자 이름입니다.
+	tokens map[string]string
+}
+
+// NewBearer는 토큰 → 호출자 이름 맵으로 Bearer 인증을 만듭니다.
+func NewBearer(tokens map[string]string) *Bearer {
+	return &Bearer{tokens: tokens}
+}
+
+// ParseBearerTokens는 "alice:token1,bob:token2" 형식을 토큰 맵으로 바꿉니다.
+// 이름 없이 토큰만 적으면 "client-1"처럼 순번으로 이름을 붙입니다.
+func ParseBearerTokens(spec string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for i, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(item, ":")
+		if !ok {
+			name, token = fmt.Sprintf("client-%d", i+1), item
+		}
+		if token == "" {
+			return nil, fmt.Errorf("empty bearer token for %q", name)
+		}
+		tokens[token] = name
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("no bearer tokens given")
+	}
+	return tokens, nil
+}
+
+func (b *Bearer) SchemeName() a2a.SecuritySchemeName { return "bearer" }
+
+func (b *Bearer) Scheme() a2a.SecurityScheme {
+	return a2a.HTTPAuthSecurityScheme{Scheme: "Bearer", Description: "Static bearer token issued by the server operator."}
+}
+
+func (b *Bearer) Authenticate(r *http.Request) (string, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", ErrNoCredentials
+	}
+	token = strings.TrimSpace(token)
+	// 타이밍 공격을 피하기 위해 모든 토큰과 상수 시간 비교를 합니다.
+	var client string
+	for known, name := range b.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			client = name
+		}
+	}
+	if client == "" {
+		return "", errors.New("unknown bearer token")
+	}
+	return client, nil
+}
@@ -0,0 +1,130 @@
+package a2aauth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// ClientCredentials는 클라이언트가 요청에 붙일 자격 증명입니다. 비어 있는 항목은 사용하지 않습니다.
+type ClientCredentials struct {
+	BearerToken string
+	HMACKeyID   string
+	HMACSecret  []byte
+
+	// TLS 설정 (https 서버 검증 및 mTLS 클라이언트 인증서)
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// NewHTTPClient는 자격 증명을 자동으로 붙이는 http.Client를 만듭니다.
+// A2A 스트리밍 응답이 길어질 수 있으므로 전체 Timeout은 두지 않습니다. 호출 쪽에서 ctx로 제한하세요.
+func NewHTTPClient(creds ClientCredentials) (*http.Client, error) {
+	tlsCfg, err := ClientTLSConfig(creds.CAFile, creds.CertFile, creds.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = tlsCfg
+
+	var rt http.RoundTripper = base
+	switch {
+	case creds.HMACKeyID != "":
+		rt = &hmacTransport{next: rt, keyID: creds.HMACKeyID, secret: creds.HMACSecret, now: time.Now}
+	case creds.BearerToken != "":
+		rt = &bearerTransport{next: rt, token: creds.BearerToken}
+	}
+	return &http.Client{Transport: rt}, nil
+}
+
+type bearerTransport struct {
+	next  http.RoundTripper
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.next.RoundTrip(req)
+}
+
+type hmacTransport struct {
+	next   http.RoundTripper
+	keyID  string
+	secret []byte
+	now    func() time.Time
+}
+
+func (t *hmacTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body for signing: %w", err)
+		}
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+
+	ts := strconv.FormatInt(t.now().Unix(), 10)
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// 서버는 r.Host로 검증하므로, Host를 따로 정하지 않았다면 URL의 호스트가 그대로 Host 헤더가 됩니다.
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	sig := signRequest(t.secret, req.Method, host, req.URL.RequestURI(), ts, hex.EncodeToString(nonce), body)
+	req.Header.Set("Authorization", fmt.Sprintf("%s keyId=%s,ts=%s,nonce=%x,sig=%s", hmacScheme, t.keyID, ts, nonce, hex.EncodeToString(sig)))
+	return t.next.RoundTrip(req)
+}
+
+// Satisfies는 이 자격 증명으로 카드의 security 요구 사항 중 하나를 만족할 수 있는지 확인합니다.
+// 카드가 아무 요구 사항도 광고하지 않으면 true입니다.
+func (c ClientCredentials) Satisfies(card *a2a.AgentCard) bool {
+	if len(card.Security) == 0 {
+		return true
+	}
+	for _, req := range card.Security {
+		ok := true
+		for name := range req {
+			if !c.supports(card.SecuritySchemes[name]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ClientCredentials) supports(scheme a2a.SecurityScheme) bool {
+	switch s := scheme.(type) {
+	case a2a.HTTPAuthSecurityScheme:
+		switch {
+		case strings.EqualFold(s.Scheme, "Bearer"):
+			return c.BearerToken != "" && c.HMACKeyID == ""
+		case strings.EqualFold(s.Scheme, hmacScheme):
+			return c.HMACKeyID != ""
+		}
+	case a2a.MutualTLSSecurityScheme:
+		return c.CertFile != ""
+	}
+	return false
+}
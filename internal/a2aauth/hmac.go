@@ -0,0 +1,175 @@
+package a2aauth
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// hmacScheme은 Authorization 헤더에 쓰이는 방식 이름입니다.
+//
+//	Authorization: HMAC-SHA256 keyId=<id>,ts=<unix seconds>,nonce=<random hex>,sig=<hex>
+//
+// 서명 대상은 "METHOD\nHOST\nREQUEST_URI\nTS\nNONCE\nhex(sha256(body))" 입니다.
+const hmacScheme = "HMAC-SHA256"
+
+// 서명 검증을 위해 읽어들이는 요청 본문의 최대 크기
+const maxSignedBody = 10 << 20
+
+// nonce의 최대 길이. 이보다 긴 값은 받지 않아 기억해 둘 메모리를 제한합니다.
+const maxNonceLen = 64
+
+// HMAC은 공유 비밀키로 서명된 요청을 검증합니다.
+// 타임스탬프로 서명이 유효한 시간을 제한하고, 그 시간 안에 같은 nonce가 다시 오면 재전송(replay)으로 보고 거부합니다.
+// 호스트도 서명하므로 다른 서버로 옮겨 보낸 요청도 통과하지 못합니다.
+type HMAC struct {
+	// keys는 키 ID → 비밀키입니다. 키 ID가 곧 호출자 이름이 됩니다.
+	keys    map[string][]byte
+	maxSkew time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// seen은 이미 쓴 "키 ID/nonce" → 그 서명이 만료되는 시각입니다.
+	seen map[string]time.Time
+	// expiry는 seen의 항목을 만료 시각 순으로 담은 힙입니다. 만료된 항목을 앞에서부터 지워, 요청마다 seen 전체를 훑지 않습니다.
+	expiry nonceHeap
+}
+
+// NewHMAC은 키 ID → 비밀키 맵으로 HMAC 인증을 만듭니다.
+func NewHMAC(keys map[string][]byte) *HMAC {
+	return &HMAC{keys: keys, maxSkew: 5 * time.Minute, now: time.Now, seen: make(map[string]time.Time)}
+}
+
+// ParseHMACKeys는 "alice:secret1,bob:secret2" 형식을 키 맵으로 바꿉니다.
+func ParseHMACKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(item, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("malformed HMAC key %q, want <keyId>:<secret>", item)
+		}
+		keys[id] = []byte(secret)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no HMAC keys given")
+	}
+	return keys, nil
+}
+
+func (h *HMAC) SchemeName() a2a.SecuritySchemeName { return "hmac" }
+
+func (h *HMAC) Scheme() a2a.SecurityScheme {
+	return a2a.HTTPAuthSecurityScheme{
+		Scheme:      hmacScheme,
+		Description: `Authorization: HMAC-SHA256 keyId=<id>,ts=<unix>,nonce=<unique per request>,sig=hex(HMAC(secret, "METHOD\nHOST\nREQUEST_URI\nTS\nNONCE\nhex(sha256(body))"))`,
+	}
+}
+
+func (h *HMAC) Authenticate(r *http.Request) (string, error) {
+	scheme, params, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, hmacScheme) {
+		return "", ErrNoCredentials
+	}
+
+	fields := make(map[string]string)
+	for _, kv := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(kv), "=")
+		fields[k] = v
+	}
+	keyID, ts, nonce, sig := fields["keyId"], fields["ts"], fields["nonce"], fields["sig"]
+	secret, ok := h.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown key %q", keyID)
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("malformed timestamp %q", ts)
+	}
+	signedAt := time.Unix(unix, 0)
+	if skew := h.now().Sub(signedAt).Abs(); skew > h.maxSkew {
+		return "", fmt.Errorf("timestamp is %s off", skew.Round(time.Second))
+	}
+	if nonce == "" || len(nonce) > maxNonceLen {
+		return "", errors.New("missing or malformed nonce")
+	}
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return "", errors.New("malformed signature")
+	}
+
+	// 서명 검증을 위해 본문을 읽은 뒤, 다음 핸들러가 다시 읽을 수 있도록 되돌려 놓습니다.
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to read body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if !hmac.Equal(want, signRequest(secret, r.Method, r.Host, r.URL.RequestURI(), ts, nonce, body)) {
+		return "", errors.New("signature mismatch")
+	}
+	// 서명이 맞는 요청의 nonce만 기억합니다. 그렇지 않으면 아무나 nonce를 미리 써 버릴 수 있습니다.
+	if !h.remember(keyID+"/"+nonce, signedAt.Add(h.maxSkew)) {
+		return "", errors.New("nonce was already used (replayed request)")
+	}
+	return keyID, nil
+}
+
+// remember는 key를 expires까지 기억합니다. 이미 기억하고 있던 key면 false를 반환합니다.
+// 타임스탬프 검사로 expires가 지난 서명은 어차피 거부되므로, 그런 항목은 이때 함께 지웁니다.
+func (h *HMAC) remember(key string, expires time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	for len(h.expiry) > 0 && now.After(h.expiry[0].expires) {
+		delete(h.seen, heap.Pop(&h.expiry).(seenNonce).key)
+	}
+	if _, ok := h.seen[key]; ok {
+		return false
+	}
+	h.seen[key] = expires
+	heap.Push(&h.expiry, seenNonce{key: key, expires: expires})
+	return true
+}
+
+type seenNonce struct {
+	key     string
+	expires time.Time
+}
+
+// nonceHeap은 만료 시각이 가장 이른 항목이 맨 앞에 오는 힙입니다.
+// 서명 시각이 조금씩 앞뒤로 어긋나 들어오므로 단순히 뒤에 붙이는 대신 힙으로 순서를 유지합니다.
+type nonceHeap []seenNonce
+
+func (h nonceHeap) Len() int           { return len(h) }
+func (h nonceHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h nonceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *nonceHeap) Push(v any)        { *h = append(*h, v.(seenNonce)) }
+func (h *nonceHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+func signRequest(secret []byte, method, host, requestURI, ts, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s\n%s", method, strings.ToLower(host), requestURI, ts, nonce, hex.EncodeToString(bodyHash[:]))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,110 @@
+package a2aauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedRequest는 hmacTransport로 서명한 요청을 만들어, 서버가 받는 모양(*http.Request)으로 돌려줍니다.
+func signedRequest(t *testing.T, now time.Time, url, body string) *http.Request {
+	t.Helper()
+	var captured *http.Request
+	rt := &hmacTransport{
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			captured = req
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		keyID:  "alice",
+		secret: []byte("secret"),
+		now:    func() time.Time { return now },
+	}
+	req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.RequestURI = ""
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	// 서버 쪽 요청처럼 Host를 채웁니다.
+	server := httptest.NewRequest(captured.Method, url, captured.Body)
+	server.Header = captured.Header
+	return server
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestHMACRejectsReplayAndOtherHost(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	h := NewHMAC(map[string][]byte{"alice": []byte("secret")})
+	h.now = func() time.Time { return now }
+
+	req := signedRequest(t, now, "http://prime.local:8001/invoke", `{"n":7}`)
+	if client, err := h.Authenticate(req); err != nil || client != "alice" {
+		t.Fatalf("Authenticate = %q, %v; want alice", client, err)
+	}
+
+	// 같은 요청을 다시 보내면 (본문까지 같게) nonce 때문에 거부됩니다.
+	again := signedRequest(t, now, "http://prime.local:8001/invoke", `{"n":7}`)
+	again.Header = req.Header
+	if _, err := h.Authenticate(again); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("replayed request: err = %v, want nonce error", err)
+	}
+
+	// 다른 호스트로 보낸 요청은 서명이 맞지 않습니다.
+	moved := signedRequest(t, now, "http://prime.local:8001/invoke", `{"n":7}`)
+	moved.Host = "other.local:8001"
+	if _, err := h.Authenticate(moved); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("request to another host: err = %v, want signature mismatch", err)
+	}
+
+	// 유효 시간이 지나면 기억해 둔 nonce는 지워지지만, 그 서명은 타임스탬프 검사에서 거부됩니다.
+	now = now.Add(h.maxSkew + time.Second)
+	if _, err := h.Authenticate(signedRequest(t, now, "http://prime.local:8001/invoke", "{}")); err != nil {
+		t.Fatalf("fresh request: %v", err)
+	}
+	if len(h.seen) != 1 {
+		t.Errorf("seen has %d nonces, want only the fresh one", len(h.seen))
+	}
+	old := signedRequest(t, now.Add(-h.maxSkew-time.Second), "http://prime.local:8001/invoke", "{}")
+	if _, err := h.Authenticate(old); err == nil || !strings.Contains(err.Error(), "timestamp") {
+		t.Errorf("expired request: err = %v, want timestamp error", err)
+	}
+}
+
+func TestHMACForgetsNoncesInExpiryOrder(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	h := NewHMAC(map[string][]byte{"alice": []byte("secret")})
+	h.now = func() time.Time { return now }
+
+	// 서명 시각이 앞뒤로 어긋나 들어와도 만료 시각이 지난 것만 지웁니다.
+	for _, tc := range []struct {
+		key     string
+		expires time.Duration
+	}{{"b", 20 * time.Second}, {"a", 10 * time.Second}, {"c", 30 * time.Second}} {
+		if !h.remember(tc.key, now.Add(tc.expires)) {
+			t.Fatalf("remember(%q) = false on first use", tc.key)
+		}
+	}
+	if h.remember("a", now.Add(time.Minute)) {
+		t.Error("remember accepted a nonce that is still remembered")
+	}
+
+	now = now.Add(15 * time.Second)
+	if !h.remember("d", now.Add(time.Minute)) {
+		t.Fatal("remember(d) = false")
+	}
+	if _, ok := h.seen["a"]; ok {
+		t.Error("expired nonce a is still remembered")
+	}
+	for _, key := range []string{"b", "c", "d"} {
+		if _, ok := h.seen[key]; !ok {
+			t.Errorf("nonce %s was forgotten before it expired", key)
+		}
+	}
+	if len(h.expiry) != len(h.seen) {
+		t.Errorf("expiry has %d entries, seen has %d", len(h.expiry), len(h.seen))
+	}
+}
@@ -0,0 +1,87 @@
+package a2aauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// MutualTLS는 서버가 신뢰하는 CA로 서명된 클라이언트 인증서를 요구합니다.
+// 호출자 이름은 인증서의 Common Name입니다.
+type MutualTLS struct{}
+
+func (MutualTLS) SchemeName() a2a.SecuritySchemeName { return "mtls" }
+
+func (MutualTLS) Scheme() a2a.SecurityScheme {
+	return a2a.MutualTLSSecurityScheme{Description: "Client certificate signed by the server's client CA."}
+}
+
+func (MutualTLS) Authenticate(r *http.Request) (string, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return "", ErrNoCredentials
+	}
+	// ServerTLSConfig는 VerifyClientCertIfGiven을 쓰므로, 인증서가 있다면 이미 검증된 상태입니다.
+	if len(r.TLS.VerifiedChains) == 0 {
+		return "", errors.New("client certificate is not verified")
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName, nil
+}
+
+// ServerTLSConfig는 서버 인증서와, 클라이언트 인증서를 검증할 CA로 TLS 설정을 만듭니다.
+// clientCAFile이 비어 있으면 클라이언트 인증서를 요구하지 않습니다.
+// Agent Card는 인증 없이 받을 수 있어야 하므로 인증서는 "있으면 검증"만 하고,
+// 실제 요구 여부는 MutualTLS 인증 단계에서 판단합니다.
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return cfg, nil
+}
+
+// ClientTLSConfig는 서버를 검증할 CA와 (mTLS용) 클라이언트 인증서로 TLS 설정을 만듭니다.
+// 모든 인자는 선택 사항입니다.
+func ClientTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %q", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,178 @@
+package a2aauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert는 PEM 파일로 저장한 인증서와 개인 키입니다.
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// issue는 parent로 서명한(parent가 nil이면 자체 서명한) 인증서를 만들어 dir에 저장합니다.
+func issue(t *testing.T, dir, name string, tmpl *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testCert{cert: cert, key: key, certFile: filepath.Join(dir, name+".pem"), keyFile: filepath.Join(dir, name+"-key.pem")}
+	if err := os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func newCA(t *testing.T, dir, name string) *testCert {
+	return issue(t, dir, name, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newLeaf(t *testing.T, dir, name string, ca *testCert, usage x509.ExtKeyUsage) *testCert {
+	return issue(t, dir, name, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{usage},
+	}, ca)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t, dir, "test-ca")
+	server := newLeaf(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+	client := newLeaf(t, dir, "consumer", ca, x509.ExtKeyUsageClientAuth)
+	// 다른 CA가 서명한 클라이언트 인증서는 서버가 받는 CA 목록에 없으므로 보내지지 않고, 자격 증명 없음으로 거부됩니다.
+	stranger := newLeaf(t, dir, "stranger", newCA(t, dir, "other-ca"), x509.ExtKeyUsageClientAuth)
+
+	tlsCfg, err := ServerTLSConfig(server.certFile, server.keyFile, ca.certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(Middleware([]Authenticator{MutualTLS{}})(echoClient))
+	srv.TLS = tlsCfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		creds      ClientCredentials
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "CA가 서명한 인증서", creds: ClientCredentials{CAFile: ca.certFile, CertFile: client.certFile, KeyFile: client.keyFile}, wantStatus: http.StatusOK, wantBody: "consumer true"},
+		{name: "인증서 없음", creds: ClientCredentials{CAFile: ca.certFile}, wantStatus: http.StatusUnauthorized},
+		{name: "모르는 CA의 인증서", creds: ClientCredentials{CAFile: ca.certFile, CertFile: stranger.certFile, KeyFile: stranger.keyFile}, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc, err := NewHTTPClient(tt.creds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := hc.Post(srv.URL+"/invoke", "application/json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+
+	// 서버를 검증할 CA가 없으면 클라이언트가 연결을 거부합니다.
+	hc, err := NewHTTPClient(ClientCredentials{CertFile: client.certFile, KeyFile: client.keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := hc.Get(srv.URL + "/invoke"); err == nil {
+		resp.Body.Close()
+		t.Error("client accepted a server certificate from an unknown CA")
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.pem")
+	notPEM := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ServerTLSConfig(missing, missing, ""); err == nil {
+		t.Error("ServerTLSConfig accepted a missing certificate")
+	}
+	if _, err := ClientTLSConfig(notPEM, "", ""); err == nil {
+		t.Error("ClientTLSConfig accepted a CA file without certificates")
+	}
+	if _, err := ClientTLSConfig("", missing, ""); err == nil {
+		t.Error("ClientTLSConfig accepted a certificate without a key")
+	}
+}
+
+func TestMutualTLSAuthenticate(t *testing.T) {
+	dir := t.TempDir()
+	client := newLeaf(t, dir, "consumer", newCA(t, dir, "test-ca"), x509.ExtKeyUsageClientAuth)
+
+	r := httptest.NewRequest(http.MethodPost, "/invoke", nil)
+	if _, err := (MutualTLS{}).Authenticate(r); err != ErrNoCredentials {
+		t.Errorf("plain http: err = %v, want ErrNoCredentials", err)
+	}
+	// 인증서는 있지만 검증된 체인이 없으면 자격 증명이 틀린 것으로 봅니다.
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{client.cert}}
+	if _, err := (MutualTLS{}).Authenticate(r); err == nil || err == ErrNoCredentials {
+		t.Errorf("unverified certificate: err = %v, want a rejection", err)
+	}
+	r.TLS.VerifiedChains = [][]*x509.Certificate{{client.cert}}
+	if got, err := (MutualTLS{}).Authenticate(r); err != nil || got != "consumer" {
+		t.Errorf("verified certificate: Authenticate = %q, %v; want consumer", got, err)
+	}
+}
@@ -0,0 +1,170 @@
+package a2aregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// Client는 레지스트리 HTTP API를 호출합니다.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token은 등록, 하트비트, 해제 요청에 붙일 Bearer 토큰입니다. 검색(Find)에는 쓰지 않습니다.
+	Token string
+}
+
+// NewClient는 baseURL(예: http://localhost:8000)의 레지스트리에 접속하는 클라이언트를 만듭니다.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Register는 카드를 ttl 동안 유효하도록 등록합니다.
+func (c *Client) Register(ctx context.Context, cardURL string, card *a2a.AgentCard, ttl time.Duration) error {
+	body := registerRequest{CardURL: cardURL, Card: card, TTLSeconds: int(ttl / time.Second)}
+	return c.do(ctx, http.MethodPost, "/agents", body, nil)
+}
+
+// Heartbeat는 등록을 연장합니다. 레지스트리가 이미 만료시켰다면 ErrNotFound를 반환합니다.
+func (c *Client) Heartbeat(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPut, "/agents/"+url.PathEscape(name)+"/heartbeat", nil, nil)
+}
+
+// Deregister는 등록을 해제합니다.
+func (c *Client) Deregister(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/agents/"+url.PathEscape(name), nil, nil)
+}
+
+// Find는 조건에 맞는 살아있는 에이전트 목록을 가져옵니다.
+func (c *Client) Find(ctx context.Context, q Query) ([]Entry, error) {
+	params := url.Values{}
+	if q.Skill != "" {
+		params.Set("skill", q.Skill)
+	}
+	if q.Tag != "" {
+		params.Set("tag", q.Tag)
+	}
+	path := "/agents"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	var resp []entryResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(resp))
+	for _, r := range resp {
+		entries = append(entries, Entry{
+			Name:     r.Name,
+			CardURL:  r.CardURL,
+			Card:     r.Card,
+			TTL:      r.ExpiresAt.Sub(r.LastSeen),
+			LastSeen: r.LastSeen,
+		})
+	}
+	return entries, nil
+}
+
+// KeepAlive는 card를 등록하고, ctx가 끝날 때까지 ttl/3 마다 하트비트를 보냅니다.
+// 레지스트리가 아직 뜨지 않았거나 재시작되어 등록이 사라진 경우에도 스스로 다시 등록합니다.
+// ctx가 끝나면 등록을 해제하고 반환합니다.
+func (c *Client) KeepAlive(ctx context.Context, cardURL string, card *a2a.AgentCard, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	interval := ttl / 3
+	name := card.Name
+
+	register := func() bool {
+		if err := c.Register(ctx, cardURL, card, ttl); err != nil {
+			log.Printf("[registry] register failed: %v", err)
+			return false
+		}
+		log.Printf("[registry] registered %q at %s", name, c.BaseURL)
+		return true
+	}
+
+	registered := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if !registered {
+			registered = register()
+		} else if err := c.Heartbeat(ctx, name); err != nil {
+			log.Printf("[registry] heartbeat failed: %v", err)
+			registered = errors.Is(err, ErrNotFound) && register()
+		}
+
+		select {
+		case <-ctx.Done():
+			if registered {
+				// ctx는 이미 끝났으므로 해제 요청은 별도 타임아웃으로 보냅니다.
+				dctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				if err := c.Deregister(dctx, name); err != nil {
+					log.Printf("[registry] deregister failed: %v", err)
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" && method != http.MethodGet {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusForbidden:
+		return ErrForbidden
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("registry %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("registry %s %s: malformed response: %w", method, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Package a2aregistry는 A2A 에이전트들이 자신의 Agent Card를 등록하고,
+// 소비자(consumer)가 스킬/태그로 원격 에이전트를 찾을 수 있게 해주는 작은 레지스트리입니다.
+//
+// 서버는 시작할 때 카드를 등록하고 주기적으로 하트비트를 보냅니다.
+// TTL 안에 하트비트가 없는 에이전트는 죽은 것으로 보고 목록에서 제거됩니다.
+package a2aregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// DefaultTTL은 등록 요청에 TTL이 없을 때 사용하는 기본 만료 시간입니다.
+const DefaultTTL = 30 * time.Second
+
+var (
+	// ErrNotFound는 등록되지 않았거나 이미 만료된 에이전트를 가리킬 때 반환됩니다.
+	ErrNotFound = errors.New("agent is not registered")
+	// ErrForbidden은 다른 호출자가 등록한 에이전트를 교체하거나 연장하거나 해제하려 할 때 반환됩니다.
+	ErrForbidden = errors.New("agent is registered by another client")
+)
+
+// Entry는 레지스트리에 등록된 에이전트 한 건입니다.
+type Entry struct {
+	// Name은 카드의 이름이며 레지스트리 안에서 키로 쓰입니다.
+	Name string `json:"name"`
+	// CardURL은 카드를 가져올 수 있는 에이전트의 기본 주소입니다.
+	CardURL  string         `json:"cardUrl"`
+	Card     *a2a.AgentCard `json:"card"`
+	TTL      time.Duration  `json:"ttl"`
+	LastSeen time.Time      `json:"lastSeen"`
+	// Owner는 등록한 호출자(인증된 클라이언트 이름)입니다. 같은 호출자만 이 항목을 바꿀 수 있습니다.
+	// 인증 없이 운영하는 레지스트리에서는 비어 있습니다.
+	Owner string `json:"owner,omitempty"`
+}
+
+// ExpiresAt은 다음 하트비트가 없을 때 에이전트가 만료되는 시각입니다.
+func (e Entry) ExpiresAt() time.Time {
+	return e.LastSeen.Add(e.TTL)
+}
+
+// Query는 에이전트 검색 조건입니다. 비어 있는 필드는 조건으로 쓰지 않습니다.
+type Query struct {
+	// Skill은 스킬의 ID 또는 이름과 대소문자 구분 없이 비교합니다.
+	Skill string
+	// Tag는 스킬에 붙은 태그 중 하나와 대소문자 구분 없이 비교합니다.
+	Tag string
+}
+
+func (q Query) matches(card *a2a.AgentCard) bool {
+	if q.Skill == "" && q.Tag == "" {
+		return true
+	}
+	for _, s := range card.Skills {
+		if q.Skill != "" && !strings.EqualFold(s.ID, q.Skill) && !strings.EqualFold(s.Name, q.Skill) {
+			continue
+		}
+		if q.Tag != "" && !slices.ContainsFunc(s.Tags, func(t string) bool { return strings.EqualFold(t, q.Tag) }) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Registry는 에이전트 목록을 메모리에 보관하고, path가 있으면 변경될 때마다 파일에도 기록합니다.
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	path    string
+	now     func() time.Time
+}
+
+// Option은 Registry 생성 옵션입니다.
+type Option func(*Registry)
+
+// WithClock은 만료 계산에 쓰는 시계를 바꿉니다.
+func WithClock(now func() time.Time) Option {
+	return func(r *Registry) { r.now = now }
+}
+
+// NewInMemory는 파일에 저장하지 않는 레지스트리를 만듭니다.
+func NewInMemory(opts ...Option) *Registry {
+	r := &Registry{entries: make(map[string]Entry), now: time.Now}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// NewFileBacked는 path의 JSON 파일에서 목록을 읽어오고, 이후 변경 사항을 같은 파일에 기록하는 레지스트리를 만듭니다.
+// 파일이 없으면 빈 레지스트리로 시작합니다.
+func NewFileBacked(path string, opts ...Option) (*Registry, error) {
+	r := NewInMemory(opts...)
+	r.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry file %q: %w", path, err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("malformed registry file %q: %w", path, err)
+	}
+	for _, e := range entries {
+		r.entries[e.Name] = e
+	}
+	r.expireLocked()
+	return r, nil
+}
+
+// Register는 owner의 이름으로 카드를 등록하거나, 같은 이름이 이미 있으면 교체합니다.
+// 같은 이름이 다른 owner로 살아 있으면 ErrForbidden을 반환합니다.
+func (r *Registry) Register(owner, cardURL string, card *a2a.AgentCard, ttl time.Duration) (Entry, error) {
+	if card == nil || strings.TrimSpace(card.Name) == "" {
+		return Entry{}, fmt.Errorf("agent card must have a name")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expireLocked()
+	if old, ok := r.entries[card.Name]; ok && old.Owner != owner {
+		return Entry{}, ErrForbidden
+	}
+	e := Entry{Name: card.Name, CardURL: cardURL, Card: card, TTL: ttl, LastSeen: r.now(), Owner: owner}
+	r.entries[e.Name] = e
+	return e, r.saveLocked()
+}
+
+// Heartbeat는 에이전트의 마지막 응답 시각을 갱신합니다.
+// 이미 만료된 에이전트라면 ErrNotFound를 반환하므로, 호출한 쪽은 다시 등록해야 합니다.
+// owner가 등록한 호출자가 아니면 ErrForbidden을 반환합니다.
+func (r *Registry) Heartbeat(owner, name string) (Entry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expireLocked()
+	e, ok := r.entries[name]
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+	if e.Owner != owner {
+		return Entry{}, ErrForbidden
+	}
+	e.LastSeen = r.now()
+	r.entries[name] = e
+	return e, r.saveLocked()
+}
+
+// Deregister는 에이전트를 목록에서 제거합니다. owner가 등록한 호출자가 아니면 ErrForbidden을 반환합니다.
+func (r *Registry) Deregister(owner, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[name]
+	if !ok {
+		return ErrNotFound
+	}
+	if e.Owner != owner {
+		return ErrForbidden
+	}
+	delete(r.entries, name)
+	return r.saveLocked()
+}
+
+// List는 조건에 맞는 살아있는 에이전트를 이름순으로 반환합니다.
+func (r *Registry) List(q Query) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expireLocked()
+	var out []Entry
+	for _, e := range r.entries {
+		if q.matches(e.Card) {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Expire는 TTL이 지난 에이전트를 제거하고, 제거한 이름 목록을 반환합니다.
+func (r *Registry) Expire() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := r.expireLocked()
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, r.saveLocked()
+}
+
+func (r *Registry) expireLocked() []string {
+	now := r.now()
+	var removed []string
+	for name, e := range r.entries {
+		if now.After(e.ExpiresAt()) {
+			delete(r.entries, name)
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// saveLocked는 임시 파일에 쓴 뒤 rename 하여, 중간에 프로세스가 죽어도 파일이 깨지지 않게 합니다.
+func (r *Registry) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+	entries := make([]Entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode registry: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write registry file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write registry file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write registry file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to write registry file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package a2aregistry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// fakeClock은 테스트에서 직접 움직이는 시계입니다.
+type fakeClock struct{ t time.Time }
+
+func newFakeClock() *fakeClock               { return &fakeClock{t: time.Unix(1_700_000_000, 0)} }
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func card(name string, skills ...a2a.AgentSkill) *a2a.AgentCard {
+	return &a2a.AgentCard{Name: name, Skills: skills}
+}
+
+func names(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Name
+	}
+	return out
+}
+
+func TestRegistryTTL(t *testing.T) {
+	clock := newFakeClock()
+	r := NewInMemory(WithClock(clock.now))
+	if _, err := r.Register("", "http://prime", card("prime"), 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	e, err := r.Register("", "http://echo", card("echo"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.TTL != DefaultTTL {
+		t.Errorf("TTL = %v, want DefaultTTL", e.TTL)
+	}
+
+	// TTL과 같은 시각까지는 살아 있고, 그 뒤에 만료됩니다.
+	clock.advance(10 * time.Second)
+	if got := names(r.List(Query{})); !slices.Equal(got, []string{"echo", "prime"}) {
+		t.Fatalf("at the TTL: List = %v", got)
+	}
+	clock.advance(time.Second)
+	if got := names(r.List(Query{})); !slices.Equal(got, []string{"echo"}) {
+		t.Fatalf("after the TTL: List = %v", got)
+	}
+	if _, err := r.Heartbeat("", "prime"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Heartbeat on an expired agent = %v, want ErrNotFound", err)
+	}
+
+	// 하트비트를 받으면 그 시각부터 TTL을 다시 셉니다.
+	clock.advance(DefaultTTL - 11*time.Second)
+	if _, err := r.Heartbeat("", "echo"); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(DefaultTTL - time.Second)
+	if removed, err := r.Expire(); err != nil || len(removed) != 0 {
+		t.Errorf("Expire after a heartbeat = %v, %v; want nothing", removed, err)
+	}
+	clock.advance(2 * time.Second)
+	if removed, err := r.Expire(); err != nil || !slices.Equal(removed, []string{"echo"}) {
+		t.Errorf("Expire = %v, %v; want [echo]", removed, err)
+	}
+
+	// 만료된 이름은 다른 주인이 새로 등록할 수 있습니다.
+	if _, err := r.Register("bob", "http://prime2", card("prime"), 0); err != nil {
+		t.Errorf("Register over an expired entry = %v", err)
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	r := NewInMemory()
+	agents := []*a2a.AgentCard{
+		card("prime", a2a.AgentSkill{ID: "check_prime", Name: "Check Prime", Tags: []string{"math", "number-theory"}}),
+		card("calc", a2a.AgentSkill{ID: "add", Name: "Add", Tags: []string{"Math"}}, a2a.AgentSkill{ID: "weather", Name: "Weather"}),
+		card("echo"),
+	}
+	for _, c := range agents {
+		if _, err := r.Register("", "http://"+c.Name, c, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{name: "조건 없음", q: Query{}, want: []string{"calc", "echo", "prime"}},
+		{name: "스킬 ID", q: Query{Skill: "check_prime"}, want: []string{"prime"}},
+		{name: "스킬 이름은 대소문자 무시", q: Query{Skill: "check PRIME"}, want: []string{"prime"}},
+		{name: "태그는 대소문자 무시", q: Query{Tag: "math"}, want: []string{"calc", "prime"}},
+		{name: "스킬과 태그가 같은 스킬에 있어야 함", q: Query{Skill: "weather", Tag: "math"}, want: nil},
+		{name: "스킬과 태그", q: Query{Skill: "add", Tag: "MATH"}, want: []string{"calc"}},
+		{name: "없는 스킬", q: Query{Skill: "translate"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(r.List(tt.q)); !slices.Equal(got, tt.want) {
+				t.Errorf("List(%+v) = %v, want %v", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileBackedPersistsAcrossReopen(t *testing.T) {
+	clock := newFakeClock()
+	path := filepath.Join(t.TempDir(), "registry.json")
+
+	r, err := NewFileBacked(path, WithClock(clock.now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register("alice", "http://prime", card("prime", a2a.AgentSkill{ID: "check_prime"}), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register("alice", "http://echo", card("echo"), 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register("alice", "http://gone", card("gone"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Deregister("alice", "gone"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 다시 열면 카드와 주인이 그대로이고, 그 사이 만료된 에이전트는 빠집니다.
+	clock.advance(10 * time.Second)
+	reopened, err := NewFileBacked(path, WithClock(clock.now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reopened.List(Query{Skill: "check_prime"})
+	if len(got) != 1 || got[0].CardURL != "http://prime" || got[0].Owner != "alice" || got[0].TTL != time.Minute {
+		t.Fatalf("reopened entries = %+v", got)
+	}
+	if all := names(reopened.List(Query{})); !slices.Equal(all, []string{"prime"}) {
+		t.Errorf("reopened List = %v, want only prime", all)
+	}
+	// 주인도 저장되므로 다른 호출자는 여전히 바꿀 수 없습니다.
+	if _, err := reopened.Heartbeat("bob", "prime"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Heartbeat(bob) after reopen = %v, want ErrForbidden", err)
+	}
+
+	// 임시 파일은 남지 않습니다.
+	files, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("registry directory has %d files, want only the registry file", len(files))
+	}
+}
+
+func TestNewFileBackedErrors(t *testing.T) {
+	dir := t.TempDir()
+	if r, err := NewFileBacked(filepath.Join(dir, "missing.json")); err != nil || len(r.List(Query{})) != 0 {
+		t.Errorf("missing file: %v, %v; want an empty registry", r, err)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileBacked(bad); err == nil {
+		t.Error("malformed file was accepted")
+	}
+}
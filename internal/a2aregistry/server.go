@@ -0,0 +1,146 @@
+package a2aregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"awesomeProject2/internal/a2aauth"
+)
+
+// maxRegisterBody는 POST /agents 본문의 최대 크기입니다. Agent Card 하나로는 충분하고,
+// 검색이 공개되어 있으니 큰 본문으로 메모리와 레지스트리 파일을 채우지 못하게 막습니다.
+const maxRegisterBody = 1 << 20
+
+// registerRequest는 POST /agents 요청 본문입니다.
+type registerRequest struct {
+	CardURL    string         `json:"cardUrl"`
+	Card       *a2a.AgentCard `json:"card"`
+	TTLSeconds int            `json:"ttlSeconds,omitempty"`
+}
+
+// entryResponse는 등록 정보를 외부에 보여줄 때의 형식입니다.
+type entryResponse struct {
+	Name      string         `json:"name"`
+	CardURL   string         `json:"cardUrl"`
+	Card      *a2a.AgentCard `json:"card"`
+	LastSeen  time.Time      `json:"lastSeen"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+func toResponse(e Entry) entryResponse {
+	return entryResponse{Name: e.Name, CardURL: e.CardURL, Card: e.Card, LastSeen: e.LastSeen, ExpiresAt: e.ExpiresAt()}
+}
+
+// NewHandler는 레지스트리 HTTP API를 반환합니다.
+//
+//	POST   /agents                  카드 등록 (본문: {"cardUrl", "card", "ttlSeconds"})
+//	PUT    /agents/{name}/heartbeat 하트비트, 만료된 경우 404
+//	DELETE /agents/{name}           등록 해제
+//	GET    /agents?skill=&tag=      살아있는 에이전트 검색
+//
+// 등록, 하트비트, 해제는 auths 중 하나를 통과한 호출자만 할 수 있고, 처음 등록한 호출자만 그 항목을 바꿀 수 있습니다(403).
+// 검색은 consumer가 자격 증명 없이도 쓸 수 있게 공개합니다. auths가 비어 있으면 모든 요청을 인증 없이 받습니다.
+func NewHandler(reg *Registry, auths []a2aauth.Authenticator) http.Handler {
+	mux := http.NewServeMux()
+	protect := a2aauth.Middleware(auths)
+
+	mux.Handle("POST /agents", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req registerRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRegisterBody)).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "malformed register request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		owner, _ := a2aauth.ClientFrom(r.Context())
+		e, err := reg.Register(owner, req.CardURL, req.Card, time.Duration(req.TTLSeconds)*time.Second)
+		if errors.Is(err, ErrForbidden) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[registry] registered %q (%s) by %q", e.Name, e.CardURL, owner)
+		writeJSON(w, toResponse(e))
+	})))
+
+	mux.Handle("PUT /agents/{name}/heartbeat", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		owner, _ := a2aauth.ClientFrom(r.Context())
+		e, err := reg.Heartbeat(owner, r.PathValue("name"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, toResponse(e))
+	})))
+
+	mux.Handle("DELETE /agents/{name}", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		owner, _ := a2aauth.ClientFrom(r.Context())
+		if err := reg.Deregister(owner, r.PathValue("name")); err != nil {
+			writeError(w, err)
+			return
+		}
+		log.Printf("[registry] deregistered %q", r.PathValue("name"))
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	mux.HandleFunc("GET /agents", func(w http.ResponseWriter, r *http.Request) {
+		q := Query{Skill: r.URL.Query().Get("skill"), Tag: r.URL.Query().Get("tag")}
+		out := []entryResponse{}
+		for _, e := range reg.List(q) {
+			out = append(out, toResponse(e))
+		}
+		writeJSON(w, out)
+	})
+
+	return mux
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RunExpiry는 ctx가 끝날 때까지 interval마다 만료된 에이전트를 정리합니다.
+func RunExpiry(ctx context.Context, reg *Registry, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := reg.Expire()
+			if err != nil {
+				log.Printf("[registry] expiry failed: %v", err)
+			}
+			for _, name := range removed {
+				log.Printf("[registry] expired %q (no heartbeat)", name)
+			}
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[registry] failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package a2aregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"awesomeProject2/internal/a2aauth"
+)
+
+func TestHandlerRequiresOwnerToken(t *testing.T) {
+	auths := []a2aauth.Authenticator{a2aauth.NewBearer(map[string]string{"token-a": "alice", "token-b": "bob"})}
+	srv := httptest.NewServer(NewHandler(NewInMemory(), auths))
+	defer srv.Close()
+	ctx := context.Background()
+	card := &a2a.AgentCard{Name: "prime"}
+
+	client := func(token string) *Client {
+		c := NewClient(srv.URL)
+		c.Token = token
+		return c
+	}
+	alice, bob, anonymous := client("token-a"), client("token-b"), client("")
+
+	if err := anonymous.Register(ctx, srv.URL, card, 0); err == nil {
+		t.Fatal("Register without a token succeeded")
+	}
+	if err := client("wrong").Register(ctx, srv.URL, card, 0); err == nil {
+		t.Fatal("Register with an unknown token succeeded")
+	}
+	if err := alice.Register(ctx, srv.URL, card, 0); err != nil {
+		t.Fatalf("Register(alice) = %v", err)
+	}
+
+	// 다른 주인은 교체, 연장, 해제를 할 수 없습니다.
+	if err := bob.Register(ctx, "http://evil", card, 0); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Register(bob) = %v, want ErrForbidden", err)
+	}
+	if err := bob.Heartbeat(ctx, "prime"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Heartbeat(bob) = %v, want ErrForbidden", err)
+	}
+	if err := bob.Deregister(ctx, "prime"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Deregister(bob) = %v, want ErrForbidden", err)
+	}
+
+	// 검색은 토큰 없이 할 수 있고, 카드는 그대로입니다.
+	entries, err := anonymous.Find(ctx, Query{})
+	if err != nil {
+		t.Fatalf("Find = %v", err)
+	}
+	if len(entries) != 1 || entries[0].CardURL != srv.URL {
+		t.Fatalf("Find = %+v, want alice's entry", entries)
+	}
+
+	if err := alice.Heartbeat(ctx, "prime"); err != nil {
+		t.Errorf("Heartbeat(alice) = %v", err)
+	}
+	if err := alice.Deregister(ctx, "prime"); err != nil {
+		t.Errorf("Deregister(alice) = %v", err)
+	}
+}
+
+func TestHandlerRejectsLargeRegisterBody(t *testing.T) {
+	reg := NewInMemory()
+	srv := httptest.NewServer(NewHandler(reg, nil))
+	defer srv.Close()
+
+	// 카드 설명을 한도보다 길게 채워 본문이 maxRegisterBody를 넘게 합니다.
+	body, err := json.Marshal(registerRequest{CardURL: srv.URL, Card: &a2a.AgentCard{Name: "huge", Description: strings.Repeat("x", maxRegisterBody)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(srv.URL+"/agents", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", resp.StatusCode)
+	}
+	if got := reg.List(Query{}); len(got) != 0 {
+		t.Errorf("oversized card was registered: %+v", got)
+	}
+
+	// 한도 안의 본문은 그대로 받습니다.
+	if err := NewClient(srv.URL).Register(context.Background(), srv.URL, &a2a.AgentCard{Name: "small"}, 0); err != nil {
+		t.Errorf("Register(small) = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package handoff
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// 상담원 페이지의 로그인 쿠키 이름
+const loginCookie = "handoff_operator"
+
+// operatorAuth는 상담원 API와 페이지에 상담원끼리 나눠 가진 토큰을 요구합니다.
+// API는 Authorization: Bearer <토큰> 헤더로, 페이지는 /login에서 토큰을 한 번 입력해 받은 쿠키로 인증합니다.
+// 쿠키 값과 폼에 숨겨 보내는 CSRF 값은 토큰에서 HMAC으로 만들어, 토큰 자체는 브라우저에 남지 않습니다.
+type operatorAuth struct {
+	token  string
+	cookie string // 로그인 쿠키 값
+	csrf   string // 폼의 csrf 필드 값
+}
+
+func newOperatorAuth(token string) *operatorAuth {
+	return &operatorAuth{token: token, cookie: derive(token, "cookie"), csrf: derive(token, "csrf")}
+}
+
+// derive는 토큰에서 용도마다 다른 값을 만듭니다.
+func derive(token, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte("handoff/" + purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// api는 Bearer 토큰이 맞지 않으면 401로 응답합니다.
+func (a *operatorAuth) api(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !equal(token, a.token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="handoff"`)
+			http.Error(w, "missing or invalid operator token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// page는 로그인 쿠키가 없으면 로그인 페이지로 보냅니다.
+func (a *operatorAuth) page(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie(loginCookie); err != nil || !equal(c.Value, a.cookie) {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// form은 page에 더해, 폼에 숨겨 보낸 csrf 값이 맞는지 확인합니다.
+// 쿠키는 다른 사이트에서 보낸 폼에도 실려 올 수 있으므로, 우리 페이지가 그린 폼만 받습니다.
+func (a *operatorAuth) form(next http.HandlerFunc) http.HandlerFunc {
+	return a.page(func(w http.ResponseWriter, r *http.Request) {
+		if !equal(r.PostFormValue("csrf"), a.csrf) {
+			http.Error(w, "invalid CSRF token", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// login은 토큰이 맞으면 로그인 쿠키를 주고 상담원 페이지로 보냅니다.
+func (a *operatorAuth) login(w http.ResponseWriter, r *http.Request) {
+	if !equal(r.PostFormValue("token"), a.token) {
+		renderLogin(w, http.StatusUnauthorized, "토큰이 맞지 않습니다.")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     loginCookie,
+		Value:    a.cookie,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
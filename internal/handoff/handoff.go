@@ -0,0 +1,289 @@
+// Package handoff는 에이전트가 직접 처리하지 못하고 사람(상담원)에게 넘긴 요청을 모아두는 대기열입니다.
+//
+// 라우터가 escalate_to_human으로 분류한 요청은 Ticket이 되어 대기열에 들어가고,
+// 상담원은 Pending으로 아직 처리되지 않은 티켓을 우선순위 순으로 봅니다.
+// 티켓은 open → claimed(상담원이 맡음) → resolved(상담원이 답함) 순서로 진행됩니다.
+// NewHandler는 상담원용 HTTP API와 웹 페이지를 제공하고, 상담원의 답변을 사용자의 세션에 이벤트로 넣어줍니다.
+//
+// NewInMemory는 메모리에만 보관하고, Open은 JSONL 파일에 한 줄씩 덧붙여(append-only) 기록합니다.
+package handoff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"awesomeProject2/internal/jsonl"
+)
+
+// Status는 티켓의 처리 상태입니다.
+type Status string
+
+const (
+	StatusOpen     Status = "open"     // 상담원을 기다리는 중
+	StatusClaimed  Status = "claimed"  // 상담원이 맡아 답변을 준비하는 중
+	StatusResolved Status = "resolved" // 상담원이 답변함
+)
+
+var (
+	// ErrNotFound는 티켓이 없을 때 반환됩니다.
+	ErrNotFound = errors.New("ticket not found")
+	// ErrConflict는 다른 상담원이 이미 맡았거나 이미 답변한 티켓을 맡거나 답하려 할 때 반환됩니다.
+	ErrConflict = errors.New("ticket is not available")
+)
+
+// Ticket은 사람에게 넘긴 요청 하나입니다.
+type Ticket struct {
+	ID        string `json:"id"`
+	AppName   string `json:"appName"`
+	UserID    string `json:"userId"`
+	SessionID string `json:"sessionId"`
+	// Summary는 라우터가 요약한 사용자의 의도, Reason은 사람에게 넘긴 이유입니다.
+	Summary   string    `json:"summary"`
+	Reason    string    `json:"reason,omitempty"`
+	Priority  string    `json:"priority"` // high, medium, low
+	Message   string    `json:"message"`  // 사용자가 보낸 원래 메시지
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// 상담원이 맡은 뒤에 채워집니다.
+	Operator    string    `json:"operator,omitempty"`
+	ClaimedAt   time.Time `json:"claimedAt,omitzero"`
+	Reply       string    `json:"reply,omitempty"`
+	RespondedAt time.Time `json:"respondedAt,omitzero"`
+}
+
+// checkOperator는 operator가 이 티켓을 맡거나 답할 수 있는지 확인합니다.
+// 아직 아무도 맡지 않았거나 operator 본인이 맡은 티켓만 가능합니다.
+func (t *Ticket) checkOperator(operator string) error {
+	switch {
+	case t.Status == StatusResolved:
+		return fmt.Errorf("%w: %s was already answered by %s", ErrConflict, t.ID, t.Operator)
+	case t.Status == StatusClaimed && t.Operator != operator:
+		return fmt.Errorf("%w: %s is claimed by %s", ErrConflict, t.ID, t.Operator)
+	}
+	return nil
+}
+
+// 우선순위가 높은 티켓부터 보여주기 위한 순서
+var priorityRank = map[string]int{"high": 0, "medium": 1, "low": 2}
+
+// 파일에 기록되는 한 줄
+type record struct {
+	Op     string    `json:"op"` // enqueue, update
+	Ticket *Ticket   `json:"ticket"`
+	Time   time.Time `json:"time"`
+}
+
+// Queue는 티켓 대기열입니다. 여러 고루틴에서 동시에 써도 안전합니다.
+type Queue struct {
+	mu      sync.RWMutex
+	file    *jsonl.Log
+	closed  bool
+	tickets map[string]*Ticket
+	// responding은 답변을 전달하는 중인 티켓과 그 상담원입니다. 전달이 끝날 때까지 다른 요청은 티켓을 바꿀 수 없습니다.
+	responding map[string]string
+}
+
+// NewInMemory는 파일에 기록하지 않는 Queue를 만듭니다.
+func NewInMemory() *Queue {
+	return &Queue{tickets: make(map[string]*Ticket), responding: make(map[string]string)}
+}
+
+// Open은 path의 대기열 파일을 읽어 Queue를 만듭니다. 파일이 없으면 새로 만듭니다.
+// 기록 도중 프로세스가 죽어 마지막 줄이 잘렸다면 그 줄은 버립니다.
+func Open(path string) (*Queue, error) {
+	q := NewInMemory()
+	f, err := jsonl.Open(path, q.apply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open handoff file: %w", err)
+	}
+	q.file = f
+	return q, nil
+}
+
+// apply는 기록 한 줄을 메모리에 반영합니다. 파일에서 읽은 기록이 형식에 맞지 않으면 오류를 반환합니다.
+func (q *Queue) apply(rec record) error {
+	switch rec.Op {
+	case "enqueue", "update":
+		if rec.Ticket == nil || rec.Ticket.ID == "" {
+			return fmt.Errorf("%s record without a ticket", rec.Op)
+		}
+		q.tickets[rec.Ticket.ID] = rec.Ticket
+	default:
+		return fmt.Errorf("unknown op %q", rec.Op)
+	}
+	return nil
+}
+
+// write는 rec을 파일 끝에 기록하고(fsync 포함) 메모리에 반영합니다. q.mu를 잡은 상태에서 호출해야 합니다.
+func (q *Queue) write(rec record) error {
+	if q.closed {
+		return errors.New("handoff queue is closed")
+	}
+	if q.file != nil {
+		if err := q.file.Append(rec); err != nil {
+			return fmt.Errorf("failed to write handoff record: %w", err)
+		}
+	}
+	return q.apply(rec)
+}
+
+// Enqueue는 t에 ID, 상태, 접수 시각을 채워 대기열에 넣고 저장된 티켓을 반환합니다.
+// Priority가 비어 있거나 알 수 없는 값이면 medium으로 둡니다.
+func (q *Queue) Enqueue(t Ticket) (Ticket, error) {
+	if _, ok := priorityRank[t.Priority]; !ok {
+		t.Priority = "medium"
+	}
+	t.ID = uuid.NewString()[:8]
+	t.Status = StatusOpen
+	t.CreatedAt = time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.write(record{Op: "enqueue", Ticket: &t, Time: t.CreatedAt}); err != nil {
+		return Ticket{}, err
+	}
+	return t, nil
+}
+
+// Get은 ID가 id인 티켓을 반환합니다.
+func (q *Queue) Get(id string) (Ticket, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	t, ok := q.tickets[id]
+	if !ok {
+		return Ticket{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return *t, nil
+}
+
+// List는 appName의 티켓 중 상태가 statuses 중 하나인 것을 우선순위가 높은 것부터, 같은 우선순위에서는 먼저 들어온 것부터 반환합니다.
+// appName이 비어 있으면 모든 앱의, statuses가 비어 있으면 모든 상태의 티켓을 반환합니다.
+func (q *Queue) List(appName string, statuses ...Status) []Ticket {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	var tickets []Ticket
+	for _, t := range q.tickets {
+		if (appName == "" || t.AppName == appName) && (len(statuses) == 0 || slices.Contains(statuses, t.Status)) {
+			tickets = append(tickets, *t)
+		}
+	}
+	slices.SortFunc(tickets, func(a, b Ticket) int {
+		if d := priorityRank[a.Priority] - priorityRank[b.Priority]; d != 0 {
+			return d
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return tickets
+}
+
+// Pending은 appName의 아직 답변하지 않은(open, claimed) 티켓을 List와 같은 순서로 반환합니다.
+func (q *Queue) Pending(appName string) []Ticket {
+	return q.List(appName, StatusOpen, StatusClaimed)
+}
+
+// Claim은 operator가 티켓을 맡습니다. 이미 맡은 티켓을 다시 맡는 것은 괜찮지만,
+// 다른 상담원이 맡았거나 이미 답변한 티켓이면 ErrConflict를 반환합니다.
+func (q *Queue) Claim(id, operator string) (Ticket, error) {
+	if operator == "" {
+		return Ticket{}, errors.New("operator is required")
+	}
+	return q.update(id, operator, func(t *Ticket) {
+		if t.Status == StatusOpen {
+			t.Status, t.Operator, t.ClaimedAt = StatusClaimed, operator, time.Now()
+		}
+	})
+}
+
+// Respond는 operator의 답변을 deliver로 사용자에게 전달한 뒤, 기록하고 티켓을 resolved로 바꿉니다. 맡지 않은 티켓에 바로 답해도 됩니다.
+// deliver가 nil이면 기록만 합니다(NewHandler는 답변을 사용자의 세션에 이벤트로 넣는 deliver를 넘깁니다).
+//
+// 전달은 되돌릴 수 없으므로, 먼저 티켓을 잡아둔 뒤에 전달합니다. 아직 아무도 맡지 않은 티켓은 이때 operator가 맡은 것으로 기록되고,
+// 전달이 끝날 때까지 다른 상담원은 물론 같은 상담원의 다른 요청도 이 티켓을 맡거나 답할 수 없습니다(ErrConflict).
+// 그래서 두 답변이 함께 전달되는 일은 없습니다. 전달에 실패하면 잡아둔 것만 풀고 티켓은 operator가 맡은 채로 남으므로, 다시 답할 수 있습니다.
+func (q *Queue) Respond(id, operator, reply string, deliver func(Ticket) error) (Ticket, error) {
+	if operator == "" || reply == "" {
+		return Ticket{}, errors.New("operator and reply are required")
+	}
+	q.mu.Lock()
+	t, err := q.updateLocked(id, operator, func(t *Ticket) {
+		if t.Status == StatusOpen {
+			t.Status, t.Operator, t.ClaimedAt = StatusClaimed, operator, time.Now()
+		}
+	})
+	if err == nil {
+		q.responding[id] = operator
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	if deliver != nil {
+		if err := deliver(t); err != nil {
+			q.mu.Lock()
+			delete(q.responding, id)
+			q.mu.Unlock()
+			return Ticket{}, err
+		}
+	}
+
+	// 잡아둔 동안에는 아무도 티켓을 바꿀 수 없으므로, 다시 확인하지 않고 기록합니다.
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.responding, id)
+	t.Status, t.Reply, t.RespondedAt = StatusResolved, reply, time.Now()
+	if err := q.write(record{Op: "update", Ticket: &t, Time: t.RespondedAt}); err != nil {
+		return Ticket{}, fmt.Errorf("reply was delivered but not recorded: %w", err)
+	}
+	return t, nil
+}
+
+// update는 operator가 티켓을 바꿀 수 있는지 확인한 뒤, 사본에 change를 적용해 기록합니다.
+func (q *Queue) update(id, operator string, change func(t *Ticket)) (Ticket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.updateLocked(id, operator, change)
+}
+
+// updateLocked는 q.mu를 잡은 상태에서 update를 합니다. 답변을 전달하는 중인 티켓은 바꿀 수 없고, 바뀐 것이 없으면 기록하지 않습니다.
+func (q *Queue) updateLocked(id, operator string, change func(t *Ticket)) (Ticket, error) {
+	old, ok := q.tickets[id]
+	if !ok {
+		return Ticket{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if busy, ok := q.responding[id]; ok {
+		return Ticket{}, fmt.Errorf("%w: %s is being answered by %s", ErrConflict, id, busy)
+	}
+	if err := old.checkOperator(operator); err != nil {
+		return Ticket{}, err
+	}
+	t := *old
+	change(&t)
+	if *old == t {
+		return t, nil
+	}
+	if err := q.write(record{Op: "update", Ticket: &t, Time: time.Now()}); err != nil {
+		return Ticket{}, err
+	}
+	return t, nil
+}
+
+// Close는 대기열 파일을 닫습니다.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	if q.file == nil {
+		return nil
+	}
+	return q.file.Close()
+}
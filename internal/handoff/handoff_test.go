@@ -0,0 +1,93 @@
+package handoff
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRespondReservesTicketWhileDelivering(t *testing.T) {
+	q := NewInMemory()
+	ticket, err := q.Enqueue(Ticket{AppName: "app", UserID: "alice", SessionID: "s1", Summary: "환불 요청"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 첫 답변이 전달되는 동안 다른 요청들을 보냅니다.
+	delivering, release := make(chan struct{}), make(chan struct{})
+	var delivered atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := q.Respond(ticket.ID, "kim", "확인했습니다", func(Ticket) error {
+			delivered.Add(1)
+			close(delivering)
+			<-release
+			return nil
+		})
+		if err != nil {
+			t.Errorf("first Respond = %v", err)
+		}
+	}()
+	<-delivering
+
+	// 답하는 중인 티켓은 같은 상담원이든 다른 상담원이든 맡거나 답할 수 없습니다.
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"same operator responds again", func() error {
+			_, err := q.Respond(ticket.ID, "kim", "한 번 더", func(Ticket) error { delivered.Add(1); return nil })
+			return err
+		}},
+		{"other operator responds", func() error {
+			_, err := q.Respond(ticket.ID, "lee", "제가 할게요", func(Ticket) error { delivered.Add(1); return nil })
+			return err
+		}},
+		{"other operator claims", func() error {
+			_, err := q.Claim(ticket.ID, "lee")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); !errors.Is(err, ErrConflict) {
+			t.Errorf("%s: err = %v, want ErrConflict", tt.name, err)
+		}
+	}
+
+	close(release)
+	wg.Wait()
+	if n := delivered.Load(); n != 1 {
+		t.Errorf("reply delivered %d times, want 1", n)
+	}
+	got, _ := q.Get(ticket.ID)
+	if got.Status != StatusResolved || got.Operator != "kim" || got.Reply != "확인했습니다" {
+		t.Errorf("ticket = %+v, want resolved by kim", got)
+	}
+}
+
+func TestRespondDeliveryFailureKeepsClaim(t *testing.T) {
+	q := NewInMemory()
+	ticket, err := q.Enqueue(Ticket{AppName: "app", UserID: "alice", SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("session is gone")
+	if _, err := q.Respond(ticket.ID, "kim", "확인했습니다", func(Ticket) error { return failed }); !errors.Is(err, failed) {
+		t.Fatalf("Respond = %v, want delivery error", err)
+	}
+	// 전달하지 못한 티켓은 kim이 맡은 채로 남아, 다른 상담원은 가져갈 수 없고 kim은 다시 답할 수 있습니다.
+	got, _ := q.Get(ticket.ID)
+	if got.Status != StatusClaimed || got.Operator != "kim" {
+		t.Errorf("ticket = %+v, want claimed by kim", got)
+	}
+	if _, err := q.Respond(ticket.ID, "lee", "제가 할게요", nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("Respond(lee) = %v, want ErrConflict", err)
+	}
+	if _, err := q.Respond(ticket.ID, "kim", "다시 보냅니다", nil); err != nil {
+		t.Errorf("Respond(kim) retry = %v", err)
+	}
+}
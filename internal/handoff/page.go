@@ -0,0 +1,134 @@
+package handoff
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+)
+
+// pageData는 상담원 페이지에 그리는 값입니다.
+type pageData struct {
+	Tickets  []Ticket
+	Status   string // 목록 필터 (비어 있으면 답변하지 않은 티켓)
+	Operator string // 폼에 미리 채울 상담원 이름
+	Error    string // 직전 폼 처리에서 난 오류
+	CSRF     string // 폼마다 숨겨 보내는 값 (operatorAuth.form이 확인)
+}
+
+// 상담원 페이지. JavaScript 없이 폼만으로 맡기/답변하기를 합니다.
+var pageTemplate = template.Must(template.New("operator").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format(time.DateTime) },
+}).Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>상담원 대기열</title>
+<style>
+  body { font-family: -apple-system, "Apple SD Gothic Neo", "Noto Sans KR", sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #222; background: #f6f7f9; }
+  nav { display: flex; gap: 1rem; align-items: center; flex-wrap: wrap; margin-bottom: 1rem; }
+  nav a { color: #1c7ed6; text-decoration: none; }
+  nav a.current { font-weight: 700; text-decoration: underline; }
+  .error { background: #fff5f5; color: #c92a2a; padding: .6rem 1rem; border-radius: 8px; }
+  .ticket { background: #fff; border-radius: 10px; padding: .8rem 1rem; margin: .8rem 0; box-shadow: 0 1px 2px rgba(0,0,0,.08); }
+  .meta { font-size: .8rem; color: #777; }
+  .badge { display: inline-block; border-radius: 4px; padding: 0 .4rem; font-size: .8rem; color: #fff; }
+  .high { background: #e03131; } .medium { background: #f08c00; } .low { background: #868e96; }
+  .status { font-weight: 600; }
+  .summary { font-weight: 600; margin: .4rem 0; }
+  .message { white-space: pre-wrap; background: #e8f1ff; padding: .5rem .7rem; border-radius: 6px; }
+  .reason { color: #555; font-size: .9rem; margin: .4rem 0; }
+  .reply { white-space: pre-wrap; background: #ebfbee; padding: .5rem .7rem; border-radius: 6px; }
+  form { margin-top: .5rem; }
+  textarea { width: 100%; min-height: 4rem; box-sizing: border-box; }
+</style>
+</head>
+<body>
+<h1>상담원 대기열</h1>
+<nav>
+  <a href="/?operator={{.Operator}}" {{if eq .Status ""}}class="current"{{end}}>답변 대기</a>
+  <a href="/?status=open&operator={{.Operator}}" {{if eq .Status "open"}}class="current"{{end}}>새 요청</a>
+  <a href="/?status=claimed&operator={{.Operator}}" {{if eq .Status "claimed"}}class="current"{{end}}>처리 중</a>
+  <a href="/?status=resolved&operator={{.Operator}}" {{if eq .Status "resolved"}}class="current"{{end}}>답변 완료</a>
+  <a href="/?status=all&operator={{.Operator}}" {{if eq .Status "all"}}class="current"{{end}}>전체</a>
+  <form method="get" action="/">
+    {{if .Status}}<input type="hidden" name="status" value="{{.Status}}">{{end}}
+    <label>상담원 이름 <input name="operator" value="{{.Operator}}" required></label>
+    <button>적용</button>
+  </form>
+</nav>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if not .Tickets}}<p>티켓이 없습니다.</p>{{end}}
+{{range .Tickets}}
+<div class="ticket">
+  <div class="meta">
+    <span class="badge {{.Priority}}">{{.Priority}}</span>
+    <span class="status">{{.Status}}</span>
+    #{{.ID}} · {{datetime .CreatedAt}} · 사용자 {{.UserID}} ·
+    <a href="/tickets/{{.ID}}/session" target="_blank">대화 보기</a>
+    {{if .Operator}} · 담당 {{.Operator}}{{end}}
+  </div>
+  <div class="summary">{{.Summary}}</div>
+  <div class="message">{{.Message}}</div>
+  {{if .Reason}}<div class="reason">라우터 판단: {{.Reason}}</div>{{end}}
+  {{if eq .Status "resolved"}}
+    <div class="meta">{{datetime .RespondedAt}} 답변</div>
+    <div class="reply">{{.Reply}}</div>
+  {{else if not $.Operator}}
+    <p class="meta">맡거나 답하려면 위에서 상담원 이름을 입력하세요.</p>
+  {{else if and (eq .Status "claimed") (ne .Operator $.Operator)}}
+    <p class="meta">{{.Operator}} 상담원이 처리 중입니다.</p>
+  {{else}}
+    {{if eq .Status "open"}}
+    <form method="post" action="/tickets/{{.ID}}/claim">
+      <input type="hidden" name="csrf" value="{{$.CSRF}}">
+      <input type="hidden" name="operator" value="{{$.Operator}}">
+      <button>맡기</button>
+    </form>
+    {{end}}
+    <form method="post" action="/tickets/{{.ID}}/respond">
+      <input type="hidden" name="csrf" value="{{$.CSRF}}">
+      <input type="hidden" name="operator" value="{{$.Operator}}">
+      <textarea name="reply" placeholder="사용자에게 보낼 답변" required></textarea>
+      <button>답변 보내기</button>
+    </form>
+  {{end}}
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// 로그인 페이지. 상담원 토큰을 한 번 입력하면 쿠키로 기억합니다.
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>상담원 로그인</title>
+<style>
+  body { font-family: -apple-system, "Apple SD Gothic Neo", "Noto Sans KR", sans-serif; max-width: 360px; margin: 4rem auto; padding: 0 1rem; color: #222; background: #f6f7f9; }
+  .error { background: #fff5f5; color: #c92a2a; padding: .6rem 1rem; border-radius: 8px; }
+  input { width: 100%; box-sizing: border-box; margin: .5rem 0; }
+</style>
+</head>
+<body>
+<h1>상담원 로그인</h1>
+{{if .}}<p class="error">{{.}}</p>{{end}}
+<form method="post" action="/login">
+  <label>상담원 토큰 <input type="password" name="token" autocomplete="current-password" required autofocus></label>
+  <button>들어가기</button>
+</form>
+</body>
+</html>
+`))
+
+// renderLogin은 로그인 페이지를 status로 그립니다. errMsg가 있으면 위쪽에 보여줍니다.
+func renderLogin(w http.ResponseWriter, status int, errMsg string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := loginTemplate.Execute(w, errMsg); err != nil {
+		log.Printf("[handoff] failed to render login page: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package handoff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/transcript"
+)
+
+// OperatorAuthor는 상담원의 답변을 세션에 넣을 때 쓰는 이벤트 작성자입니다.
+const OperatorAuthor = "human_operator"
+
+// respondRequest는 답변 요청 본문입니다.
+type respondRequest struct {
+	Operator string `json:"operator"`
+	Reply    string `json:"reply"`
+}
+
+// NewHandler는 상담원용 HTTP API와 웹 페이지를 반환합니다.
+// sessions는 에이전트가 쓰는 것과 같은 세션 서비스여야 합니다. 티켓의 대화를 보여주고, 답변을 그 세션에 넣습니다.
+//
+// 티켓에는 사용자의 메시지와 대화가 들어 있으므로 모든 경로에 token이 필요합니다.
+// API는 Authorization: Bearer <token> 헤더를 보내야 하고, 페이지는 /login에서 token을 입력하면 받는 쿠키로 들어갑니다.
+// 페이지의 폼은 숨긴 csrf 값을 함께 보내야 하고, 다른 출처(origin)에서 보낸 POST는 모두 거절합니다(http.CrossOriginProtection).
+//
+//	GET  /login                      로그인 페이지 (POST /login으로 token 제출)
+//	GET  /                           상담원 페이지 (?status=open|claimed|resolved|all, ?operator=이름)
+//	GET  /tickets/{id}/session       티켓이 나온 대화 (HTML 대화록)
+//	GET  /api/tickets?status=&app=   티켓 목록 (기본: 답변하지 않은 티켓)
+//	GET  /api/tickets/{id}           티켓 하나
+//	POST /api/tickets/{id}/claim     티켓 맡기 (본문: {"operator"})
+//	POST /api/tickets/{id}/respond   답변하기 (본문: {"operator", "reply"}) - 답변은 사용자의 세션에 이벤트로 들어갑니다.
+func NewHandler(q *Queue, sessions session.Service, token string) (http.Handler, error) {
+	if token == "" {
+		return nil, errors.New("operator token is required")
+	}
+	auth := newOperatorAuth(token)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		renderLogin(w, http.StatusOK, "")
+	})
+	mux.HandleFunc("POST /login", auth.login)
+
+	mux.HandleFunc("GET /api/tickets", auth.api(func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := parseStatus(r.URL.Query().Get("status"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tickets := q.List(r.URL.Query().Get("app"), statuses...)
+		if tickets == nil {
+			tickets = []Ticket{}
+		}
+		writeJSON(w, tickets)
+	}))
+
+	mux.HandleFunc("GET /api/tickets/{id}", auth.api(func(w http.ResponseWriter, r *http.Request) {
+		t, err := q.Get(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, t)
+	}))
+
+	mux.HandleFunc("POST /api/tickets/{id}/claim", auth.api(func(w http.ResponseWriter, r *http.Request) {
+		var req respondRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "malformed claim request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		t, err := claim(q, r.PathValue("id"), req.Operator)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, t)
+	}))
+
+	mux.HandleFunc("POST /api/tickets/{id}/respond", auth.api(func(w http.ResponseWriter, r *http.Request) {
+		var req respondRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "malformed respond request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		t, err := respond(r.Context(), q, sessions, r.PathValue("id"), req.Operator, req.Reply)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, t)
+	}))
+
+	// 상담원 페이지의 폼은 JavaScript 없이 POST한 뒤 목록으로 돌아갑니다.
+	mux.HandleFunc("POST /tickets/{id}/claim", auth.form(func(w http.ResponseWriter, r *http.Request) {
+		operator := strings.TrimSpace(r.PostFormValue("operator"))
+		_, err := claim(q, r.PathValue("id"), operator)
+		redirectToPage(w, r, operator, err)
+	}))
+
+	mux.HandleFunc("POST /tickets/{id}/respond", auth.form(func(w http.ResponseWriter, r *http.Request) {
+		operator := strings.TrimSpace(r.PostFormValue("operator"))
+		_, err := respond(r.Context(), q, sessions, r.PathValue("id"), operator, r.PostFormValue("reply"))
+		redirectToPage(w, r, operator, err)
+	}))
+
+	mux.HandleFunc("GET /tickets/{id}/session", auth.page(func(w http.ResponseWriter, r *http.Request) {
+		t, err := q.Get(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp, err := sessions.Get(r.Context(), &session.GetRequest{AppName: t.AppName, UserID: t.UserID, SessionID: t.SessionID})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("session %s is not available: %v", t.SessionID, err), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := transcript.FromSession(resp.Session).WriteHTML(w); err != nil {
+			log.Printf("[handoff] %v", err)
+		}
+	}))
+
+	mux.HandleFunc("GET /{$}", auth.page(func(w http.ResponseWriter, r *http.Request) {
+		status := r.URL.Query().Get("status")
+		statuses, err := parseStatus(status)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = pageTemplate.Execute(w, pageData{
+			Tickets:  q.List("", statuses...),
+			Status:   status,
+			Operator: r.URL.Query().Get("operator"),
+			Error:    r.URL.Query().Get("error"),
+			CSRF:     auth.csrf,
+		})
+		if err != nil {
+			log.Printf("[handoff] failed to render operator page: %v", err)
+		}
+	}))
+
+	return http.NewCrossOriginProtection().Handler(mux), nil
+}
+
+// parseStatus는 status 쿼리를 List에 넘길 상태 목록으로 바꿉니다. 비어 있으면 답변하지 않은 티켓, "all"이면 모든 티켓입니다.
+func parseStatus(s string) ([]Status, error) {
+	switch Status(s) {
+	case "":
+		return []Status{StatusOpen, StatusClaimed}, nil
+	case "all":
+		return nil, nil
+	case StatusOpen, StatusClaimed, StatusResolved:
+		return []Status{Status(s)}, nil
+	}
+	return nil, fmt.Errorf("unknown ticket status %q", s)
+}
+
+func claim(q *Queue, id, operator string) (Ticket, error) {
+	if operator == "" {
+		return Ticket{}, errInvalid("operator is required")
+	}
+	t, err := q.Claim(id, operator)
+	if err == nil {
+		log.Printf("[handoff] %s claimed %s", operator, id)
+	}
+	return t, err
+}
+
+// respond는 답변을 사용자의 세션에 넣은 뒤 티켓을 resolved로 바꿉니다.
+// 세션에 넣는 동안에는 Queue.Respond가 티켓을 잡아두므로 두 답변이 함께 들어가지 않습니다.
+// 세션에 넣지 못하면 티켓은 operator가 맡은 채로 남으므로, 상담원은 다시 답할 수 있습니다.
+func respond(ctx context.Context, q *Queue, sessions session.Service, id, operator, reply string) (Ticket, error) {
+	reply = strings.TrimSpace(reply)
+	if operator == "" || reply == "" {
+		return Ticket{}, errInvalid("operator and reply are required")
+	}
+	t, err := q.Respond(id, operator, reply, func(t Ticket) error {
+		if err := InjectReply(ctx, sessions, t, operator, reply); err != nil {
+			return fmt.Errorf("failed to deliver reply to session %s: %w", t.SessionID, err)
+		}
+		return nil
+	})
+	if err == nil {
+		log.Printf("[handoff] %s answered %s", operator, id)
+	}
+	return t, err
+}
+
+// InjectReply는 상담원의 답변을 티켓이 나온 세션에 OperatorAuthor의 이벤트로 덧붙입니다.
+// 웹 UI에서는 세션을 다시 열면 답변이 보이고, 에이전트도 다음 턴부터 이 답변을 대화의 일부로 봅니다.
+func InjectReply(ctx context.Context, sessions session.Service, t Ticket, operator, reply string) error {
+	resp, err := sessions.Get(ctx, &session.GetRequest{AppName: t.AppName, UserID: t.UserID, SessionID: t.SessionID})
+	if err != nil {
+		return err
+	}
+	// 답변은 에이전트 실행 밖에서 들어가므로 새 호출(invocation) ID를 쓰고, 이벤트 ID로 어느 티켓의 답변인지 남깁니다.
+	event := session.NewEvent("e-" + uuid.NewString())
+	event.ID = "handoff-" + t.ID
+	event.Author = OperatorAuthor
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(reply, genai.RoleModel)}
+	event.Actions.StateDelta = map[string]any{
+		"handoff_ticket_status": string(StatusResolved),
+		"handoff_operator":      operator,
+	}
+	return sessions.AppendEvent(ctx, resp.Session, event)
+}
+
+// errInvalid는 요청 값이 잘못됐을 때의 오류입니다. writeError가 400으로 바꿉니다.
+type errInvalid string
+
+func (e errInvalid) Error() string { return string(e) }
+
+func writeError(w http.ResponseWriter, err error) {
+	var invalid errInvalid
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// redirectToPage는 폼 처리 결과를 가지고 상담원 페이지로 돌아갑니다. 오류는 페이지 위쪽에 보여줍니다.
+func redirectToPage(w http.ResponseWriter, r *http.Request, operator string, err error) {
+	v := url.Values{}
+	if operator != "" {
+		v.Set("operator", operator)
+	}
+	if err != nil {
+		v.Set("error", err.Error())
+	}
+	http.Redirect(w, r, "/?"+v.Encode(), http.StatusSeeOther)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[handoff] failed to write response: %v", err)
+	}
+}
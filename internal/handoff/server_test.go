@@ -0,0 +1,91 @@
+package handoff
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/session"
+)
+
+func TestHandlerRequiresOperatorToken(t *testing.T) {
+	q := NewInMemory()
+	sessions := session.InMemoryService()
+	if _, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+	ticket, err := q.Enqueue(Ticket{AppName: "app", UserID: "alice", SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHandler(q, sessions, ""); err == nil {
+		t.Fatal("NewHandler without a token succeeded")
+	}
+	h, err := NewHandler(q, sessions, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := newOperatorAuth("secret")
+	loggedIn := &http.Cookie{Name: loginCookie, Value: auth.cookie}
+
+	claimForm := func(csrf string) string {
+		return url.Values{"operator": {"kim"}, "csrf": {csrf}}.Encode()
+	}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header http.Header
+		cookie *http.Cookie
+		status int
+	}{
+		{"API without token", "GET", "/api/tickets", "", nil, nil, http.StatusUnauthorized},
+		{"API with wrong token", "GET", "/api/tickets", "", http.Header{"Authorization": {"Bearer nope"}}, nil, http.StatusUnauthorized},
+		{"API with token", "GET", "/api/tickets", "", http.Header{"Authorization": {"Bearer secret"}}, nil, http.StatusOK},
+		{"API login cookie is not enough", "GET", "/api/tickets", "", nil, loggedIn, http.StatusUnauthorized},
+		{"page without login", "GET", "/", "", nil, nil, http.StatusSeeOther},
+		{"page with login", "GET", "/", "", nil, loggedIn, http.StatusOK},
+		{"transcript without login", "GET", "/tickets/" + ticket.ID + "/session", "", nil, nil, http.StatusSeeOther},
+		{"wrong login token", "POST", "/login", "token=nope", nil, nil, http.StatusUnauthorized},
+		{"form without csrf", "POST", "/tickets/" + ticket.ID + "/claim", claimForm(""), nil, loggedIn, http.StatusForbidden},
+		{"form from another site", "POST", "/tickets/" + ticket.ID + "/claim", claimForm(auth.csrf), http.Header{"Sec-Fetch-Site": {"cross-site"}}, loggedIn, http.StatusForbidden},
+		{"form with csrf", "POST", "/tickets/" + ticket.ID + "/claim", claimForm(auth.csrf), nil, loggedIn, http.StatusSeeOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.method == "POST" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.status, strings.TrimSpace(rec.Body.String()))
+			}
+		})
+	}
+
+	// 폼으로 맡은 티켓만 kim이 맡은 상태여야 합니다(CSRF로 거절된 요청은 바꾸지 않음).
+	if got, _ := q.Get(ticket.ID); got.Status != StatusClaimed || got.Operator != "kim" {
+		t.Errorf("ticket = %+v, want claimed by kim", got)
+	}
+
+	// 로그인에 성공하면 쿠키를 받습니다.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/login", strings.NewReader("token=secret"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(rec, req)
+	cookies := rec.Result().Cookies()
+	if rec.Code != http.StatusSeeOther || len(cookies) != 1 || cookies[0].Value != auth.cookie || !cookies[0].HttpOnly {
+		t.Errorf("login: status %d, cookies %v", rec.Code, cookies)
+	}
+}
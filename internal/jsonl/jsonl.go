@@ -0,0 +1,187 @@
+// Package jsonl은 JSON 기록을 파일에 한 줄씩 덧붙이는(append-only) 로그입니다.
+// memstore, sessionstore, profilestore, handoff가 이 로그 위에 자기 기록 형식을 얹어 씁니다.
+//
+// Open은 파일의 모든 줄을 처음부터 다시 적용(replay)한 뒤 끝에 덧붙일 준비를 합니다.
+// Append는 한 줄을 한 번의 Write로 기록하고 fsync 하므로, 줄바꿈까지 기록된 줄은 온전합니다.
+// 기록 도중 프로세스가 죽어 마지막 줄이 잘렸다면 Open이 그 줄을 버리고 파일을 온전한 줄까지 잘라냅니다.
+// 줄바꿈까지 기록된 줄이 깨졌다면 파일이 손상된 것이므로 오류를 반환합니다.
+//
+// Rewrite는 살아있는 기록만 담은 새 파일을 임시 파일로 쓴 뒤 원래 파일과 바꿔치기합니다(compaction).
+// 바꾸기 전에 죽더라도 원래 파일은 그대로 남아 있습니다.
+//
+// Log는 동시에 쓰기에 안전하지 않습니다. 호출자가 자기 잠금으로 보호해야 합니다.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Log는 JSONL 파일 하나입니다.
+type Log struct {
+	path string
+	file *os.File
+}
+
+// Open은 path의 파일을 열고(없으면 디렉터리까지 만들고) 모든 줄을 T로 읽어 apply에 넘깁니다.
+// apply가 오류를 반환하면 그 줄이 손상된 것으로 보고 파일을 닫은 뒤 오류를 반환합니다.
+func Open[T any](path string, apply func(T) error) (*Log, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create dir for %s: %w", path, err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	valid, err := replay(f, path, apply)
+	if err == nil {
+		if err = f.Truncate(valid); err != nil {
+			err = fmt.Errorf("failed to repair %s: %w", path, err)
+		}
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekEnd)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Log{path: path, file: f}, nil
+}
+
+// Replay는 path의 파일을 읽기 전용으로 열어 모든 줄을 T로 읽어 apply에 넘깁니다.
+// 파일을 바꾸지 않으므로, 다른 프로세스가 기록 중인 파일을 내보내거나 다시 돌려볼 때 씁니다.
+// 잘린 마지막 줄은 Open과 같이 건너뜁니다.
+func Replay[T any](path string, apply func(T) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	_, err = replay(f, path, apply)
+	return err
+}
+
+// replay는 r의 모든 줄을 apply에 넘기고, 마지막으로 온전하게 읽힌 줄이 끝나는 위치를 반환합니다.
+func replay[T any](r io.Reader, path string, apply func(T) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var valid int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				log.Printf("[jsonl] dropping incomplete last record in %s", path)
+			}
+			return valid, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		var rec T
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return 0, fmt.Errorf("corrupted %s at offset %d: %w", path, valid, err)
+		}
+		if err := apply(rec); err != nil {
+			return 0, fmt.Errorf("corrupted %s at offset %d: %w", path, valid, err)
+		}
+		valid += int64(len(line))
+	}
+}
+
+// Path는 파일 경로입니다.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append는 rec을 JSON 한 줄로 파일 끝에 기록하고 fsync 합니다.
+func (l *Log) Append(rec any) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	// 한 번의 Write로 줄 전체를 기록합니다. 도중에 죽으면 Open이 잘린 줄을 정리합니다.
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", l.path, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Rewrite는 write가 enc로 쓴 기록들만 담은 새 파일로 원래 파일을 바꿉니다. 이후 Append는 새 파일에 덧붙입니다.
+// 임시 파일에 다 쓰고 fsync 한 뒤에 이름을 바꾸므로, 도중에 실패하면 원래 파일이 그대로 남습니다.
+// 새 파일은 원래 파일의 권한을 그대로 물려받고, 이름을 바꾼 뒤에는 디렉터리도 fsync 해서 바꿔치기가 디스크에 남게 합니다.
+func (l *Log) Rewrite(write func(enc *json.Encoder) error) error {
+	info, err := l.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", l.path, err)
+	}
+	dir := filepath.Dir(l.path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(l.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp는 0600으로 만들므로 원래 권한으로 되돌립니다.
+	err = tmp.Chmod(info.Mode().Perm())
+	if err == nil {
+		w := bufio.NewWriter(tmp)
+		err = write(json.NewEncoder(w))
+		if err == nil {
+			err = w.Flush()
+		}
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), l.path)
+	}
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to rewrite %s: %w", l.path, err)
+	}
+
+	// 이름을 바꾼 뒤에는 새 파일이 l.path이므로, 아래에서 실패하더라도 새 파일에 이어 씁니다.
+	old := l.file
+	l.file = tmp
+	var errs []error
+	if err := old.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close replaced %s: %w", l.path, err))
+	}
+	if err := syncDir(dir); err != nil {
+		errs = append(errs, fmt.Errorf("failed to sync dir of %s: %w", l.path, err))
+	}
+	if _, err := tmp.Seek(0, io.SeekEnd); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// syncDir는 디렉터리를 fsync 해서 그 안의 이름 바꾸기를 디스크에 남깁니다.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Close는 파일을 닫습니다.
+func (l *Log) Close() error {
+	return l.file.Close()
+}
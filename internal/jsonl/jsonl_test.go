@@ -0,0 +1,138 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type rec struct {
+	N int `json:"n"`
+}
+
+// collect는 Open이 다시 적용한 기록들을 모읍니다.
+func collect(got *[]int) func(rec) error {
+	return func(r rec) error {
+		*got = append(*got, r.N)
+		return nil
+	}
+}
+
+func TestOpenRepairsIncompleteLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte("{\"n\":1}\n{\"n\":2}\n{\"n\":"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int
+	l, err := Open(path, collect(&got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("replayed %v, want [1 2]", got)
+	}
+	if err := l.Append(rec{N: 3}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	// 잘린 줄은 잘려 나가고, 새 기록은 그 자리에 이어 붙습니다.
+	data, _ := os.ReadFile(path)
+	if want := "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+}
+
+func TestOpenRejectsCorruptedLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		apply func(rec) error
+	}{
+		{"invalid JSON", "{\"n\":1}\nnot json\n{\"n\":2}\n", func(rec) error { return nil }},
+		{"rejected by apply", "{\"n\":1}\n{\"n\":-1}\n", func(r rec) error {
+			if r.N < 0 {
+				return errors.New("negative")
+			}
+			return nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "log.jsonl")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Open(path, tt.apply)
+			if err == nil || !strings.Contains(err.Error(), "corrupted") || !strings.Contains(err.Error(), "offset 8") {
+				t.Errorf("Open = %v, want corruption at offset 8", err)
+			}
+			// 손상된 파일은 건드리지 않습니다.
+			if data, _ := os.ReadFile(path); string(data) != tt.data {
+				t.Errorf("file changed to %q", data)
+			}
+		})
+	}
+}
+
+func TestRewriteReplacesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	var got []int
+	l, err := Open(path, collect(&got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range 3 {
+		if err := l.Append(rec{N: n}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Rewrite(func(enc *json.Encoder) error { return enc.Encode(rec{N: 9}) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Append(rec{N: 10}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	got = nil
+	if err := Replay(path, collect(&got)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != 9 || got[1] != 10 {
+		t.Errorf("replayed %v, want [9 10]", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("dir has %d files, want only the log", len(entries))
+	}
+}
+
+func TestRewriteKeepsFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte("{\"n\":1}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// umask와 상관없이 정해진 권한에서 시작합니다.
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(path, func(rec) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Rewrite(func(enc *json.Encoder) error { return enc.Encode(rec{N: 2}) }); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0o640 {
+		t.Errorf("mode after rewrite = %v, want %v", got, os.FileMode(0o640))
+	}
+}
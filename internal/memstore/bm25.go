@@ -0,0 +1,120 @@
+package memstore
+
+import (
+	"math"
+	"sort"
+)
+
+// BM25 파라미터 (일반적으로 쓰는 기본값)
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type doc struct {
+	entry  entry
+	length int
+}
+
+// bm25Index는 한 사용자(app, user)의 기억에 대한 역색인(inverted index)입니다.
+// term → (문서 ID → 문서 안에서의 등장 횟수)를 보관하고, 질의가 오면 BM25 점수로 순위를 매깁니다.
+type bm25Index struct {
+	docs     map[int]*doc
+	postings map[string]map[int]int
+	totalLen int
+	nextID   int
+}
+
+func newBM25Index() *bm25Index {
+	return &bm25Index{docs: make(map[int]*doc), postings: make(map[string]map[int]int)}
+}
+
+// add는 기억 한 건을 색인하고 문서 ID를 반환합니다.
+func (x *bm25Index) add(e entry) int {
+	id := x.nextID
+	x.nextID++
+
+	terms := tokenize(entryText(e.Content))
+	for _, t := range terms {
+		p, ok := x.postings[t]
+		if !ok {
+			p = make(map[int]int)
+			x.postings[t] = p
+		}
+		p[id]++
+	}
+	x.docs[id] = &doc{entry: e, length: len(terms)}
+	x.totalLen += len(terms)
+	return id
+}
+
+func (x *bm25Index) remove(id int) {
+	d, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, t := range tokenize(entryText(d.entry.Content)) {
+		if p, ok := x.postings[t]; ok {
+			delete(p, id)
+			if len(p) == 0 {
+				delete(x.postings, t)
+			}
+		}
+	}
+	x.totalLen -= d.length
+	delete(x.docs, id)
+}
+
+type hit struct {
+	id    int
+	score float64
+}
+
+// search는 질의 term들로 BM25 점수를 계산해 점수가 높은 순으로 반환합니다.
+// 점수가 minScore보다 낮은 문서는 빼고, topK가 0보다 크면 그 개수까지만 반환합니다.
+func (x *bm25Index) search(query string, topK int, minScore float64) []hit {
+	n := float64(len(x.docs))
+	if n == 0 {
+		return nil
+	}
+	avgLen := float64(x.totalLen) / n
+
+	scores := make(map[int]float64)
+	seen := make(map[string]bool)
+	for _, t := range tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		p := x.postings[t]
+		if len(p) == 0 {
+			continue
+		}
+		df := float64(len(p))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range p {
+			f := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(x.docs[id].length)/avgLen
+			scores[id] += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+	}
+
+	hits := make([]hit, 0, len(scores))
+	for id, s := range scores {
+		if s > 0 && s >= minScore {
+			hits = append(hits, hit{id: id, score: s})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		// 점수가 같으면 최근 기억을 먼저 보여줍니다.
+		return x.docs[hits[i].id].entry.Timestamp.After(x.docs[hits[j].id].entry.Timestamp)
+	})
+	if topK > 0 && len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits
+}
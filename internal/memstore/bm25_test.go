@@ -0,0 +1,173 @@
+package memstore
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/genai"
+)
+
+// newTestIndex는 texts를 차례로 색인합니다. 뒤에 있는 글일수록 최근 기억입니다.
+func newTestIndex(texts ...string) *bm25Index {
+	x := newBM25Index()
+	for i, text := range texts {
+		x.add(entry{
+			Content:   genai.NewContentFromText(text, genai.RoleUser),
+			Timestamp: time.Unix(1_700_000_000+int64(i), 0),
+		})
+	}
+	return x
+}
+
+func hitIDs(hits []hit) []int {
+	ids := make([]int, len(hits))
+	for i, h := range hits {
+		ids[i] = h.id
+	}
+	return ids
+}
+
+func TestBM25SingleDocumentScore(t *testing.T) {
+	x := newTestIndex("고양이 강아지")
+	hits := x.search("강아지", 0, 0)
+	if len(hits) != 1 {
+		t.Fatalf("hits = %v, want 1", hits)
+	}
+	// 문서가 하나뿐이어도 IDF가 0이나 음수가 되지 않아야 검색됩니다: ln(1 + 0.5/1.5)
+	// 문서 길이가 평균과 같고 tf가 1이면 점수는 IDF와 같습니다.
+	if want := math.Log(1 + 0.5/1.5); math.Abs(hits[0].score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", hits[0].score, want)
+	}
+}
+
+func TestBM25Ranking(t *testing.T) {
+	tests := []struct {
+		name  string
+		docs  []string
+		query string
+		want  []int
+	}{
+		{
+			name:  "드문 단어가 더 중요함",
+			docs:  []string{"서울 날씨", "서울 맛집", "부산 맛집"},
+			query: "부산 맛집",
+			want:  []int{2, 1},
+		},
+		{
+			name:  "여러 번 나오면 점수가 오름",
+			docs:  []string{"커피 차 물", "커피 커피 물"},
+			query: "커피",
+			want:  []int{1, 0},
+		},
+		{
+			name:  "짧은 문서가 더 관련 있음",
+			docs:  []string{"여행 계획 일정 예산 숙소 항공", "여행 계획"},
+			query: "예산 여행",
+			want:  []int{0, 1},
+		},
+		{
+			name:  "점수가 같으면 최근 기억이 먼저",
+			docs:  []string{"떡볶이 맛집", "떡볶이 맛집"},
+			query: "떡볶이",
+			want:  []int{1, 0},
+		},
+		{
+			name:  "맞는 단어가 없으면 결과 없음",
+			docs:  []string{"서울 날씨"},
+			query: "부산",
+			want:  []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitIDs(newTestIndex(tt.docs...).search(tt.query, 0, 0))
+			if len(got) != len(tt.want) {
+				t.Fatalf("ranking = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ranking = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// 여러 번 나와도 점수는 두 배가 되지 않습니다(tf 포화).
+	hits := newTestIndex("커피 차", "커피 커피").search("커피", 0, 0)
+	if hits[0].score >= 2*hits[1].score {
+		t.Errorf("tf does not saturate: %v", hits)
+	}
+}
+
+func TestBM25MinScoreAndTopK(t *testing.T) {
+	x := newTestIndex("부산 맛집", "서울 맛집", "서울 날씨", "부산 바다")
+	all := x.search("부산 맛집", 0, 0)
+	if len(all) != 3 {
+		t.Fatalf("hits = %v, want 3", all)
+	}
+
+	// 기준과 같은 점수는 남고, 그보다 낮은 것만 빠집니다.
+	if got := x.search("부산 맛집", 0, all[2].score); len(got) != 3 {
+		t.Errorf("minScore equal to the lowest score: %v, want all of %v", got, all)
+	}
+	between := (all[0].score + all[1].score) / 2
+	if got := x.search("부산 맛집", 0, between); len(got) != 1 || got[0].id != all[0].id {
+		t.Errorf("minScore %v: %v, want only the top of %v", between, got, all)
+	}
+	if got := x.search("부산 맛집", 0, all[0].score+1); len(got) != 0 {
+		t.Errorf("minScore above every score: %v", got)
+	}
+	if got := x.search("부산 맛집", 1, 0); len(got) != 1 || got[0].id != all[0].id {
+		t.Errorf("topK 1: %v", got)
+	}
+}
+
+func TestBM25Remove(t *testing.T) {
+	x := newTestIndex("부산 맛집", "서울 맛집")
+	x.remove(0)
+	if got := hitIDs(x.search("부산", 0, 0)); len(got) != 0 {
+		t.Errorf("removed document is still found: %v", got)
+	}
+	if _, ok := x.postings["부산"]; ok {
+		t.Error("empty posting list was not deleted")
+	}
+	if x.totalLen != x.docs[1].length {
+		t.Errorf("totalLen = %d, want %d", x.totalLen, x.docs[1].length)
+	}
+}
+
+// 요청의 예: "내 이름이 뭐였지?"로 찾으면 이름을 말한 기억이 가장 먼저 나와야 합니다.
+func TestSearchRanksNameMemoryFirst(t *testing.T) {
+	s := NewInMemory()
+	g := newGrowingSession(t, testUser)
+	sess := g.addAt(time.Time{},
+		"제 이름은 민수입니다",
+		"오늘 날씨가 정말 좋네요",
+		"저는 떡볶이를 좋아해요",
+		"이름 모를 꽃이 피었어요",
+		"주말에 부산에 갔어요",
+	)
+	if err := s.AddSession(t.Context(), sess); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.SearchScored(t.Context(), &memory.SearchRequest{AppName: testApp, UserID: testUser, Query: "내 이름이 뭐였지?"}, SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) == 0 || !strings.Contains(entryText(res[0].Content), "민수") {
+		t.Fatalf("results = %v, want the name memory first", res)
+	}
+	// 날씨, 떡볶이, 부산 기억은 질의와 겹치는 단어가 없으므로 나오지 않습니다.
+	if len(res) != 2 {
+		t.Errorf("got %d results, want 2", len(res))
+	}
+	for i := 1; i < len(res); i++ {
+		if res[i].Score > res[i-1].Score {
+			t.Errorf("results are not sorted by score: %v", res)
+		}
+	}
+}
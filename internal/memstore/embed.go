@@ -0,0 +1,156 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"google.golang.org/genai"
+)
+
+// Embedder는 텍스트를 벡터로 바꿉니다. 뜻이 비슷한 텍스트일수록 벡터의 코사인 유사도가 높아야 합니다.
+type Embedder interface {
+	// Name은 모델을 구분하는 이름입니다. 이름이 다른 Embedder의 벡터끼리는 비교할 수 없습니다.
+	Name() string
+	// EmbedDocuments는 저장할 기억들을 벡터로 바꿉니다.
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedQuery는 검색어를 벡터로 바꿉니다.
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// --- Gemini 임베딩 ---
+
+// Gemini 임베딩 API가 한 번에 받는 최대 텍스트 수
+const geminiEmbedBatch = 100
+
+// GeminiEmbedder는 Gemini 임베딩 모델을 사용합니다. 다국어를 지원하므로 "what do I like to eat"과 "나는 떡볶이를 좋아해"처럼
+// 단어가 전혀 겹치지 않아도 뜻이 비슷하면 찾아냅니다.
+type GeminiEmbedder struct {
+	client *genai.Client
+	model  string
+}
+
+// NewGeminiEmbedder는 model(예: "gemini-embedding-001")을 쓰는 Embedder를 만듭니다.
+func NewGeminiEmbedder(ctx context.Context, model string, cfg *genai.ClientConfig) (*GeminiEmbedder, error) {
+	client, err := genai.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create genai client: %w", err)
+	}
+	return &GeminiEmbedder{client: client, model: model}, nil
+}
+
+func (e *GeminiEmbedder) Name() string { return "gemini/" + e.model }
+
+func (e *GeminiEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.embed(ctx, texts, "RETRIEVAL_DOCUMENT")
+}
+
+func (e *GeminiEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := e.embed(ctx, []string{text}, "RETRIEVAL_QUERY")
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+func (e *GeminiEmbedder) embed(ctx context.Context, texts []string, taskType string) ([][]float32, error) {
+	vecs := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiEmbedBatch {
+		batch := texts[start:min(start+geminiEmbedBatch, len(texts))]
+		contents := make([]*genai.Content, 0, len(batch))
+		for _, t := range batch {
+			contents = append(contents, genai.NewContentFromText(t, genai.RoleUser))
+		}
+		resp, err := e.client.Models.EmbedContent(ctx, e.model, contents, &genai.EmbedContentConfig{TaskType: taskType})
+		if err != nil {
+			return nil, fmt.Errorf("embedding request failed: %w", err)
+		}
+		if len(resp.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("embedding response has %d vectors for %d texts", len(resp.Embeddings), len(batch))
+		}
+		for _, emb := range resp.Embeddings {
+			vecs = append(vecs, normalize(emb.Values))
+		}
+	}
+	return vecs, nil
+}
+
+// --- 해싱 임베딩 (오프라인) ---
+
+// HashingEmbedder는 네트워크 없이 동작하는 결정적(deterministic) 임베딩입니다.
+// 토큰과 글자 3-gram을 해시해서 고정 크기 벡터에 더하는 방식(feature hashing)이라 뜻을 이해하지는 못하지만,
+// 철자가 비슷한 단어("떡볶이"/"떡볶이를")끼리는 가깝게 만듭니다. 테스트나 API 키가 없는 환경에서 씁니다.
+type HashingEmbedder struct {
+	Dim int
+}
+
+// NewHashingEmbedder는 dim 차원의 HashingEmbedder를 만듭니다.
+func NewHashingEmbedder(dim int) *HashingEmbedder {
+	return &HashingEmbedder{Dim: dim}
+}
+
+func (e *HashingEmbedder) Name() string { return fmt.Sprintf("hashing/%d", e.Dim) }
+
+func (e *HashingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs := make([][]float32, len(texts))
+	for i, t := range texts {
+		vecs[i] = e.vector(t)
+	}
+	return vecs, nil
+}
+
+func (e *HashingEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.vector(text), nil
+}
+
+func (e *HashingEmbedder) vector(text string) []float32 {
+	vec := make([]float32, e.Dim)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		// 위쪽 비트로 부호를 정해 해시 충돌이 서로 상쇄되도록 합니다.
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vec[sum%uint64(e.Dim)] += sign * weight
+	}
+	for _, term := range tokenize(text) {
+		add("t:"+term, 1)
+		runes := []rune(term)
+		for i := 0; i+3 <= len(runes); i++ {
+			add("g:"+string(runes[i:i+3]), 0.5)
+		}
+	}
+	return normalize(vec)
+}
+
+// normalize는 벡터를 길이 1로 만듭니다. 그러면 내적이 곧 코사인 유사도가 됩니다.
+func normalize(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vec
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = v / norm
+	}
+	return out
+}
+
+func dot(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var s float32
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
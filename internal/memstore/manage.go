@@ -0,0 +1,145 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/adk/memory"
+)
+
+// DeletableService는 저장된 기억을 보고 지울 수 있는 memory.Service입니다.
+type DeletableService interface {
+	memory.Service
+	// List는 사용자의 모든 기억을 오래된 것부터 반환합니다.
+	List(ctx context.Context, appName, userID string) ([]StoredEntry, error)
+	// Delete는 ID가 ids인 기억을 지우고 지운 개수를 반환합니다.
+	Delete(ctx context.Context, appName, userID string, ids ...string) (int, error)
+	// DeleteAll은 사용자의 모든 기억을 지우고 지운 개수를 반환합니다.
+	DeleteAll(ctx context.Context, appName, userID string) (int, error)
+}
+
+var _ DeletableService = (*Service)(nil)
+
+var (
+	// ErrNotFound는 그런 ID의 기억이 없을 때 반환됩니다.
+	ErrNotFound = errors.New("memory not found")
+	// ErrAmbiguousID는 ID 앞부분에 맞는 기억이 여러 개일 때 반환됩니다.
+	ErrAmbiguousID = errors.New("memory ID prefix matches more than one memory")
+)
+
+func storedEntry(e entry) StoredEntry {
+	return StoredEntry{
+		Entry:     memory.Entry{Content: e.Content, Author: e.Author, Timestamp: e.Timestamp},
+		ID:        e.EventID,
+		SessionID: e.SessionID,
+	}
+}
+
+func (s *Service) List(ctx context.Context, appName, userID string) ([]StoredEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	um, ok := s.store[key{appName: appName, userID: userID}]
+	if !ok {
+		return nil, nil
+	}
+	res := make([]StoredEntry, 0, len(um.index.docs))
+	for _, d := range um.index.docs {
+		res = append(res, storedEntry(d.entry))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Timestamp.Equal(res[j].Timestamp) {
+			return res[i].Timestamp.Before(res[j].Timestamp)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+// Resolve는 ID 앞부분(prefix)에 맞는 기억의 전체 ID를 찾습니다. 목록에서 줄여 보여준 ID로 지울 때 씁니다.
+func (s *Service) Resolve(appName, userID, prefix string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	um, ok := s.store[key{appName: appName, userID: userID}]
+	if !ok || prefix == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, prefix)
+	}
+	if _, ok := um.byID[prefix]; ok {
+		return prefix, nil
+	}
+	var found string
+	for id := range um.byID {
+		if strings.HasPrefix(id, prefix) {
+			if found != "" {
+				return "", fmt.Errorf("%w: %s", ErrAmbiguousID, prefix)
+			}
+			found = id
+		}
+	}
+	if found == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, prefix)
+	}
+	return found, nil
+}
+
+// Delete는 기억을 지웁니다. 지운 기억의 이벤트는 같은 세션을 다시 AddSession 해도 색인되지 않습니다.
+// ids 중 하나라도 없는 ID가 있으면 아무것도 지우지 않고 ErrNotFound를 반환합니다.
+// 파일에 기록하는 경우 파일을 다시 써서 지운 기억의 내용을 디스크에서도 없앱니다.
+func (s *Service) Delete(ctx context.Context, appName, userID string, ids ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	um, ok := s.store[key{appName: appName, userID: userID}]
+	for _, id := range ids {
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if _, exists := um.byID[id]; !exists {
+			return 0, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := s.writeLocked(record{Op: opDelete, AppName: appName, UserID: userID, Entries: []entry{}, Deleted: ids, SavedAt: s.now()}); err != nil {
+		return 0, err
+	}
+	return len(ids), s.purgeLocked()
+}
+
+// DeleteAll은 Delete처럼 지운 뒤 파일을 다시 씁니다.
+func (s *Service) DeleteAll(ctx context.Context, appName, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	um, ok := s.store[key{appName: appName, userID: userID}]
+	if !ok || len(um.byID) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, 0, len(um.byID))
+	for id := range um.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if err := s.writeLocked(record{Op: opDelete, AppName: appName, UserID: userID, Entries: []entry{}, Deleted: ids, SavedAt: s.now()}); err != nil {
+		return 0, err
+	}
+	return len(ids), s.purgeLocked()
+}
+
+// purgeLocked는 지운 기억의 내용이 파일에 남지 않도록 살아있는 기억만으로 파일을 다시 씁니다. s.mu를 잡은 상태에서 호출해야 합니다.
+// 지운 기록(opDelete)만 덧붙이면 원래 대화 내용이 파일에 그대로 남기 때문입니다.
+// 다시 쓰기에 실패해도 기억은 이미 지워진 상태이고, 내용은 다음 정리(Open, StartRetention) 때 파일에서 사라집니다.
+func (s *Service) purgeLocked() error {
+	if s.file == nil {
+		return nil
+	}
+	if err := s.compact(); err != nil {
+		return fmt.Errorf("memories were deleted but remain in the memory file until the next compaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,662 @@
+// Package memstore는 BM25로 순위를 매겨 검색하는 memory.Service 구현입니다.
+// WithVectorSearch 옵션을 주면 임베딩 벡터로 뜻이 비슷한 기억을 찾는 검색과, 두 점수를 섞는 하이브리드 검색도 할 수 있습니다.
+//
+// NewInMemory는 메모리에만 기억을 보관하고, Open은 JSONL 파일에 기록해서 프로세스를 다시 시작해도 기억이 남습니다.
+// 파일에는 한 줄씩 덧붙여(append-only) 기록합니다. 각 줄은 세션에 새로 추가된 기억들입니다.
+//
+// AddSession은 세션마다 마지막으로 색인한 이벤트를 기억해 두고, 그 뒤에 새로 생긴 이벤트만 색인합니다.
+// 이미 색인한 이벤트 ID는 다시 색인하지 않으므로 같은 세션을 몇 번 넘겨도 기억이 중복되지 않습니다.
+// Search(memory.Service)는 사용자별 역색인에서 BM25 점수가 높은 기억부터 돌려주고,
+// SearchScored는 검색 방식(BM25/벡터/하이브리드)과 점수 기준을 골라 점수와 함께 돌려줍니다.
+package memstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/jsonl"
+)
+
+// DefaultTopK는 memory.Service의 Search가 돌려주는 최대 기억 수입니다.
+const DefaultTopK = 10
+
+// 파일에 기록되는 한 줄
+type record struct {
+	// Op가 opAppend면 Entries를 세션의 기억에 더하고, opReplace(또는 비어 있으면)면 세션의 기억을 Entries로 바꿉니다.
+	// opDelete면 ID가 Deleted에 있는 기억을 지웁니다(SessionID는 비어 있습니다).
+	Op        string   `json:"op,omitempty"`
+	AppName   string   `json:"appName"`
+	UserID    string   `json:"userId"`
+	SessionID string   `json:"sessionId"`
+	Entries   []entry  `json:"entries"`
+	Deleted   []string `json:"deleted,omitempty"`
+	// Forgotten은 지워진 기억의 ID입니다. 파일을 정리할 때 기록해서, 지운 이벤트가 다시 색인되지 않게 합니다.
+	Forgotten []string  `json:"forgotten,omitempty"`
+	SavedAt   time.Time `json:"savedAt"`
+}
+
+const (
+	opReplace = "replace"
+	opAppend  = "append"
+	opDelete  = "delete"
+)
+
+type entry struct {
+	// EventID는 기억이 된 세션 이벤트의 ID이자 기억의 ID입니다. 같은 이벤트를 두 번 색인하지 않는 데 씁니다.
+	// 예전 형식의 기억에는 없으므로, 읽을 때 내용으로 legacyID를 만들어 채웁니다.
+	EventID   string         `json:"eventId,omitempty"`
+	Content   *genai.Content `json:"content"`
+	Author    string         `json:"author"`
+	Timestamp time.Time      `json:"timestamp"`
+	SessionID string         `json:"-"`
+
+	// 임베딩 벡터와 그 벡터를 만든 모델 이름 (벡터 검색을 켠 경우에만)
+	Embedding      []float32 `json:"embedding,omitempty"`
+	EmbeddingModel string    `json:"embeddingModel,omitempty"`
+
+	// LastRetrieved는 마지막으로 검색 결과에 나간 시각입니다. 파일을 정리할 때만 기록됩니다.
+	LastRetrieved time.Time `json:"lastRetrieved,omitzero"`
+}
+
+type key struct {
+	appName, userID string
+}
+
+// userMemory는 한 사용자의 기억 색인과, 세션마다 어디까지 색인했는지를 보관합니다.
+// vectors는 벡터 검색을 켠 경우에만 있으며 index와 같은 문서 ID를 씁니다.
+type userMemory struct {
+	index    *bm25Index
+	vectors  VectorIndex
+	sessions map[string]*sessionMemory
+	byID     map[string]int // 기억 ID → 문서 ID
+	// lastUsed는 문서가 마지막으로 검색된 시각입니다(보관 정책의 LRU 기준). Service.usageMu로 보호합니다.
+	lastUsed map[int]time.Time
+}
+
+// sessionMemory는 세션 하나의 색인 상태입니다.
+type sessionMemory struct {
+	docs     []int
+	eventIDs map[string]bool
+	// forgotten은 사용자가 지운 기억의 ID입니다. eventIDs에도 남아 있어 다시 색인되지 않습니다.
+	forgotten map[string]bool
+	// 마지막으로 색인한 이벤트 (다음 AddSession은 이 이후의 이벤트만 보면 됩니다)
+	lastEventID   string
+	lastEventTime time.Time
+	// legacy는 이벤트 ID 없이 저장된(예전 형식) 기억이 있다는 뜻입니다. 다음 저장 때 세션 전체를 다시 색인합니다.
+	legacy bool
+}
+
+// SearchMode는 검색 방식입니다.
+type SearchMode string
+
+const (
+	// ModeBM25는 단어가 겹치는 정도(BM25)로만 검색합니다. 점수는 0 이상이며 상한이 없습니다.
+	ModeBM25 SearchMode = "bm25"
+	// ModeVector는 임베딩의 코사인 유사도(-1~1)로만 검색합니다.
+	ModeVector SearchMode = "vector"
+	// ModeHybrid는 두 점수를 섞습니다: Alpha × 코사인 유사도 + (1 - Alpha) × (BM25 / 최고 BM25). 점수는 0~1입니다.
+	ModeHybrid SearchMode = "hybrid"
+)
+
+// DefaultAlpha는 하이브리드 검색에서 벡터 점수의 기본 비중입니다.
+const DefaultAlpha = 0.5
+
+// StoredEntry는 저장된 기억 한 건입니다. ID로 기억을 지울 수 있습니다.
+type StoredEntry struct {
+	memory.Entry
+	ID        string
+	SessionID string
+}
+
+// ScoredEntry는 검색된 기억 한 건과 그 점수입니다.
+type ScoredEntry struct {
+	StoredEntry
+	Score float64
+}
+
+// SearchOptions는 SearchScored의 검색 조건입니다.
+type SearchOptions struct {
+	// TopK는 돌려줄 최대 기억 수입니다. 0 이하면 제한하지 않습니다.
+	TopK int
+	// MinScore보다 점수가 낮은 기억은 관련 없는 것으로 보고 버립니다. 점수의 범위는 Mode마다 다릅니다.
+	MinScore float64
+	// Mode가 비어 있으면 ModeBM25로 검색합니다.
+	Mode SearchMode
+	// Alpha는 ModeHybrid에서 벡터 점수의 비중(0~1)입니다. 0이면 DefaultAlpha를 씁니다.
+	Alpha float64
+}
+
+// Service는 BM25로 검색하는 memory.Service입니다. 여러 고루틴에서 동시에 써도 안전합니다.
+type Service struct {
+	mu     sync.RWMutex
+	file   *jsonl.Log
+	closed bool
+	store  map[key]*userMemory
+	// 교체되어 더 이상 쓰이지 않는 줄 수 (파일 정리 기준)
+	superseded int
+
+	embedder Embedder
+	newIndex func() VectorIndex
+
+	now       func() time.Time
+	retention RetentionPolicy
+	// 검색은 읽기 잠금만 잡으므로, 마지막 사용 시각은 따로 보호합니다.
+	usageMu sync.Mutex
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+var _ memory.Service = (*Service)(nil)
+
+// Option은 Service 생성 옵션입니다.
+type Option func(*Service)
+
+// WithVectorSearch는 기억을 저장할 때 embedder로 벡터를 만들어 newIndex가 만든 색인(사용자마다 하나)에 넣습니다.
+// 그러면 ModeVector와 ModeHybrid로 검색할 수 있습니다.
+func WithVectorSearch(embedder Embedder, newIndex func() VectorIndex) Option {
+	return func(s *Service) {
+		s.embedder = embedder
+		s.newIndex = newIndex
+	}
+}
+
+// NewInMemory는 파일에 기록하지 않는 Service를 만듭니다. 프로세스가 끝나면 기억도 사라집니다.
+func NewInMemory(opts ...Option) *Service {
+	s := &Service{store: make(map[key]*userMemory), now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Open은 path의 기억 파일을 읽어 Service를 만듭니다. 파일이 없으면 새로 만듭니다.
+//
+// 기록 도중 프로세스가 죽어 마지막 줄이 잘렸다면 그 줄은 버리고 파일을 온전한 줄까지 잘라냅니다.
+// 교체되어 더 이상 쓰이지 않는 줄이 세션 수보다 많으면 파일을 다시 써서 크기를 줄입니다.
+// 벡터 검색을 켰는데 벡터가 없거나 다른 모델로 만든 기억이 있으면, 이때 새로 벡터를 만들어 파일에 반영합니다.
+func Open(path string, opts ...Option) (*Service, error) {
+	s := &Service{store: make(map[key]*userMemory), now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	f, err := jsonl.Open(path, func(rec record) error {
+		s.apply(rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory file: %w", err)
+	}
+	s.file = f
+
+	embedded, err := s.embedMissing(context.Background())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if s.superseded > s.sessionCount() || embedded > 0 {
+		if err := s.compact(); err != nil {
+			log.Printf("[memstore] compaction failed: %v", err)
+		}
+	}
+	return s, nil
+}
+
+// apply는 기록 한 줄을 색인에 반영합니다. 이미 색인된 이벤트 ID의 기억은 건너뜁니다.
+func (s *Service) apply(rec record) {
+	k := key{appName: rec.AppName, userID: rec.UserID}
+	um, ok := s.store[k]
+	if !ok {
+		um = &userMemory{
+			index:    newBM25Index(),
+			sessions: make(map[string]*sessionMemory),
+			byID:     make(map[string]int),
+			lastUsed: make(map[int]time.Time),
+		}
+		if s.newIndex != nil {
+			um.vectors = s.newIndex()
+		}
+		s.store[k] = um
+	}
+	if rec.Op == opDelete {
+		s.deleteEntries(um, rec.Deleted)
+		s.superseded++
+		return
+	}
+
+	sm, ok := um.sessions[rec.SessionID]
+	if !ok || rec.Op != opAppend {
+		// 세션을 교체해도 사용자가 지운 기억은 계속 지운 상태로 둡니다.
+		next := &sessionMemory{eventIDs: make(map[string]bool), forgotten: make(map[string]bool)}
+		if ok {
+			for _, id := range sm.docs {
+				s.removeDoc(um, id)
+			}
+			for id := range sm.forgotten {
+				next.eventIDs[id], next.forgotten[id] = true, true
+			}
+			s.superseded++
+		}
+		sm = next
+		um.sessions[rec.SessionID] = sm
+	}
+	for _, id := range rec.Forgotten {
+		sm.eventIDs[id], sm.forgotten[id] = true, true
+	}
+
+	for _, e := range rec.Entries {
+		e.SessionID = rec.SessionID
+		if e.EventID == "" {
+			e.EventID = legacyID(rec.SessionID, e.Author, e.Timestamp, entryText(e.Content))
+		}
+		if sm.eventIDs[e.EventID] {
+			continue
+		}
+		id := um.index.add(e)
+		if um.vectors != nil && s.hasEmbedding(e) {
+			um.vectors.Add(id, e.Embedding)
+		}
+		sm.docs = append(sm.docs, id)
+		sm.eventIDs[e.EventID] = true
+		um.byID[e.EventID] = id
+		if !e.LastRetrieved.IsZero() {
+			um.lastUsed[id] = e.LastRetrieved
+		}
+
+		if strings.HasPrefix(e.EventID, legacyPrefix) {
+			sm.legacy = true
+			continue
+		}
+		if !e.Timestamp.Before(sm.lastEventTime) {
+			sm.lastEventID, sm.lastEventTime = e.EventID, e.Timestamp
+		}
+	}
+}
+
+// deleteEntries는 ID가 ids에 있는 기억을 색인에서 빼고, 세션에 지운 기억으로 표시합니다.
+func (s *Service) deleteEntries(um *userMemory, ids []string) {
+	for _, memID := range ids {
+		id, ok := um.byID[memID]
+		if !ok {
+			continue
+		}
+		sm := um.sessions[um.index.docs[id].entry.SessionID]
+		s.removeDoc(um, id)
+		sm.docs = slices.DeleteFunc(sm.docs, func(d int) bool { return d == id })
+		sm.forgotten[memID] = true
+	}
+}
+
+func (s *Service) removeDoc(um *userMemory, id int) {
+	if d, ok := um.index.docs[id]; ok {
+		delete(um.byID, d.entry.EventID)
+	}
+	delete(um.lastUsed, id)
+	um.index.remove(id)
+	if um.vectors != nil {
+		um.vectors.Remove(id)
+	}
+}
+
+// 예전 형식(이벤트 ID 없음) 기억의 ID 접두어
+const legacyPrefix = "legacy-"
+
+// legacyID는 이벤트 ID 없이 저장된 기억에 내용으로 ID를 만들어 줍니다. 같은 내용이면 항상 같은 ID가 나옵니다.
+func legacyID(sessionID, author string, ts time.Time, text string) string {
+	h := fnv.New32a()
+	for _, part := range []string{sessionID, author, ts.UTC().Format(time.RFC3339Nano), text} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s%08x", legacyPrefix, h.Sum32())
+}
+
+func (s *Service) hasEmbedding(e entry) bool {
+	return s.embedder != nil && len(e.Embedding) > 0 && e.EmbeddingModel == s.embedder.Name()
+}
+
+// embedMissing은 파일에서 읽은 기억 중 지금 embedder로 만든 벡터가 없는 것들에 벡터를 만들어 줍니다.
+func (s *Service) embedMissing(ctx context.Context) (int, error) {
+	if s.embedder == nil {
+		return 0, nil
+	}
+	type target struct {
+		um *userMemory
+		id int
+	}
+	var targets []target
+	var texts []string
+	for _, um := range s.store {
+		for id, d := range um.index.docs {
+			if !s.hasEmbedding(d.entry) {
+				targets = append(targets, target{um, id})
+				texts = append(texts, entryText(d.entry.Content))
+			}
+		}
+	}
+	if len(texts) == 0 {
+		return 0, nil
+	}
+	log.Printf("[memstore] embedding %d stored memories with %s", len(texts), s.embedder.Name())
+	vecs, err := s.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to embed stored memories: %w", err)
+	}
+	for i, t := range targets {
+		d := t.um.index.docs[t.id]
+		d.entry.Embedding, d.entry.EmbeddingModel = vecs[i], s.embedder.Name()
+		t.um.vectors.Add(t.id, vecs[i])
+	}
+	return len(targets), nil
+}
+
+// embedEntries는 rec의 기억들에 벡터를 붙입니다.
+func (s *Service) embedEntries(ctx context.Context, rec *record) error {
+	if s.embedder == nil || len(rec.Entries) == 0 {
+		return nil
+	}
+	texts := make([]string, len(rec.Entries))
+	for i, e := range rec.Entries {
+		texts[i] = entryText(e.Content)
+	}
+	vecs, err := s.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed memories: %w", err)
+	}
+	for i := range rec.Entries {
+		rec.Entries[i].Embedding, rec.Entries[i].EmbeddingModel = vecs[i], s.embedder.Name()
+	}
+	return nil
+}
+
+func (s *Service) sessionCount() int {
+	n := 0
+	for _, um := range s.store {
+		n += len(um.sessions)
+	}
+	return n
+}
+
+// AddSession은 세션에서 아직 색인하지 않은 텍스트 이벤트만 기억으로 저장합니다.
+// 새 이벤트가 없으면 아무것도 기록하지 않으므로, 같은 세션을 여러 번 넘겨도 안전합니다(idempotent).
+// 세션에서 이벤트가 사라져도(요약 등) 이미 저장된 기억은 지우지 않습니다.
+// 파일에 기록하는 경우 디스크에 반영(fsync)된 뒤에만 검색 결과에 나타납니다.
+func (s *Service) AddSession(ctx context.Context, curSession session.Session) error {
+	rec := record{
+		Op:        opAppend,
+		AppName:   curSession.AppName(),
+		UserID:    curSession.UserID(),
+		SessionID: curSession.ID(),
+		Entries:   []entry{},
+		SavedAt:   s.now(),
+	}
+
+	s.mu.RLock()
+	sm := s.sessionLocked(rec.AppName, rec.UserID, rec.SessionID)
+	var forgottenLegacy map[string]bool
+	if sm != nil && sm.legacy {
+		// 예전 형식(이벤트 ID 없음)의 기억은 어느 이벤트에서 왔는지 모르므로 세션 전체를 다시 색인합니다.
+		// 사용자가 지운 예전 기억과 내용이 같은 이벤트는 다시 넣지 않습니다.
+		rec.Op, forgottenLegacy, sm = opReplace, sm.forgotten, nil
+	}
+	for event := range curSession.Events().All() {
+		text := entryText(event.LLMResponse.Content)
+		if strings.TrimSpace(text) == "" || (sm != nil && sm.eventIDs[event.ID]) ||
+			forgottenLegacy[legacyID(rec.SessionID, event.Author, event.Timestamp, text)] {
+			continue
+		}
+		rec.Entries = append(rec.Entries, entry{
+			EventID:   event.ID,
+			Content:   event.LLMResponse.Content,
+			Author:    event.Author,
+			Timestamp: event.Timestamp,
+		})
+	}
+	s.mu.RUnlock()
+
+	if len(rec.Entries) == 0 && rec.Op == opAppend {
+		return nil
+	}
+
+	// 임베딩은 네트워크 호출일 수 있으므로 잠금을 잡기 전에 만듭니다.
+	if err := s.embedEntries(ctx, &rec); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 임베딩을 만드는 사이 다른 호출이 같은 이벤트를 먼저 저장했을 수 있습니다.
+	if sm := s.sessionLocked(rec.AppName, rec.UserID, rec.SessionID); sm != nil && rec.Op == opAppend {
+		rec.Entries = slices.DeleteFunc(rec.Entries, func(e entry) bool { return sm.eventIDs[e.EventID] })
+		if len(rec.Entries) == 0 {
+			return nil
+		}
+	}
+	return s.writeLocked(rec)
+}
+
+// writeLocked는 rec을 파일에 기록한 뒤 색인에 반영합니다. s.mu를 잡은 상태에서 호출해야 합니다.
+func (s *Service) writeLocked(rec record) error {
+	if s.closed {
+		return errors.New("memory store is closed")
+	}
+	if s.file != nil {
+		if err := s.file.Append(rec); err != nil {
+			return fmt.Errorf("failed to write memory: %w", err)
+		}
+	}
+	s.apply(rec)
+	return nil
+}
+
+// Cursor는 세션에서 마지막으로 색인한 이벤트의 ID와 시각을 반환합니다. 색인한 적이 없으면 ok가 false입니다.
+// 세션 서비스에서 이 시각 이후의 이벤트만 가져와(session.GetRequest.After) AddSession에 넘기면
+// 매 턴 세션 전체를 읽지 않아도 됩니다.
+func (s *Service) Cursor(appName, userID, sessionID string) (eventID string, at time.Time, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sm := s.sessionLocked(appName, userID, sessionID)
+	if sm == nil || sm.lastEventID == "" || sm.legacy {
+		return "", time.Time{}, false
+	}
+	return sm.lastEventID, sm.lastEventTime, true
+}
+
+func (s *Service) sessionLocked(appName, userID, sessionID string) *sessionMemory {
+	um, ok := s.store[key{appName: appName, userID: userID}]
+	if !ok {
+		return nil
+	}
+	return um.sessions[sessionID]
+}
+
+// Search는 BM25 점수가 높은 순으로 최대 DefaultTopK개의 기억을 반환합니다.
+func (s *Service) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
+	scored, err := s.SearchScored(ctx, req, SearchOptions{TopK: DefaultTopK})
+	if err != nil {
+		return nil, err
+	}
+	res := &memory.SearchResponse{}
+	for _, se := range scored {
+		res.Memories = append(res.Memories, se.Entry)
+	}
+	return res, nil
+}
+
+// SearchScored는 opts.Mode 방식으로 점수를 매겨 기억을 검색합니다.
+func (s *Service) SearchScored(ctx context.Context, req *memory.SearchRequest, opts SearchOptions) ([]ScoredEntry, error) {
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeBM25
+	}
+	var queryVec []float32
+	switch mode {
+	case ModeBM25:
+	case ModeVector, ModeHybrid:
+		if s.embedder == nil {
+			return nil, fmt.Errorf("%s search requires vector search to be enabled", mode)
+		}
+		var err error
+		if queryVec, err = s.embedder.EmbedQuery(ctx, req.Query); err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown search mode %q", mode)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	um, ok := s.store[key{appName: req.AppName, userID: req.UserID}]
+	if !ok {
+		return nil, nil
+	}
+
+	var hits []hit
+	switch mode {
+	case ModeBM25:
+		hits = um.index.search(req.Query, opts.TopK, opts.MinScore)
+	case ModeVector:
+		for _, vh := range um.vectors.Search(queryVec, topKOrAll(opts.TopK, um.vectors.Len())) {
+			if float64(vh.Score) >= opts.MinScore {
+				hits = append(hits, hit{id: vh.ID, score: float64(vh.Score)})
+			}
+		}
+	case ModeHybrid:
+		hits = s.hybridSearch(um, req.Query, queryVec, opts)
+	}
+
+	s.touch(um, hits)
+	var res []ScoredEntry
+	for _, h := range hits {
+		e := um.index.docs[h.id].entry
+		res = append(res, ScoredEntry{StoredEntry: storedEntry(e), Score: h.score})
+	}
+	return res, nil
+}
+
+// hybridSearch는 BM25 후보와 벡터 후보를 합친 뒤 두 점수를 섞어 다시 순위를 매깁니다.
+// BM25 점수는 상한이 없으므로 이번 검색의 최고 점수로 나눠 0~1로 맞춥니다.
+func (s *Service) hybridSearch(um *userMemory, query string, queryVec []float32, opts SearchOptions) []hit {
+	alpha := opts.Alpha
+	if alpha <= 0 {
+		alpha = DefaultAlpha
+	}
+
+	keyword := make(map[int]float64)
+	var maxKeyword float64
+	for _, h := range um.index.search(query, 0, 0) {
+		keyword[h.id] = h.score
+		maxKeyword = max(maxKeyword, h.score)
+	}
+	// 벡터 후보는 최종 개수보다 넉넉하게 가져옵니다.
+	candidates := make(map[int]bool)
+	for id := range keyword {
+		candidates[id] = true
+	}
+	for _, vh := range um.vectors.Search(queryVec, max(4*opts.TopK, 20)) {
+		candidates[vh.ID] = true
+	}
+
+	var hits []hit
+	for id := range candidates {
+		var semantic, lexical float64
+		if e := um.index.docs[id].entry; s.hasEmbedding(e) {
+			semantic = max(float64(dot(queryVec, e.Embedding)), 0)
+		}
+		if maxKeyword > 0 {
+			lexical = keyword[id] / maxKeyword
+		}
+		score := alpha*semantic + (1-alpha)*lexical
+		if score > 0 && score >= opts.MinScore {
+			hits = append(hits, hit{id: id, score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].id > hits[j].id
+	})
+	if opts.TopK > 0 && len(hits) > opts.TopK {
+		hits = hits[:opts.TopK]
+	}
+	return hits
+}
+
+func topKOrAll(k, n int) int {
+	if k > 0 {
+		return k
+	}
+	return n
+}
+
+// Close는 StartRetention의 고루틴을 멈추고 기억 파일을 닫습니다.
+func (s *Service) Close() error {
+	s.stopRetention()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// compact는 살아있는 세션만 담도록 파일을 다시 씁니다. 지운 기억의 내용은 이때 파일에서 사라집니다.
+func (s *Service) compact() error {
+	now := s.now()
+	err := s.file.Rewrite(func(enc *json.Encoder) error {
+		for k, um := range s.store {
+			for sessionID, sm := range um.sessions {
+				rec := record{Op: opReplace, AppName: k.appName, UserID: k.userID, SessionID: sessionID, Entries: []entry{}, SavedAt: now}
+				for _, id := range sm.docs {
+					e := um.index.docs[id].entry
+					e.LastRetrieved = um.lastUsed[id]
+					rec.Entries = append(rec.Entries, e)
+				}
+				rec.Forgotten = slices.Sorted(maps.Keys(sm.forgotten))
+				if err := enc.Encode(rec); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.superseded = 0
+	return nil
+}
+
+// entryText는 기억 한 건의 텍스트 파트를 이어 붙입니다.
+func entryText(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range content.Parts {
+		if part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,281 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	testApp  = "app"
+	testUser = "alice"
+)
+
+// growingSession은 이벤트가 계속 늘어나는 세션 하나입니다.
+type growingSession struct {
+	t        *testing.T
+	sessions session.Service
+	userID   string
+	id       string
+	n        int
+}
+
+func newGrowingSession(t *testing.T, userID string) *growingSession {
+	t.Helper()
+	sessions := session.InMemoryService()
+	res, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: testApp, UserID: userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &growingSession{t: t, sessions: sessions, userID: userID, id: res.Session.ID()}
+}
+
+// add는 "떡볶이 기억 N" 같은 텍스트 이벤트 n개를 세션에 더하고, 더한 뒤의 세션을 반환합니다.
+func (g *growingSession) add(n int) session.Session {
+	g.t.Helper()
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("떡볶이 기억 %d", g.n+i+1)
+	}
+	return g.addAt(time.Time{}, texts...)
+}
+
+// addAt은 texts를 이벤트로 세션에 더합니다. at이 비어 있으면 이벤트마다 1초씩 늘어나는 시각을 씁니다.
+func (g *growingSession) addAt(at time.Time, texts ...string) session.Session {
+	g.t.Helper()
+	ctx := g.t.Context()
+	res, err := g.sessions.Get(ctx, &session.GetRequest{AppName: testApp, UserID: g.userID, SessionID: g.id})
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	for _, text := range texts {
+		g.n++
+		event := session.NewEvent("inv-test")
+		event.Author = "user"
+		event.Timestamp = at
+		if at.IsZero() {
+			event.Timestamp = time.Unix(1_700_000_000+int64(g.n), 0)
+		}
+		event.LLMResponse.Content = genai.NewContentFromText(text, genai.RoleUser)
+		if err := g.sessions.AppendEvent(ctx, res.Session, event); err != nil {
+			g.t.Fatal(err)
+		}
+	}
+	res, err = g.sessions.Get(ctx, &session.GetRequest{AppName: testApp, UserID: g.userID, SessionID: g.id})
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	return res.Session
+}
+
+// assertIndexedOnce는 세션의 이벤트가 모두 한 번씩만 색인됐고 검색 결과에 같은 기억이 두 번 나오지 않는지 확인합니다.
+func assertIndexedOnce(t *testing.T, s *Service, want int) {
+	t.Helper()
+	entries, err := s.List(t.Context(), testApp, testUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != want {
+		t.Errorf("List returned %d memories, want %d", len(entries), want)
+	}
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if seen[e.ID] {
+			t.Errorf("event %s is indexed twice", e.ID)
+		}
+		seen[e.ID] = true
+	}
+
+	scored, err := s.SearchScored(t.Context(), &memory.SearchRequest{AppName: testApp, UserID: testUser, Query: "떡볶이"}, SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scored) != want {
+		t.Errorf("Search returned %d memories, want %d", len(scored), want)
+	}
+	texts := make(map[string]bool)
+	for _, se := range scored {
+		text := entryText(se.Content)
+		if texts[text] {
+			t.Errorf("Search returned %q twice", text)
+		}
+		texts[text] = true
+	}
+}
+
+func TestAddSessionIndexesEachEventOnce(t *testing.T) {
+	// 각 단계는 세션에 이벤트를 더한 뒤 AddSession을 몇 번 부르거나(add, calls), 저장소를 닫고 다시 엽니다(reopen).
+	type step struct {
+		add    int
+		calls  int
+		reopen bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"same session twice", []step{{add: 3, calls: 2}}},
+		{"growing session", []step{{add: 2, calls: 1}, {add: 3, calls: 1}, {add: 0, calls: 3}}},
+		{"reopen in the middle", []step{{add: 2, calls: 2}, {reopen: true}, {add: 0, calls: 1}, {add: 2, calls: 2}}},
+		{"reopen twice", []step{{add: 1, calls: 1}, {reopen: true}, {add: 1, calls: 1}, {reopen: true}, {add: 0, calls: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "memory.jsonl")
+			s, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { s.Close() }()
+
+			g := newGrowingSession(t, testUser)
+			for i, st := range tt.steps {
+				if st.reopen {
+					s.Close()
+					if s, err = Open(path); err != nil {
+						t.Fatalf("step %d: reopen: %v", i, err)
+					}
+				} else {
+					sess := g.add(st.add)
+					for range st.calls {
+						if err := s.AddSession(context.Background(), sess); err != nil {
+							t.Fatalf("step %d: AddSession: %v", i, err)
+						}
+					}
+				}
+				assertIndexedOnce(t, s, g.n)
+			}
+		})
+	}
+}
+
+func TestDeleteRemovesContentFromFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete func(s *Service, ids []string) (int, error)
+		left   int
+	}{
+		{"Delete", func(s *Service, ids []string) (int, error) {
+			return s.Delete(context.Background(), testApp, testUser, ids[0])
+		}, 2},
+		{"DeleteAll", func(s *Service, ids []string) (int, error) {
+			return s.DeleteAll(context.Background(), testApp, testUser)
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "memory.jsonl")
+			s, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { s.Close() }()
+			g := newGrowingSession(t, testUser)
+			sess := g.add(3)
+			if err := s.AddSession(context.Background(), sess); err != nil {
+				t.Fatal(err)
+			}
+			entries, _ := s.List(t.Context(), testApp, testUser)
+			ids := make([]string, len(entries))
+			for i, e := range entries {
+				ids[i] = e.ID
+			}
+
+			if _, err := tt.delete(s, ids); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 첫 기억("떡볶이 기억 1")은 두 경우 모두 지워졌으므로 파일 어디에도 없어야 합니다.
+			if strings.Contains(string(data), "떡볶이 기억 1\"") {
+				t.Errorf("deleted memory is still in the file:\n%s", data)
+			}
+
+			// 다시 열어도, 같은 세션을 다시 저장해도 지운 기억은 돌아오지 않습니다.
+			s.Close()
+			if s, err = Open(path); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.AddSession(context.Background(), sess); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := s.List(t.Context(), testApp, testUser); len(entries) != tt.left {
+				t.Errorf("List after reopen returned %d memories, want %d", len(entries), tt.left)
+			}
+		})
+	}
+}
+
+func TestUsersDoNotSeeEachOthersMemories(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "memory.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	// 두 사용자가 같은 단어(떡볶이)를 말해도 검색은 자기 기억에서만 합니다.
+	said := map[string][]string{
+		"alice": {"alice는 떡볶이를 좋아해", "alice의 생일은 3월"},
+		"bob":   {"bob은 떡볶이를 싫어해", "bob의 고양이는 나비"},
+	}
+	for user, texts := range said {
+		if err := s.AddSession(ctx, newGrowingSession(t, user).addAt(time.Time{}, texts...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for user := range said {
+		other := "bob"
+		if user == "bob" {
+			other = "alice"
+		}
+		t.Run(user, func(t *testing.T) {
+			owns := func(what string, content *genai.Content) {
+				if text := entryText(content); !strings.HasPrefix(text, user) {
+					t.Errorf("%s for %s returned %q", what, user, text)
+				}
+			}
+
+			entries, err := s.List(ctx, testApp, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(said[user]) {
+				t.Errorf("List returned %d memories, want %d", len(entries), len(said[user]))
+			}
+			for _, e := range entries {
+				owns("List", e.Content)
+			}
+			for _, query := range []string{"떡볶이", other, "고양이 생일"} {
+				res, err := s.Search(ctx, &memory.SearchRequest{AppName: testApp, UserID: user, Query: query})
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, m := range res.Memories {
+					owns("Search "+query, m.Content)
+				}
+			}
+
+			// 다른 사용자의 기억 ID로는 찾지도 지우지도 못합니다.
+			theirs, _ := s.List(ctx, testApp, other)
+			if _, err := s.Resolve(testApp, user, theirs[0].ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Resolve(%s's memory) = %v, want ErrNotFound", other, err)
+			}
+			if _, err := s.Delete(ctx, testApp, user, theirs[0].ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Delete(%s's memory) = %v, want ErrNotFound", other, err)
+			}
+		})
+	}
+}
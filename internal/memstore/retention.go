@@ -0,0 +1,165 @@
+package memstore
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy는 기억을 얼마나 오래, 얼마나 많이 보관할지 정합니다. 0인 항목은 제한하지 않습니다.
+type RetentionPolicy struct {
+	// MaxAge보다 오래된 기억(대화 시각 기준)은 지웁니다.
+	MaxAge time.Duration
+	// MaxEntriesPerUser를 넘으면 가장 오랫동안 검색되지 않은 기억부터 지웁니다(LRU).
+	// 한 번도 검색되지 않은 기억은 대화 시각을 마지막 사용 시각으로 봅니다.
+	MaxEntriesPerUser int
+}
+
+func (p RetentionPolicy) enabled() bool {
+	return p.MaxAge > 0 || p.MaxEntriesPerUser > 0
+}
+
+// WithRetention은 보관 정책을 정합니다. 정책은 EnforceRetention을 부르거나 StartRetention으로 주기적으로 적용합니다.
+func WithRetention(p RetentionPolicy) Option {
+	return func(s *Service) {
+		s.retention = p
+	}
+}
+
+// WithClock은 현재 시각을 돌려주는 함수를 바꿉니다. 테스트에서 시간을 앞당겨 만료를 확인할 때 씁니다.
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+// touch는 검색 결과로 나간 기억의 마지막 사용 시각을 기록합니다. s.mu.RLock을 잡은 상태에서 호출합니다.
+func (s *Service) touch(um *userMemory, hits []hit) {
+	if len(hits) == 0 {
+		return
+	}
+	now := s.now()
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+	for _, h := range hits {
+		um.lastUsed[h.id] = now
+	}
+}
+
+// lastUsedLocked는 기억을 마지막으로 쓴 시각(검색된 시각, 없으면 대화 시각)입니다.
+func lastUsedLocked(um *userMemory, id int) time.Time {
+	if t, ok := um.lastUsed[id]; ok {
+		return t
+	}
+	return um.index.docs[id].entry.Timestamp
+}
+
+// EnforceRetention은 보관 정책을 한 번 적용하고 지운 기억 수를 반환합니다.
+// 지운 기억은 Delete로 지운 것과 같아서, 세션을 다시 AddSession 해도 돌아오지 않고 파일에서도 내용이 사라집니다.
+func (s *Service) EnforceRetention(ctx context.Context) (int, error) {
+	if !s.retention.enabled() {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var removed int
+	for k, um := range s.store {
+		type candidate struct {
+			memID    string
+			lastUsed time.Time
+		}
+		var expired []string
+		var kept []candidate
+		for id, d := range um.index.docs {
+			if s.retention.MaxAge > 0 && now.Sub(d.entry.Timestamp) > s.retention.MaxAge {
+				expired = append(expired, d.entry.EventID)
+				continue
+			}
+			kept = append(kept, candidate{memID: d.entry.EventID, lastUsed: lastUsedLocked(um, id)})
+		}
+		if limit := s.retention.MaxEntriesPerUser; limit > 0 && len(kept) > limit {
+			sort.Slice(kept, func(i, j int) bool {
+				if !kept[i].lastUsed.Equal(kept[j].lastUsed) {
+					return kept[i].lastUsed.Before(kept[j].lastUsed)
+				}
+				return kept[i].memID < kept[j].memID
+			})
+			for _, c := range kept[:len(kept)-limit] {
+				expired = append(expired, c.memID)
+			}
+		}
+		if len(expired) == 0 {
+			continue
+		}
+
+		sort.Strings(expired)
+		rec := record{Op: opDelete, AppName: k.appName, UserID: k.userID, Entries: []entry{}, Deleted: expired, SavedAt: now}
+		if err := s.writeLocked(rec); err != nil {
+			return removed, err
+		}
+		removed += len(expired)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.purgeLocked()
+}
+
+// StartRetention은 interval마다 보관 정책을 적용하고, 파일에 쓸모없는 줄이 쌓였으면 파일을 정리하는 고루틴을 시작합니다.
+// 고루틴은 Close가 멈춥니다. 두 번 이상 부르면 아무것도 하지 않습니다.
+func (s *Service) StartRetention(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil || s.closed {
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.maintain()
+			}
+		}
+	}()
+}
+
+// maintain은 StartRetention의 고루틴이 주기마다 하는 일입니다.
+func (s *Service) maintain() {
+	if n, err := s.EnforceRetention(context.Background()); err != nil {
+		log.Printf("[memstore] retention failed: %v", err)
+	} else if n > 0 {
+		log.Printf("[memstore] retention removed %d memories", n)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil || s.closed || s.superseded <= s.sessionCount() {
+		return
+	}
+	if err := s.compact(); err != nil {
+		log.Printf("[memstore] compaction failed: %v", err)
+	}
+}
+
+// stopRetention은 StartRetention의 고루틴을 멈추고 끝날 때까지 기다립니다.
+func (s *Service) stopRetention() {
+	s.mu.Lock()
+	stop := s.stop
+	s.stop = nil
+	s.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		s.wg.Wait()
+	}
+}
@@ -0,0 +1,105 @@
+package memstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/memory"
+)
+
+func TestEnforceRetention(t *testing.T) {
+	day := 24 * time.Hour
+	base := time.Unix(1_700_000_000, 0)
+	now := base
+	path := filepath.Join(t.TempDir(), "memory.jsonl")
+	s, err := Open(path,
+		WithClock(func() time.Time { return now }),
+		WithRetention(RetentionPolicy{MaxAge: 30 * day, MaxEntriesPerUser: 2}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	g := newGrowingSession(t, testUser)
+	g.addAt(base, "떡볶이 좋아")
+	g.addAt(base.Add(time.Second), "김밥 좋아")
+	g.addAt(base.Add(20*day), "라면 좋아")
+	if err := s.AddSession(ctx, g.addAt(base.Add(20*day+time.Second), "순대 좋아")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 가장 오래된 떡볶이를 검색해서 마지막 사용 시각을 지금으로 당깁니다.
+	now = base.Add(20*day + time.Hour)
+	if _, err := s.SearchScored(ctx, &memory.SearchRequest{AppName: testApp, UserID: testUser, Query: "떡볶이"}, SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// maintain이 true인 단계는 EnforceRetention 대신 StartRetention의 주기 작업을 직접 부르고, 지운 개수는 보지 않습니다.
+	steps := []struct {
+		name     string
+		advance  time.Duration
+		maintain bool
+		removed  int
+		left     []string
+		gone     []string
+	}{
+		{
+			// 나이 제한(30일)에는 아무도 걸리지 않지만 4개 > 2개이므로, 가장 오랫동안 쓰이지 않은 김밥(대화 시각)과 라면이 지워집니다.
+			// 떡볶이는 가장 오래됐지만 방금 검색되어 남습니다.
+			name:    "quota evicts least recently retrieved",
+			removed: 2,
+			left:    []string{"떡볶이 좋아", "순대 좋아"},
+			gone:    []string{"김밥", "라면"},
+		},
+		{
+			// 30일이 지나면 떡볶이는 검색된 적이 있어도 대화 시각 기준으로 만료됩니다. 주기 작업(maintain)도 같은 일을 합니다.
+			name:     "max age expires by conversation time",
+			advance:  11 * day,
+			maintain: true,
+			left:     []string{"순대 좋아"},
+			gone:     []string{"떡볶이"},
+		},
+		{
+			name:    "nothing left to evict",
+			advance: day,
+			removed: 0,
+			left:    []string{"순대 좋아"},
+		},
+	}
+	for _, st := range steps {
+		now = now.Add(st.advance)
+		if st.maintain {
+			s.maintain()
+		} else if removed, err := s.EnforceRetention(ctx); err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		} else if removed != st.removed {
+			t.Errorf("%s: removed %d, want %d", st.name, removed, st.removed)
+		}
+
+		entries, _ := s.List(ctx, testApp, testUser)
+		var left []string
+		for _, e := range entries {
+			left = append(left, entryText(e.Content))
+		}
+		if !slices.Equal(left, st.left) {
+			t.Errorf("%s: left %q, want %q", st.name, left, st.left)
+		}
+		// 지운 기억은 파일을 다시 써서 디스크에서도 사라집니다.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, text := range st.gone {
+			if strings.Contains(string(data), text) {
+				t.Errorf("%s: evicted %q is still in the file", st.name, text)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package memstore
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenize는 텍스트를 검색용 단어(term)로 나눕니다.
+//
+//  1. 글자/숫자가 아닌 문자(공백, 문장부호)를 기준으로 나누고 소문자로 바꿉니다.
+//  2. "Go언어를"처럼 한글과 영문이 붙어 있으면 문자 종류가 바뀌는 곳에서 한 번 더 나눕니다.
+//  3. 한글 단어는 조사를 떼고("이름은" → "이름") 어미를 정규화합니다("좋아해요" → "좋아하", "민수입니다" → "민수").
+//  4. 의문사, 대명사처럼 어느 문장에나 나오는 불용어("뭐", "내", "what")는 버립니다.
+//
+// 조사/어미 분석은 사전 없이 규칙으로만 하므로 틀릴 수 있습니다("아이" → "아").
+// 그래서 원래 단어도 항상 함께 내보내, 분석이 틀려도 같은 모양의 단어끼리는 여전히 검색됩니다.
+func tokenize(text string) []string {
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		for _, word := range splitScripts(field) {
+			if !isHangulWord(word) {
+				// 영문 한 글자("i", "a")는 검색에 도움이 되지 않습니다.
+				if (utf8.RuneCountInString(word) > 1 || unicode.IsNumber([]rune(word)[0])) && !stopwords[word] {
+					terms = append(terms, word)
+				}
+				continue
+			}
+			for _, form := range koreanForms(word) {
+				if !stopwords[form] {
+					terms = append(terms, form)
+				}
+			}
+		}
+	}
+	return terms
+}
+
+// 검색에 도움이 되지 않는 불용어 (조사를 떼고 난 뒤의 형태로 비교합니다)
+var stopwords = map[string]bool{
+	"뭐": true, "무엇": true, "뭔가": true, "어디": true, "언제": true, "누구": true, "왜": true, "어떻게": true, "어떤": true,
+	"나": true, "내": true, "저": true, "제": true, "너": true, "네": true, "우리": true, "그": true, "이": true, "것": true, "거": true,
+	"좀": true, "아까": true, "그리고": true, "그래서": true,
+	"the": true, "is": true, "are": true, "was": true, "what": true, "my": true, "me": true, "you": true, "your": true,
+	"do": true, "did": true, "to": true, "of": true, "and": true, "in": true, "it": true,
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isHangul(r rune) bool {
+	return unicode.Is(unicode.Hangul, r)
+}
+
+func isHangulWord(word string) bool {
+	for _, r := range word {
+		if !isHangul(r) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// splitScripts는 한글과 그 밖의 문자(영문, 숫자)가 바뀌는 곳에서 단어를 나눕니다.
+func splitScripts(word string) []string {
+	var parts []string
+	start := 0
+	var prev bool
+	for i, r := range word {
+		cur := isHangul(r)
+		if i > 0 && cur != prev {
+			parts = append(parts, word[start:i])
+			start = i
+		}
+		prev = cur
+	}
+	return append(parts, word[start:])
+}
+
+// 체언 뒤에 붙는 조사. 긴 것부터 비교합니다.
+var particles = sortedByLength([]string{
+	"에서는", "으로는", "에게서", "한테서", "이라고", "이라는", "이랑은",
+	"에서", "에게", "한테", "으로", "까지", "부터", "처럼", "보다", "이랑", "라고", "라는", "에는", "와는", "과는", "께서",
+	"은", "는", "이", "가", "을", "를", "의", "에", "도", "만", "로", "와", "과", "랑", "께",
+})
+
+// 서술격 조사 "이다"의 활용형 ("민수입니다", "학생이에요", "뭐였지")
+var copulaEndings = sortedByLength([]string{
+	"이었습니다", "였습니다", "이었어요", "였어요", "이었어", "였어", "이었지", "였지", "이었다", "였다",
+	"입니다", "이에요", "이예요", "예요", "에요", "이야", "이다", "이고", "이지", "인데",
+})
+
+// "하다" 동사/형용사의 활용형 ("좋아해요", "좋아했어", "좋아하는") → 어간 "하"로 맞춥니다.
+var haEndings = sortedByLength([]string{
+	"했습니다", "합니다", "했어요", "해요", "했어", "했다", "했지", "해서", "하세요", "하는", "하고", "하면", "한다", "하지", "하다", "해", "할", "함",
+})
+
+// 그 밖의 용언 어미 ("먹었어" → "먹", "좋아요" → "좋")
+var verbEndings = sortedByLength([]string{
+	"었습니다", "았습니다", "습니다", "었어요", "았어요", "어요", "아요", "었어", "았어", "었다", "았다", "는다", "네요", "지요", "어서", "아서",
+})
+
+// koreanForms는 한글 단어와, 조사를 떼거나 어미를 정규화한 형태들을 중복 없이 반환합니다.
+func koreanForms(word string) []string {
+	forms := []string{word}
+	add := func(f string) {
+		if f == "" {
+			return
+		}
+		for _, existing := range forms {
+			if existing == f {
+				return
+			}
+		}
+		forms = append(forms, f)
+	}
+
+	if stem, ok := trimSuffix(word, copulaEndings, 1); ok {
+		add(stem)
+	}
+	if stem, ok := trimSuffix(word, haEndings, 1); ok {
+		add(stem + "하")
+	}
+	if stem, ok := trimSuffix(word, verbEndings, 1); ok {
+		add(stem)
+	}
+	if stem, ok := trimSuffix(word, particles, 1); ok {
+		add(stem)
+	}
+	return forms
+}
+
+// trimSuffix는 word가 suffixes 중 하나로 끝나고, 떼고 남은 어간이 minStem 글자 이상이면 어간을 반환합니다.
+// suffixes는 긴 것부터 정렬되어 있어야 합니다.
+func trimSuffix(word string, suffixes []string, minStem int) (string, bool) {
+	for _, s := range suffixes {
+		if stem, ok := strings.CutSuffix(word, s); ok && utf8.RuneCountInString(stem) >= minStem {
+			return stem, true
+		}
+	}
+	return "", false
+}
+
+// sortedByLength는 긴 것부터 비교하도록 words를 글자 수 내림차순으로 정렬합니다.
+func sortedByLength(words []string) []string {
+	slices.SortStableFunc(words, func(a, b string) int {
+		return utf8.RuneCountInString(b) - utf8.RuneCountInString(a)
+	})
+	return words
+}
@@ -0,0 +1,40 @@
+package memstore
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		// 조사가 달라도 모두 "이름"이 나와야 서로 검색됩니다.
+		{"이름은", []string{"이름은", "이름"}},
+		{"이름이", []string{"이름이", "이름"}},
+		{"이름을", []string{"이름을", "이름"}},
+		{"서울에서는", []string{"서울에서는", "서울"}},
+		// 어미 정규화
+		{"민수입니다", []string{"민수입니다", "민수"}},
+		{"좋아해요", []string{"좋아해요", "좋아하"}},
+		{"먹었어", []string{"먹었어", "먹"}},
+		// 한글과 영문이 붙어 있으면 나눕니다.
+		{"Go언어를 배워요", []string{"go", "언어를", "언어", "배워요"}},
+		{"React로 만든 앱", []string{"react", "로", "만든", "앱"}},
+		{"GPT-4o 모델", []string{"gpt", "4o", "모델"}},
+		// 불용어와 영문 한 글자는 버리고, 숫자는 한 글자여도 남깁니다.
+		{"내 이름이 뭐였지?", []string{"이름이", "이름", "뭐였지"}},
+		{"What is my name?", []string{"name"}},
+		{"I have 2 cats", []string{"have", "2", "cats"}},
+		{"", nil},
+		{"?!...", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tokenize(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package memstore
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+)
+
+// VectorIndex는 벡터를 보관하고 질의 벡터와 가장 비슷한(코사인 유사도가 높은) 벡터를 찾습니다.
+// 벡터는 길이 1로 정규화되어 들어온다고 가정합니다. 동시성 보호는 Service가 합니다.
+type VectorIndex interface {
+	Add(id int, vec []float32)
+	Remove(id int)
+	// Search는 유사도가 높은 순으로 최대 k개를 반환합니다.
+	Search(query []float32, k int) []VectorHit
+	Len() int
+}
+
+// VectorHit는 벡터 검색 결과 한 건입니다. Score는 코사인 유사도(-1~1)입니다.
+type VectorHit struct {
+	ID    int
+	Score float32
+}
+
+// --- 전수 조사 (Brute-force) ---
+
+// BruteForceIndex는 모든 벡터와 유사도를 계산합니다. 결과가 항상 정확하고, 기억이 수천 건 이하라면 충분히 빠릅니다.
+type BruteForceIndex struct {
+	vecs map[int][]float32
+}
+
+func NewBruteForceIndex() *BruteForceIndex {
+	return &BruteForceIndex{vecs: make(map[int][]float32)}
+}
+
+func (x *BruteForceIndex) Add(id int, vec []float32) { x.vecs[id] = vec }
+func (x *BruteForceIndex) Remove(id int)             { delete(x.vecs, id) }
+func (x *BruteForceIndex) Len() int                  { return len(x.vecs) }
+
+func (x *BruteForceIndex) Search(query []float32, k int) []VectorHit {
+	hits := make([]VectorHit, 0, len(x.vecs))
+	for id, vec := range x.vecs {
+		hits = append(hits, VectorHit{ID: id, Score: dot(query, vec)})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// --- HNSW (Hierarchical Navigable Small World) ---
+
+// HNSWIndex는 근사 최근접 이웃(ANN) 그래프 색인입니다.
+// 위층일수록 노드가 적은 여러 층의 그래프를 두고, 위층에서 대략적인 위치를 찾은 뒤 아래층으로 내려가며 좁혀갑니다.
+// 전수 조사보다 훨씬 적은 벡터만 비교하므로 기억이 많아져도 빠르지만, 가끔 가장 가까운 벡터를 놓칠 수 있습니다.
+//
+// 삭제는 표시만 해두고(tombstone) 검색 결과에서 뺍니다. 삭제된 노드가 절반을 넘으면 그래프를 다시 만듭니다.
+type HNSWIndex struct {
+	// M은 노드마다 연결하는 이웃 수입니다 (0층은 2M).
+	M int
+	// EfConstruction/EfSearch는 추가/검색할 때 살펴보는 후보 수입니다. 클수록 정확하고 느립니다.
+	EfConstruction int
+	EfSearch       int
+
+	levelMult float64
+	rng       *rand.Rand
+	nodes     map[int]*hnswNode
+	entry     int
+	maxLevel  int
+	deleted   int
+}
+
+type hnswNode struct {
+	vec     []float32
+	friends [][]int // 층별 이웃
+	deleted bool
+}
+
+// NewHNSWIndex는 일반적인 기본값(M=16, efConstruction=200, efSearch=64)으로 HNSW 색인을 만듭니다.
+// 같은 순서로 추가하면 항상 같은 그래프가 만들어지도록 난수 시드를 고정합니다.
+func NewHNSWIndex() *HNSWIndex {
+	return &HNSWIndex{
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+		levelMult:      1 / math.Log(16),
+		rng:            rand.New(rand.NewPCG(1, 2)),
+		nodes:          make(map[int]*hnswNode),
+		entry:          -1,
+	}
+}
+
+func (x *HNSWIndex) Len() int { return len(x.nodes) - x.deleted }
+
+func (x *HNSWIndex) Add(id int, vec []float32) {
+	// 같은 ID를 다시 추가하면 원래 층수를 그대로 두고 벡터와 이웃만 바꿉니다.
+	// 다른 노드의 이웃 목록에 남은 연결이 이 노드의 층을 가리키므로 층수가 바뀌면 안 됩니다.
+	if node, ok := x.nodes[id]; ok {
+		if node.deleted {
+			node.deleted = false
+			x.deleted--
+		}
+		node.vec = vec
+		if len(x.nodes) > 1 {
+			x.link(id, node)
+		}
+		return
+	}
+
+	level := int(math.Floor(-math.Log(1-x.rng.Float64()) * x.levelMult))
+	node := &hnswNode{vec: vec, friends: make([][]int, level+1)}
+	x.nodes[id] = node
+	if x.entry < 0 {
+		x.entry, x.maxLevel = id, level
+		return
+	}
+	x.link(id, node)
+	if level > x.maxLevel {
+		x.entry, x.maxLevel = id, level
+	}
+}
+
+// link는 node의 층마다 가장 비슷한 이웃을 찾아 양방향으로 연결합니다. 이웃 목록에 node 자신은 넣지 않습니다.
+func (x *HNSWIndex) link(id int, node *hnswNode) {
+	level := len(node.friends) - 1
+	ep := []int{x.entry}
+	for l := x.maxLevel; l > level; l-- {
+		ep = x.searchLayer(node.vec, ep, 1, l)[:1]
+	}
+	for l := min(level, x.maxLevel); l >= 0; l-- {
+		candidates := x.searchLayer(node.vec, ep, x.EfConstruction+1, l)
+		neighbors := make([]int, 0, x.M)
+		for _, c := range candidates {
+			if c != id && len(neighbors) < x.M {
+				neighbors = append(neighbors, c)
+			}
+		}
+		node.friends[l] = neighbors
+		for _, n := range neighbors {
+			x.connect(n, id, l)
+		}
+		ep = candidates
+	}
+}
+
+// connect는 from의 l층 이웃에 to를 추가하고, 이웃이 너무 많으면 가장 먼 이웃부터 끊습니다.
+func (x *HNSWIndex) connect(from, to, l int) {
+	node := x.nodes[from]
+	if slices.Contains(node.friends[l], to) {
+		return
+	}
+	node.friends[l] = append(node.friends[l], to)
+	maxFriends := x.M
+	if l == 0 {
+		maxFriends = 2 * x.M
+	}
+	if len(node.friends[l]) <= maxFriends {
+		return
+	}
+	sort.Slice(node.friends[l], func(i, j int) bool {
+		return dot(node.vec, x.nodes[node.friends[l][i]].vec) > dot(node.vec, x.nodes[node.friends[l][j]].vec)
+	})
+	node.friends[l] = node.friends[l][:maxFriends]
+}
+
+func (x *HNSWIndex) Remove(id int) {
+	node, ok := x.nodes[id]
+	if !ok || node.deleted {
+		return
+	}
+	node.deleted = true
+	x.deleted++
+	if x.deleted > 16 && x.deleted*2 > len(x.nodes) {
+		x.rebuild()
+	}
+}
+
+// rebuild는 삭제되지 않은 노드만으로 그래프를 다시 만듭니다.
+func (x *HNSWIndex) rebuild() {
+	ids := make([]int, 0, len(x.nodes)-x.deleted)
+	for id, n := range x.nodes {
+		if !n.deleted {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	old := x.nodes
+	x.nodes, x.entry, x.maxLevel, x.deleted = make(map[int]*hnswNode), -1, 0, 0
+	for _, id := range ids {
+		x.Add(id, old[id].vec)
+	}
+}
+
+func (x *HNSWIndex) Search(query []float32, k int) []VectorHit {
+	if x.entry < 0 || k <= 0 {
+		return nil
+	}
+	ep := []int{x.entry}
+	for l := x.maxLevel; l > 0; l-- {
+		ep = x.searchLayer(query, ep, 1, l)[:1]
+	}
+	// 삭제 표시된 노드가 후보에 섞여 있으므로 그만큼 더 넉넉하게 찾습니다.
+	ef := max(x.EfSearch, k) + x.deleted
+	var hits []VectorHit
+	for _, id := range x.searchLayer(query, ep, ef, 0) {
+		if n := x.nodes[id]; !n.deleted {
+			hits = append(hits, VectorHit{ID: id, Score: dot(query, n.vec)})
+			if len(hits) == k {
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// searchLayer는 l층에서 query와 가장 비슷한 노드를 최대 ef개 찾아 유사도가 높은 순으로 반환합니다.
+func (x *HNSWIndex) searchLayer(query []float32, entryPoints []int, ef, l int) []int {
+	visited := make(map[int]bool)
+	candidates := &simHeap{}           // 아직 살펴보지 않은 후보 (가장 비슷한 것부터)
+	results := &simHeap{reverse: true} // 지금까지 찾은 결과 (가장 덜 비슷한 것이 맨 위)
+	for _, id := range entryPoints {
+		visited[id] = true
+		s := dot(query, x.nodes[id].vec)
+		heap.Push(candidates, simItem{id, s})
+		heap.Push(results, simItem{id, s})
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(simItem)
+		if results.Len() >= ef && c.sim < results.items[0].sim {
+			break
+		}
+		node := x.nodes[c.id]
+		if l >= len(node.friends) {
+			continue
+		}
+		for _, f := range node.friends[l] {
+			if visited[f] {
+				continue
+			}
+			visited[f] = true
+			s := dot(query, x.nodes[f].vec)
+			if results.Len() < ef || s > results.items[0].sim {
+				heap.Push(candidates, simItem{f, s})
+				heap.Push(results, simItem{f, s})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sorted := results.items
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].sim > sorted[j].sim })
+	ids := make([]int, len(sorted))
+	for i, it := range sorted {
+		ids[i] = it.id
+	}
+	return ids
+}
+
+type simItem struct {
+	id  int
+	sim float32
+}
+
+// simHeap은 유사도 기준 힙입니다. reverse가 false면 가장 비슷한 항목이, true면 가장 덜 비슷한 항목이 맨 위에 옵니다.
+type simHeap struct {
+	items   []simItem
+	reverse bool
+}
+
+func (h *simHeap) Len() int { return len(h.items) }
+func (h *simHeap) Less(i, j int) bool {
+	if h.reverse {
+		return h.items[i].sim < h.items[j].sim
+	}
+	return h.items[i].sim > h.items[j].sim
+}
+func (h *simHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *simHeap) Push(v any)    { h.items = append(h.items, v.(simItem)) }
+func (h *simHeap) Pop() any {
+	it := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return it
+}
@@ -0,0 +1,263 @@
+package memstore
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/memory"
+)
+
+func TestHashingEmbedder(t *testing.T) {
+	e := NewHashingEmbedder(256)
+	if e.Name() != "hashing/256" {
+		t.Errorf("name = %q", e.Name())
+	}
+	vecs, err := e.EmbedDocuments(t.Context(), []string{"떡볶이", "떡볶이를 좋아해요", "내일 날씨"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vecs {
+		if len(v) != 256 {
+			t.Fatalf("vector %d has %d dimensions", i, len(v))
+		}
+		if n := dot(v, v); math.Abs(float64(n)-1) > 1e-5 {
+			t.Errorf("vector %d has length² %v, want 1", i, n)
+		}
+	}
+	// 같은 텍스트는 항상 같은 벡터이고, 검색어와 문서가 같은 공간에 있습니다.
+	q, _ := e.EmbedQuery(t.Context(), "떡볶이")
+	if dot(q, vecs[0]) < 0.9999 {
+		t.Errorf("query and document vectors of the same text differ: %v", dot(q, vecs[0]))
+	}
+	if near, far := dot(q, vecs[1]), dot(q, vecs[2]); near <= far {
+		t.Errorf("similar text %v is not closer than unrelated text %v", near, far)
+	}
+	// 단어가 하나도 없으면 0 벡터이며 어떤 벡터와도 유사도가 0입니다.
+	if empty, _ := e.EmbedQuery(t.Context(), "?!"); dot(empty, vecs[0]) != 0 {
+		t.Errorf("empty text is similar to %v", dot(empty, vecs[0]))
+	}
+}
+
+func TestBruteForceIndex(t *testing.T) {
+	x := NewBruteForceIndex()
+	x.Add(1, []float32{1, 0})
+	x.Add(2, []float32{0.6, 0.8})
+	x.Add(3, []float32{0, 1})
+	x.Add(4, []float32{-1, 0})
+
+	hits := x.Search([]float32{1, 0}, 0)
+	want := []int{1, 2, 3, 4}
+	if len(hits) != len(want) {
+		t.Fatalf("hits = %v", hits)
+	}
+	for i, h := range hits {
+		if h.ID != want[i] {
+			t.Fatalf("hits = %v, want ids %v", hits, want)
+		}
+	}
+	if hits[1].Score != 0.6 || hits[3].Score != -1 {
+		t.Errorf("scores = %v", hits)
+	}
+	if got := x.Search([]float32{1, 0}, 2); len(got) != 2 {
+		t.Errorf("k=2 returned %d hits", len(got))
+	}
+
+	// 같은 ID를 다시 넣으면 벡터를 바꾸고, 지우면 검색되지 않습니다.
+	x.Add(4, []float32{1, 0})
+	x.Remove(1)
+	if hits := x.Search([]float32{1, 0}, 1); x.Len() != 3 || hits[0].ID != 4 {
+		t.Errorf("len = %d, top = %v, want 3 and id 4", x.Len(), hits)
+	}
+}
+
+// randomVectors는 길이 1인 무작위 벡터 n개를 만듭니다.
+func randomVectors(rng *rand.Rand, n, dim int) [][]float32 {
+	vecs := make([][]float32, n)
+	for i := range vecs {
+		v := make([]float32, dim)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		vecs[i] = normalize(v)
+	}
+	return vecs
+}
+
+// recall은 정답(전수 조사) k개 중 HNSW가 찾은 비율입니다.
+func recall(t *testing.T, hnsw *HNSWIndex, exact *BruteForceIndex, queries [][]float32, k int) float64 {
+	t.Helper()
+	found, total := 0, 0
+	for _, q := range queries {
+		got := make(map[int]bool)
+		for _, h := range hnsw.Search(q, k) {
+			got[h.ID] = true
+		}
+		for _, h := range exact.Search(q, k) {
+			if got[h.ID] {
+				found++
+			}
+			total++
+		}
+	}
+	return float64(found) / float64(total)
+}
+
+func TestHNSWRecall(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	vecs := randomVectors(rng, 1000, 32)
+	hnsw, exact := NewHNSWIndex(), NewBruteForceIndex()
+	for id, v := range vecs {
+		hnsw.Add(id, v)
+		exact.Add(id, v)
+	}
+	queries := randomVectors(rng, 50, 32)
+	if r := recall(t, hnsw, exact, queries, 10); r < 0.95 {
+		t.Errorf("recall@10 = %.3f, want ≥ 0.95", r)
+	}
+
+	// 절반 넘게 지워 그래프를 다시 만든 뒤에도 지운 것은 나오지 않고 recall은 유지됩니다.
+	for id := 0; id < 600; id++ {
+		hnsw.Remove(id)
+		exact.Remove(id)
+	}
+	if hnsw.Len() != 400 || len(hnsw.nodes) == 1000 {
+		t.Errorf("after removing 600: len = %d, %d nodes, want 400 and a rebuilt graph", hnsw.Len(), len(hnsw.nodes))
+	}
+	for _, q := range queries {
+		for _, h := range hnsw.Search(q, 10) {
+			if h.ID < 600 {
+				t.Fatalf("removed id %d was returned", h.ID)
+			}
+		}
+	}
+	if r := recall(t, hnsw, exact, queries, 10); r < 0.95 {
+		t.Errorf("recall@10 after rebuild = %.3f, want ≥ 0.95", r)
+	}
+}
+
+// 이미 있는 ID를 다시 추가해도 층 구조가 깨지지 않아야 합니다(예전에는 connect에서 범위를 벗어나 패닉이 났습니다).
+func TestHNSWReAdd(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	hnsw, exact := NewHNSWIndex(), NewBruteForceIndex()
+	for id, v := range randomVectors(rng, 300, 16) {
+		hnsw.Add(id, v)
+		exact.Add(id, v)
+	}
+	levels := make(map[int]int)
+	for id, n := range hnsw.nodes {
+		levels[id] = len(n.friends)
+	}
+
+	for round := range 3 {
+		for id, v := range randomVectors(rng, 300, 16) {
+			hnsw.Add(id, v)
+			exact.Add(id, v)
+		}
+		if hnsw.Len() != 300 {
+			t.Fatalf("round %d: len = %d, want 300", round, hnsw.Len())
+		}
+	}
+	for id, n := range hnsw.nodes {
+		if len(n.friends) != levels[id] {
+			t.Errorf("node %d has %d layers after re-adding, want %d", id, len(n.friends), levels[id])
+		}
+		for l, friends := range n.friends {
+			for _, f := range friends {
+				if f == id {
+					t.Errorf("node %d links to itself on layer %d", id, l)
+				}
+				if l >= len(hnsw.nodes[f].friends) {
+					t.Errorf("node %d links to %d on layer %d, which it does not have", id, f, l)
+				}
+			}
+		}
+	}
+	if r := recall(t, hnsw, exact, randomVectors(rng, 30, 16), 10); r < 0.9 {
+		t.Errorf("recall@10 after re-adding = %.3f, want ≥ 0.9", r)
+	}
+
+	// 지운 ID를 다시 추가하면 되살아납니다.
+	v := hnsw.nodes[7].vec
+	hnsw.Remove(7)
+	hnsw.Add(7, v)
+	if hits := hnsw.Search(v, 1); hnsw.Len() != 300 || len(hits) != 1 || hits[0].ID != 7 {
+		t.Errorf("re-added id: len = %d, hits = %v", hnsw.Len(), hits)
+	}
+
+	// 노드가 하나뿐일 때 다시 추가해도 됩니다.
+	one := NewHNSWIndex()
+	one.Add(1, []float32{1, 0})
+	one.Add(1, []float32{0, 1})
+	if hits := one.Search([]float32{0, 1}, 1); len(hits) != 1 || hits[0].Score != 1 {
+		t.Errorf("single node re-add: %v", hits)
+	}
+}
+
+func TestHybridSearchScore(t *testing.T) {
+	s := NewInMemory(WithVectorSearch(NewHashingEmbedder(256), func() VectorIndex { return NewBruteForceIndex() }))
+	g := newGrowingSession(t, testUser)
+	sess := g.addAt(time.Time{},
+		"저는 떡볶이를 좋아해요",
+		"떡볶이 맛집은 신당동에 있어요",
+		"주말에 부산에 갔어요",
+		"내일 날씨가 궁금해요",
+	)
+	if err := s.AddSession(t.Context(), sess); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(opts SearchOptions) map[string]float64 {
+		t.Helper()
+		res, err := s.SearchScored(t.Context(), &memory.SearchRequest{AppName: testApp, UserID: testUser, Query: "떡볶이 좋아해"}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scores := make(map[string]float64)
+		for i, r := range res {
+			scores[r.ID] = r.Score
+			if i > 0 && r.Score > res[i-1].Score {
+				t.Errorf("%s results are not sorted: %v", opts.Mode, res)
+			}
+		}
+		return scores
+	}
+	lexical := search(SearchOptions{Mode: ModeBM25})
+	semantic := search(SearchOptions{Mode: ModeVector, MinScore: -1})
+	var maxLexical float64
+	for _, v := range lexical {
+		maxLexical = max(maxLexical, v)
+	}
+
+	for _, alpha := range []float64{0.3, 0.5, 1} {
+		hybrid := search(SearchOptions{Mode: ModeHybrid, Alpha: alpha})
+		if len(hybrid) == 0 {
+			t.Fatalf("alpha %v: no results", alpha)
+		}
+		for id, got := range hybrid {
+			want := alpha*max(semantic[id], 0) + (1-alpha)*lexical[id]/maxLexical
+			if math.Abs(got-want) > 1e-6 {
+				t.Errorf("alpha %v, %s: score = %v, want %v", alpha, id, got, want)
+			}
+			if got <= 0 || got > 1 {
+				t.Errorf("alpha %v, %s: score %v is outside (0, 1]", alpha, id, got)
+			}
+		}
+	}
+
+	// Alpha를 비워 두면 DefaultAlpha로 섞습니다.
+	def, half := search(SearchOptions{Mode: ModeHybrid}), search(SearchOptions{Mode: ModeHybrid, Alpha: DefaultAlpha})
+	if len(def) != len(half) {
+		t.Fatalf("default alpha = %v, want %v", def, half)
+	}
+	for id, v := range half {
+		if def[id] != v {
+			t.Errorf("default alpha score for %s = %v, want %v", id, def[id], v)
+		}
+	}
+	// 벡터 검색이 없으면 vector/hybrid 모드는 쓸 수 없습니다.
+	if _, err := NewInMemory().SearchScored(t.Context(), &memory.SearchRequest{AppName: testApp, UserID: testUser, Query: "떡볶이"}, SearchOptions{Mode: ModeHybrid}); err == nil {
+		t.Error("hybrid search without an embedder succeeded")
+	}
+}
@@ -0,0 +1,86 @@
+package profilestore
+
+import (
+	"strings"
+	"time"
+)
+
+// Category는 사실의 종류입니다.
+type Category string
+
+const (
+	CategoryIdentity   Category = "identity"   // 이름, 직업, 사는 곳
+	CategoryPreference Category = "preference" // 좋아하는 것, 싫어하는 것
+	CategoryDate       Category = "date"       // 생일, 기념일, 약속
+	CategoryOther      Category = "other"
+)
+
+// Categories는 허용되는 모든 Category입니다. 추출 에이전트의 스키마 Enum에도 씁니다.
+var Categories = []Category{CategoryIdentity, CategoryPreference, CategoryDate, CategoryOther}
+
+// Source는 사실이 어느 대화에서 나왔는지(출처, provenance)입니다.
+type Source struct {
+	SessionID string `json:"sessionId"`
+	EventID   string `json:"eventId"`
+}
+
+// Fact는 사용자 프로필의 사실 하나입니다. 사용자마다 Key 하나에 값 하나만 둡니다.
+type Fact struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"` // 예: "name", "favorite_food", "birthday"
+	Value     string    `json:"value"`
+	Category  Category  `json:"category"`
+	Source    Source    `json:"source"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// History는 이 사실의 이전 값들입니다. 오래된 것부터 최대 maxHistory개를 보관합니다.
+	History []Revision `json:"history,omitempty"`
+}
+
+// Revision은 바뀌기 전의 값 하나입니다.
+type Revision struct {
+	Value     string    `json:"value"`
+	Source    Source    `json:"source"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// 사실마다 보관하는 이전 값의 최대 개수
+const maxHistory = 5
+
+// Input은 Put에 넘기는 새 사실입니다. At은 사실이 말해진 시각(보통 이벤트 시각)입니다.
+type Input struct {
+	Key      string
+	Value    string
+	Category Category
+	Source   Source
+	At       time.Time
+}
+
+// Change는 Put이 프로필을 어떻게 바꿨는지 나타냅니다.
+type Change int
+
+const (
+	Unchanged Change = iota // 같은 값이 이미 있거나, 더 최근 값이 이미 있어 무시함
+	Added
+	Updated
+)
+
+// NormalizeKey는 "Favorite Food"나 "favorite-food"를 "favorite_food"로 맞춥니다.
+func NormalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.Join(strings.FieldsFunc(key, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '.'
+	}), "_")
+}
+
+func sameValue(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+func validCategory(c Category) Category {
+	for _, known := range Categories {
+		if c == known {
+			return c
+		}
+	}
+	return CategoryOther
+}
@@ -0,0 +1,244 @@
+// Package profilestore는 대화에서 뽑아낸 사용자 사실(이름, 취향, 날짜 등)을 보관하는 프로필 저장소입니다.
+//
+// 대화 원문(memstore)과 달리 사실은 사용자마다 Key 하나에 값 하나만 둡니다.
+// 같은 Key의 값이 바뀌면 더 최근에 말해진 값이 이기고, 이전 값은 출처와 함께 History에 남습니다.
+// 사실마다 어느 세션의 어느 이벤트에서 나왔는지(Source)를 기록합니다.
+//
+// NewInMemory는 메모리에만 보관하고, Open은 JSONL 파일에 한 줄씩 덧붙여(append-only) 기록합니다.
+package profilestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"awesomeProject2/internal/jsonl"
+)
+
+// ErrNotFound는 지우려는 사실이 없을 때 반환됩니다.
+var ErrNotFound = errors.New("fact not found")
+
+// 파일에 기록되는 한 줄
+type record struct {
+	Op      string    `json:"op"` // put, forget
+	AppName string    `json:"appName"`
+	UserID  string    `json:"userId"`
+	Fact    *Fact     `json:"fact,omitempty"`
+	FactID  string    `json:"factId,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+type key struct {
+	appName string
+	userID  string
+}
+
+// Store는 사용자 프로필 저장소입니다. 여러 고루틴에서 동시에 써도 안전합니다.
+type Store struct {
+	mu       sync.RWMutex
+	file     *jsonl.Log
+	closed   bool
+	profiles map[key]map[string]*Fact // Key → 사실
+}
+
+// NewInMemory는 파일에 기록하지 않는 Store를 만듭니다.
+func NewInMemory() *Store {
+	return &Store{profiles: make(map[key]map[string]*Fact)}
+}
+
+// Open은 path의 프로필 파일을 읽어 Store를 만듭니다. 파일이 없으면 새로 만듭니다.
+// 기록 도중 프로세스가 죽어 마지막 줄이 잘렸다면 그 줄은 버립니다.
+// 덮어쓰이거나 지워진 줄이 살아있는 사실보다 많으면 파일을 다시 써서 크기를 줄입니다.
+func Open(path string) (*Store, error) {
+	s := NewInMemory()
+	var total int
+	f, err := jsonl.Open(path, func(rec record) error {
+		total++
+		return s.apply(rec)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile file: %w", err)
+	}
+	s.file = f
+
+	if total > 2*s.factCount() {
+		if err := s.compact(); err != nil {
+			log.Printf("[profilestore] compaction failed: %v", err)
+		}
+	}
+	return s, nil
+}
+
+// apply는 기록 한 줄을 메모리에 반영합니다. 파일에서 읽은 기록이 형식에 맞지 않으면 오류를 반환합니다.
+func (s *Store) apply(rec record) error {
+	k := key{appName: rec.AppName, userID: rec.UserID}
+	switch rec.Op {
+	case "put":
+		if rec.Fact == nil || rec.Fact.Key == "" {
+			return errors.New("put record without a fact")
+		}
+		p, ok := s.profiles[k]
+		if !ok {
+			p = make(map[string]*Fact)
+			s.profiles[k] = p
+		}
+		p[rec.Fact.Key] = rec.Fact
+	case "forget":
+		for factKey, f := range s.profiles[k] {
+			if f.ID == rec.FactID {
+				delete(s.profiles[k], factKey)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown op %q", rec.Op)
+	}
+	return nil
+}
+
+// write는 rec을 파일 끝에 기록하고(fsync 포함) 메모리에 반영합니다. s.mu를 잡은 상태에서 호출해야 합니다.
+func (s *Store) write(rec record) error {
+	if s.closed {
+		return errors.New("profile store is closed")
+	}
+	if s.file != nil {
+		if err := s.file.Append(rec); err != nil {
+			return fmt.Errorf("failed to write profile record: %w", err)
+		}
+	}
+	return s.apply(rec)
+}
+
+// Put은 사실을 저장하고 저장된 사실과 변경 종류를 반환합니다.
+//
+// 같은 Key에 이미 값이 있으면:
+//   - 값이 같으면(대소문자/공백 무시) 아무것도 바꾸지 않습니다.
+//   - in.At이 저장된 값보다 이전이면 이미 더 최근 값이 있으므로 무시합니다.
+//   - 그렇지 않으면 새 값으로 바꾸고 이전 값은 History에 남깁니다. ID(와 Category를 주지 않았다면 Category)는 그대로입니다.
+func (s *Store) Put(appName, userID string, in Input) (Fact, Change, error) {
+	factKey := NormalizeKey(in.Key)
+	if factKey == "" || in.Value == "" {
+		return Fact{}, Unchanged, errors.New("fact key and value are required")
+	}
+	if in.At.IsZero() {
+		in.At = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, exists := s.profiles[key{appName: appName, userID: userID}][factKey]
+	if exists && (sameValue(old.Value, in.Value) || in.At.Before(old.UpdatedAt)) {
+		return *old, Unchanged, nil
+	}
+
+	f := &Fact{
+		ID:        uuid.NewString()[:8],
+		Key:       factKey,
+		Value:     in.Value,
+		Category:  validCategory(in.Category),
+		Source:    in.Source,
+		UpdatedAt: in.At,
+	}
+	change := Added
+	if exists {
+		f.ID = old.ID
+		if in.Category == "" {
+			f.Category = old.Category
+		}
+		f.History = append(slices.Clone(old.History), Revision{Value: old.Value, Source: old.Source, UpdatedAt: old.UpdatedAt})
+		if len(f.History) > maxHistory {
+			f.History = f.History[len(f.History)-maxHistory:]
+		}
+		change = Updated
+	}
+
+	if err := s.write(record{Op: "put", AppName: appName, UserID: userID, Fact: f, Time: time.Now()}); err != nil {
+		return Fact{}, Unchanged, err
+	}
+	return *f, change, nil
+}
+
+// Profile은 사용자의 모든 사실을 Key 순으로 반환합니다.
+func (s *Store) Profile(appName, userID string) []Fact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p := s.profiles[key{appName: appName, userID: userID}]
+	facts := make([]Fact, 0, len(p))
+	for _, f := range p {
+		facts = append(facts, *f)
+	}
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Key < facts[j].Key })
+	return facts
+}
+
+// Forget은 ID 또는 Key가 idOrKey인 사실을 지우고, 지운 사실을 반환합니다. 이전 값(History)도 함께 지워집니다.
+// 파일에 기록하는 경우 파일을 다시 써서 지운 값과 History를 디스크에서도 없앱니다.
+func (s *Store) Forget(appName, userID, idOrKey string) (Fact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var target *Fact
+	for factKey, f := range s.profiles[key{appName: appName, userID: userID}] {
+		if f.ID == idOrKey || factKey == NormalizeKey(idOrKey) {
+			target = f
+			break
+		}
+	}
+	if target == nil {
+		return Fact{}, fmt.Errorf("%w: %s", ErrNotFound, idOrKey)
+	}
+	if err := s.write(record{Op: "forget", AppName: appName, UserID: userID, FactID: target.ID, Time: time.Now()}); err != nil {
+		return Fact{}, err
+	}
+	// forget 기록만 덧붙이면 지운 값이 다음 compaction 전까지 파일에 남습니다.
+	if s.file != nil {
+		if err := s.compact(); err != nil {
+			return *target, fmt.Errorf("fact was forgotten but remains in the profile file until the next compaction: %w", err)
+		}
+	}
+	return *target, nil
+}
+
+func (s *Store) factCount() int {
+	n := 0
+	for _, p := range s.profiles {
+		n += len(p)
+	}
+	return n
+}
+
+// Close는 프로필 파일을 닫습니다.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// compact는 살아있는 사실만 담도록 파일을 다시 씁니다.
+func (s *Store) compact() error {
+	now := time.Now()
+	return s.file.Rewrite(func(enc *json.Encoder) error {
+		for k, p := range s.profiles {
+			for _, f := range p {
+				if err := enc.Encode(record{Op: "put", AppName: k.appName, UserID: k.userID, Fact: f, Time: now}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
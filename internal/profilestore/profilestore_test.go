@@ -0,0 +1,123 @@
+package profilestore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenRejectsPutWithoutFact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.jsonl")
+	data := `{"op":"put","appName":"app","userId":"alice","time":"2026-01-01T00:00:00Z"}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "corrupted") {
+		t.Errorf("Open = %v, want corruption error", err)
+	}
+}
+
+func TestUsersDoNotSeeEachOthersFacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { s.Close() }()
+
+	// 두 사용자가 같은 Key를 가져도 서로의 값을 덮어쓰지 않습니다.
+	facts := map[string]map[string]string{
+		"alice": {"name": "Alice", "favorite_food": "떡볶이"},
+		"bob":   {"name": "Bob", "pet": "고양이"},
+	}
+	ids := make(map[string]string) // 사용자 → name 사실의 ID
+	for user, kv := range facts {
+		for k, v := range kv {
+			f, _, err := s.Put("app", user, Input{Key: k, Value: v})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k == "name" {
+				ids[user] = f.ID
+			}
+		}
+	}
+
+	check := func(t *testing.T) {
+		for user, kv := range facts {
+			profile := s.Profile("app", user)
+			if len(profile) != len(kv) {
+				t.Errorf("Profile(%s) has %d facts, want %d", user, len(profile), len(kv))
+			}
+			for _, f := range profile {
+				if kv[f.Key] != f.Value {
+					t.Errorf("Profile(%s) has %s=%q, want %q", user, f.Key, f.Value, kv[f.Key])
+				}
+			}
+		}
+	}
+	t.Run("in memory", check)
+
+	// 다른 사용자의 사실은 ID로도 Key로도 지울 수 없습니다.
+	if _, err := s.Forget("app", "alice", ids["bob"]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Forget(bob's fact as alice) = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Forget("app", "bob", "favorite_food"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Forget(alice's key as bob) = %v, want ErrNotFound", err)
+	}
+
+	s.Close()
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("after reopen", check)
+}
+
+func TestForgetRemovesValueFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// 값을 한 번 바꿔 이전 값이 History에 남게 합니다.
+	for _, v := range []string{"마포구 합정동", "성동구 성수동"} {
+		if _, _, err := s.Put("app", "alice", Input{Key: "address", Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := s.Put("app", "alice", Input{Key: "name", Value: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Forget("app", "alice", "address"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"합정동", "성수동"} {
+		if strings.Contains(string(data), v) {
+			t.Errorf("forgotten value %q is still in the file:\n%s", v, data)
+		}
+	}
+	if !strings.Contains(string(data), "Alice") {
+		t.Errorf("remaining fact is missing from the file:\n%s", data)
+	}
+
+	// 다시 쓴 파일에도 계속 덧붙일 수 있습니다.
+	if _, _, err := s.Put("app", "alice", Input{Key: "pet", Value: "고양이"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Profile("app", "alice")); got != 2 {
+		t.Errorf("after reopen: %d facts, want 2", got)
+	}
+}
@@ -0,0 +1,223 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// 여러 줄 입력 중에 보여주는 프롬프트
+const continuationPrompt = "... "
+
+// lineReader는 프롬프트를 보여주고 한 줄을 읽습니다. pasted는 그 줄이 여러 줄 붙여넣기의 일부인지입니다.
+type lineReader interface {
+	readLine(prompt string) (line string, pasted bool, err error)
+}
+
+// readInput은 입력 하나를 읽습니다. 여러 줄 입력이면 줄들을 '\n'으로 이어 붙여 반환합니다.
+//   - 붙여넣은 줄들은 붙여넣기가 끝난 뒤 Enter를 칠 때까지 모읍니다.
+//   - 줄이 '\'로 끝나면 다음 줄로 이어집니다.
+//   - '"""'만 있는 줄로 시작하면 다시 '"""'만 있는 줄이 나올 때까지 모읍니다.
+//
+// 여러 줄 입력 중에 입력이 끝나면(Ctrl-D, 파일 끝) 그때까지 모은 줄을 버리지 않고 반환합니다.
+func (r *REPL) readInput(prompt string) (string, error) {
+	var lines []string
+	var block bool
+	for {
+		line, pasted, err := r.in.readLine(prompt)
+		if errors.Is(err, io.EOF) && (block || len(lines) > 0) {
+			return strings.Join(lines, "\n"), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		prompt = continuationPrompt
+
+		switch {
+		case block:
+			if strings.TrimSpace(line) == `"""` {
+				return strings.Join(lines, "\n"), nil
+			}
+			lines = append(lines, line)
+		case pasted:
+			lines = append(lines, line)
+		case len(lines) == 0 && strings.TrimSpace(line) == `"""`:
+			block = true
+		case strings.HasSuffix(line, `\`):
+			lines = append(lines, strings.TrimSuffix(line, `\`))
+		default:
+			if line != "" || len(lines) == 0 {
+				lines = append(lines, line)
+			}
+			return strings.Join(lines, "\n"), nil
+		}
+	}
+}
+
+// termReader는 x/term의 Terminal로 줄 편집과 입력 기록을 제공합니다.
+// 읽는 동안에만 터미널을 raw 모드로 두어, 에이전트가 실행되는 동안에는 Ctrl-C가 SIGINT로 전달되게 합니다.
+type termReader struct {
+	fd int
+	t  *term.Terminal
+}
+
+func newTermReader(in *os.File, out io.Writer, history *fileHistory) *termReader {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, "")
+	t.History = history
+	t.SetBracketedPasteMode(true)
+	return &termReader{fd: int(in.Fd()), t: t}
+}
+
+func (tr *termReader) readLine(prompt string) (string, bool, error) {
+	state, err := term.MakeRaw(tr.fd)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to set terminal raw mode: %w", err)
+	}
+	defer term.Restore(tr.fd, state)
+
+	if w, h, err := term.GetSize(tr.fd); err == nil && w > 0 {
+		tr.t.SetSize(w, h)
+	}
+	tr.t.SetPrompt(prompt)
+	line, err := tr.t.ReadLine()
+	if errors.Is(err, term.ErrPasteIndicator) {
+		return line, true, nil
+	}
+	// 프롬프트에서 누른 Ctrl-C와 Ctrl-D는 모두 io.EOF로 옵니다.
+	return line, false, err
+}
+
+// plainReader는 표준 입력이 터미널이 아닐 때 한 줄씩 읽습니다.
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPlainReader(in io.Reader, out io.Writer) *plainReader {
+	return &plainReader{in: bufio.NewReader(in), out: out}
+}
+
+func (pr *plainReader) readLine(prompt string) (string, bool, error) {
+	fmt.Fprint(pr.out, prompt)
+	line, err := pr.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		err = nil // 마지막 줄에 개행이 없으면 그 줄을 먼저 돌려주고, 다음 호출에서 io.EOF를 돌려줍니다.
+	}
+	return strings.TrimRight(line, "\r\n"), false, err
+}
+
+// fileHistory는 term.History를 구현합니다. 입력할 때마다 파일 끝에 한 줄씩 덧붙이고,
+// 다음 실행 때 파일의 마지막 size줄을 읽어 ↑/↓로 다시 쓸 수 있게 합니다.
+type fileHistory struct {
+	entries []string // 오래된 것부터
+	size    int
+	path    string
+	file    *os.File
+	written int // 파일의 줄 수. size의 2배를 넘으면 파일을 다시 씁니다.
+}
+
+func openHistory(path string, size int) (*fileHistory, error) {
+	h := &fileHistory{size: size, path: path}
+	if path == "" {
+		return h, nil
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create history dir: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	for line := range strings.Lines(string(data)) {
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			h.entries = append(h.entries, line)
+			h.written++
+		}
+	}
+	if len(h.entries) > size {
+		h.entries = h.entries[len(h.entries)-size:]
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	h.file = f
+	return h, nil
+}
+
+// Add는 Terminal.ReadLine이 읽은 줄을 기록합니다. 빈 줄과 바로 앞과 같은 줄은 기록하지 않습니다.
+func (h *fileHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.size {
+		h.entries = h.entries[len(h.entries)-h.size:]
+	}
+	if h.file == nil {
+		return
+	}
+	if _, err := h.file.WriteString(entry + "\n"); err != nil {
+		log.Printf("[repl] failed to write history: %v", err)
+		return
+	}
+	h.written++
+	if h.written > 2*h.size {
+		h.rewrite()
+	}
+}
+
+func (h *fileHistory) Len() int {
+	return len(h.entries)
+}
+
+// At의 0번은 가장 최근 입력입니다.
+func (h *fileHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// rewrite는 기억하는 기록만 남기도록 파일을 다시 씁니다.
+func (h *fileHistory) rewrite() {
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(h.entries, "\n")+"\n"), 0o600); err != nil {
+		log.Printf("[repl] failed to rewrite history: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		log.Printf("[repl] failed to rewrite history: %v", err)
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		log.Printf("[repl] failed to reopen history: %v", err)
+		h.file.Close()
+		h.file = nil
+		return
+	}
+	h.file.Close()
+	h.file = f
+	h.written = len(h.entries)
+}
+
+func (h *fileHistory) close() error {
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
@@ -0,0 +1,186 @@
+package repl
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// scriptLine은 fakeReader가 돌려줄 한 줄입니다.
+type scriptLine struct {
+	text   string
+	pasted bool
+}
+
+// fakeReader는 정해둔 줄을 차례로 돌려주고, 다 쓰면 io.EOF를 돌려줍니다. 받은 프롬프트도 기록합니다.
+type fakeReader struct {
+	lines   []scriptLine
+	prompts []string
+}
+
+func (f *fakeReader) readLine(prompt string) (string, bool, error) {
+	f.prompts = append(f.prompts, prompt)
+	if len(f.lines) == 0 {
+		return "", false, io.EOF
+	}
+	l := f.lines[0]
+	f.lines = f.lines[1:]
+	return l.text, l.pasted, nil
+}
+
+func typed(lines ...string) []scriptLine {
+	out := make([]scriptLine, len(lines))
+	for i, l := range lines {
+		out[i] = scriptLine{text: l}
+	}
+	return out
+}
+
+func TestReadInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []scriptLine
+		want  []string // readInput을 EOF까지 부른 결과
+	}{
+		{name: "한 줄씩", lines: typed("안녕", "잘 가"), want: []string{"안녕", "잘 가"}},
+		{name: "빈 줄도 입력 하나", lines: typed(""), want: []string{""}},
+		{name: "줄 끝의 역슬래시", lines: typed(`첫 줄\`, `둘째 줄\`, "셋째 줄"), want: []string{"첫 줄\n둘째 줄\n셋째 줄"}},
+		{name: "역슬래시 뒤 빈 줄로 끝내기", lines: typed(`첫 줄\`, ""), want: []string{"첫 줄"}},
+		{name: "따옴표 블록", lines: typed(`"""`, "func main() {", "", "}", ` """ `, "다음"), want: []string{"func main() {\n\n}", "다음"}},
+		{name: "블록 안의 역슬래시는 그대로", lines: typed(`"""`, `C:\`, `"""`), want: []string{`C:\`}},
+		{
+			name:  "붙여넣기는 Enter까지 모음",
+			lines: []scriptLine{{text: "하나", pasted: true}, {text: "둘", pasted: true}, {text: ""}},
+			want:  []string{"하나\n둘"},
+		},
+		{name: "끝나지 않은 블록도 버리지 않음", lines: typed(`"""`, "첫 줄", "둘째 줄"), want: []string{"첫 줄\n둘째 줄"}},
+		{name: "끝나지 않은 역슬래시 줄도 버리지 않음", lines: typed(`첫 줄\`), want: []string{"첫 줄"}},
+		{name: "블록을 열자마자 끝남", lines: typed(`"""`), want: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &fakeReader{lines: tt.lines}
+			r := &REPL{in: in}
+			var got []string
+			for {
+				input, err := r.readInput("> ")
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, input)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("inputs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadInputContinuationPrompt(t *testing.T) {
+	in := &fakeReader{lines: typed(`a\`, `b\`, "c")}
+	r := &REPL{in: in}
+	if _, err := r.readInput("> "); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"> ", continuationPrompt, continuationPrompt}; !slices.Equal(in.prompts, want) {
+		t.Errorf("prompts = %q, want %q", in.prompts, want)
+	}
+}
+
+func TestPlainReader(t *testing.T) {
+	var out strings.Builder
+	pr := newPlainReader(strings.NewReader("첫 줄\r\n마지막 줄"), &out)
+	for _, want := range []string{"첫 줄", "마지막 줄"} {
+		line, pasted, err := pr.readLine("> ")
+		if err != nil || pasted || line != want {
+			t.Fatalf("readLine = %q, %v, %v; want %q", line, pasted, err, want)
+		}
+	}
+	// 개행 없는 마지막 줄을 돌려준 뒤에 io.EOF가 옵니다.
+	if _, _, err := pr.readLine("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("after the last line: err = %v, want io.EOF", err)
+	}
+	if out.String() != "> > > " {
+		t.Errorf("prompts written = %q", out.String())
+	}
+}
+
+func historyEntries(h *fileHistory) []string {
+	out := make([]string, h.Len())
+	for i := range out {
+		out[i] = h.At(i)
+	}
+	return out
+}
+
+func TestFileHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history")
+	h, err := openHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []string{"하나", "둘", "둘", "  ", "셋"} {
+		h.Add(entry)
+	}
+	// At(0)이 가장 최근이고, 빈 줄과 바로 앞과 같은 줄은 기록하지 않습니다.
+	if got := historyEntries(h); !slices.Equal(got, []string{"셋", "둘", "하나"}) {
+		t.Errorf("entries = %q", got)
+	}
+	h.Add("넷")
+	if got := historyEntries(h); !slices.Equal(got, []string{"넷", "셋", "둘"}) {
+		t.Errorf("entries beyond size = %q", got)
+	}
+	if err := h.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 다시 열면 파일의 마지막 size줄을 이어서 씁니다.
+	h, err = openHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.close()
+	if got := historyEntries(h); !slices.Equal(got, []string{"넷", "셋", "둘"}) {
+		t.Errorf("reopened entries = %q", got)
+	}
+
+	// 파일 줄 수가 size의 2배를 넘으면 기억하는 기록만 남도록 다시 씁니다.
+	for _, entry := range []string{"다섯", "여섯", "일곱"} {
+		h.Add(entry)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "다섯\n여섯\n일곱\n" {
+		t.Errorf("rewritten file = %q", got)
+	}
+	h.Add("여덟")
+	if data, _ := os.ReadFile(path); string(data) != "다섯\n여섯\n일곱\n여덟\n" {
+		t.Errorf("append after rewrite = %q", data)
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary history file was left behind: %v", err)
+	}
+}
+
+func TestFileHistoryWithoutFile(t *testing.T) {
+	h, err := openHistory("", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Add("하나")
+	if h.Len() != 1 || h.At(0) != "하나" {
+		t.Errorf("entries = %q", historyEntries(h))
+	}
+	if err := h.close(); err != nil {
+		t.Error(err)
+	}
+}
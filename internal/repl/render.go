@@ -0,0 +1,92 @@
+package repl
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/transcript"
+)
+
+const (
+	colorRed    = "31"
+	colorYellow = "33"
+	colorCyan   = "36"
+	colorDim    = "2"
+)
+
+// 도구 인자와 결과는 이 글자 수까지만 보여줍니다.
+const maxToolTextRunes = 200
+
+func (r *REPL) paint(color, text string) string {
+	if !r.color {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
+
+// Render는 runner.Run이 돌려준 이벤트를 받는 대로 출력합니다.
+//   - 스트리밍(agent.StreamingModeSSE)의 부분 이벤트(Partial)는 텍스트가 오는 대로 이어서 출력하고,
+//     같은 내용이 모인 마지막 이벤트는 다시 출력하지 않습니다.
+//   - 도구 호출과 결과는 "⚙ 이름(인자)", "↳ 이름: 결과"로 따로 표시합니다.
+//   - 생각(thought) 텍스트는 흐리게, 빈 파트는 출력하지 않습니다.
+//
+// 실행 중 오류가 나면(Ctrl-C로 취소된 경우 포함) 그 오류를 반환합니다.
+func (r *REPL) Render(events iter.Seq2[*session.Event, error]) error {
+	var streamed bool   // 마지막 완성 이벤트 이후 부분 텍스트를 출력했는지
+	lineStart := true   // 커서가 줄의 처음에 있는지
+	newline := func() { // 도구 표시처럼 한 줄을 차지하는 출력 전에 줄을 바꿉니다.
+		if !lineStart {
+			fmt.Fprintln(r.out)
+			lineStart = true
+		}
+	}
+	printText := func(p *genai.Part) {
+		if p.Text == "" {
+			return
+		}
+		if p.Thought {
+			fmt.Fprint(r.out, r.paint(colorDim, p.Text))
+		} else {
+			fmt.Fprint(r.out, p.Text)
+		}
+		lineStart = strings.HasSuffix(p.Text, "\n")
+	}
+
+	for event, err := range events {
+		if err != nil {
+			newline()
+			return err
+		}
+		if event.Content == nil {
+			continue
+		}
+
+		if event.Partial {
+			for _, p := range event.Content.Parts {
+				printText(p)
+				streamed = streamed || p.Text != ""
+			}
+			continue
+		}
+
+		for _, p := range event.Content.Parts {
+			switch {
+			case p.FunctionCall != nil:
+				newline()
+				fmt.Fprintln(r.out, r.paint(colorCyan, transcript.CallLine(p.FunctionCall.Name, p.FunctionCall.Args, maxToolTextRunes)))
+			case p.FunctionResponse != nil:
+				newline()
+				fmt.Fprintln(r.out, r.paint(colorDim, transcript.ResultLine(p.FunctionResponse.Name, p.FunctionResponse.Response, maxToolTextRunes)))
+			case !streamed:
+				printText(p)
+			}
+		}
+		streamed = false
+	}
+	newline()
+	return nil
+}
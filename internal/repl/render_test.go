@@ -0,0 +1,105 @@
+package repl
+
+import (
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func event(partial bool, parts ...*genai.Part) *session.Event {
+	e := session.NewEvent("inv-1")
+	e.LLMResponse = model.LLMResponse{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}, Partial: partial}
+	return e
+}
+
+func events(list ...*session.Event) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		for _, e := range list {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	call := &genai.Part{FunctionCall: &genai.FunctionCall{Name: "search", Args: map[string]any{"query": "이름"}}}
+	result := &genai.Part{FunctionResponse: &genai.FunctionResponse{Name: "search", Response: map[string]any{"result": "민수"}}}
+
+	tests := []struct {
+		name   string
+		events []*session.Event
+		want   string
+	}{
+		{
+			name: "스트리밍 조각은 이어서 출력하고 마지막 이벤트는 다시 출력하지 않음",
+			events: []*session.Event{
+				event(true, genai.NewPartFromText("안녕")),
+				event(true, genai.NewPartFromText("하세요")),
+				event(false, genai.NewPartFromText("안녕하세요")),
+			},
+			want: "안녕하세요\n",
+		},
+		{
+			name:   "스트리밍이 아니면 완성 이벤트를 출력",
+			events: []*session.Event{event(false, genai.NewPartFromText("답변입니다"))},
+			want:   "답변입니다\n",
+		},
+		{
+			name: "도구 호출과 결과는 따로 한 줄씩",
+			events: []*session.Event{
+				event(true, genai.NewPartFromText("찾아볼게요")),
+				event(false, genai.NewPartFromText("찾아볼게요"), call),
+				event(false, result),
+				event(false, genai.NewPartFromText("민수님이에요.\n")),
+			},
+			want: "찾아볼게요\n⚙ search({\"query\":\"이름\"})\n↳ search: {\"result\":\"민수\"}\n민수님이에요.\n",
+		},
+		{
+			name:   "빈 텍스트와 내용 없는 이벤트는 출력하지 않음",
+			events: []*session.Event{event(false, genai.NewPartFromText("")), session.NewEvent("inv-1")},
+			want:   "",
+		},
+		{
+			name:   "생각도 출력 (색은 터미널에서만)",
+			events: []*session.Event{event(false, &genai.Part{Text: "고민 중", Thought: true})},
+			want:   "고민 중\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			r := &REPL{out: &out}
+			if err := r.Render(events(tt.events...)); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderColorAndError(t *testing.T) {
+	var out strings.Builder
+	r := &REPL{out: &out, color: true}
+	failure := errors.New("model unavailable")
+	err := r.Render(func(yield func(*session.Event, error) bool) {
+		if !yield(event(false, &genai.Part{Text: "생각", Thought: true}), nil) {
+			return
+		}
+		yield(nil, failure)
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("err = %v, want %v", err, failure)
+	}
+	// 생각은 흐리게 칠하고, 오류가 나도 줄을 바꾼 뒤 반환합니다.
+	if want := "\x1b[2m생각\x1b[0m\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
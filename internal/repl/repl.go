@@ -0,0 +1,194 @@
+// Package repl은 에이전트와 터미널에서 대화하는 REPL(Read-Eval-Print Loop)입니다.
+//
+// bufio.Scanner로 한 줄씩 읽던 루프를 대신해 다음을 제공합니다.
+//   - 줄 편집(←/→, Ctrl-A/E/K/U/W)과 ↑/↓ 입력 기록. 기록은 파일에 남아 다음 실행에도 이어집니다.
+//   - 여러 줄 입력: 붙여넣은 여러 줄, 줄 끝의 '\', '"""'로 감싼 블록
+//   - '/'로 시작하는 명령(AddCommand)과 기본 명령 /help, /exit
+//   - Ctrl-C: 실행 중인 턴의 context만 취소하고 프롬프트로 돌아옵니다. 프롬프트에서는 Ctrl-D(또는 exit)로 끝냅니다.
+//   - Render: 모델 답변을 스트리밍으로 출력하고, 도구 호출과 결과는 따로 표시합니다.
+//
+// 표준 입력이 터미널이 아니면(파이프, 리다이렉트) 줄 편집 없이 한 줄씩 읽습니다.
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrUsage를 명령이 반환하면 REPL이 그 명령의 사용법을 보여줍니다.
+var ErrUsage = errors.New("invalid command usage")
+
+// Command는 '/'로 시작하는 REPL 명령입니다.
+type Command struct {
+	// Name은 '/'를 포함한 명령 이름입니다. 예: "/new"
+	Name string
+	// Usage는 도움말에 보여줄 사용법입니다. 비어 있으면 Name을 씁니다. 예: "/resume <id>"
+	Usage string
+	// Help는 한 줄 설명입니다.
+	Help string
+	// Run은 명령 이름 뒤의 인자들(공백으로 나눈 것)을 받아 명령을 실행합니다.
+	Run func(ctx context.Context, args []string) error
+}
+
+// Config는 REPL 설정입니다.
+type Config struct {
+	// Prompt는 입력을 받을 때마다 불러서 프롬프트를 정합니다. 현재 사용자처럼 바뀌는 값을 보여줄 때 씁니다.
+	// 비어 있으면 "User: "를 씁니다.
+	Prompt func() string
+	// HistoryFile은 입력 기록을 남길 파일입니다. 비어 있으면 기록을 파일에 남기지 않습니다.
+	HistoryFile string
+	// HistorySize는 기억할 입력 기록 수입니다. 0이면 1000입니다.
+	HistorySize int
+}
+
+// REPL은 입력을 읽어 명령은 직접 실행하고, 나머지는 핸들러에 넘기는 루프입니다.
+type REPL struct {
+	cfg      Config
+	in       lineReader
+	out      io.Writer
+	color    bool
+	history  *fileHistory
+	commands map[string]Command
+}
+
+// New는 표준 입출력을 쓰는 REPL을 만듭니다. 다 쓰면 Close를 불러 입력 기록 파일을 닫아야 합니다.
+func New(cfg Config) (*REPL, error) {
+	if cfg.Prompt == nil {
+		cfg.Prompt = func() string { return "User: " }
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = 1000
+	}
+
+	history, err := openHistory(cfg.HistoryFile, cfg.HistorySize)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &REPL{cfg: cfg, out: os.Stdout, history: history, commands: make(map[string]Command)}
+	interactive := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	if interactive {
+		r.in = newTermReader(os.Stdin, os.Stdout, history)
+		r.color = os.Getenv("NO_COLOR") == ""
+	} else {
+		r.in = newPlainReader(os.Stdin, os.Stdout)
+	}
+
+	r.AddCommand(Command{Name: "/help", Help: "명령 목록", Run: func(context.Context, []string) error {
+		r.printHelp()
+		return nil
+	}})
+	return r, nil
+}
+
+// AddCommand는 명령을 등록합니다. 같은 이름의 명령이 있으면 바꿉니다.
+func (r *REPL) AddCommand(cmd Command) {
+	r.commands[cmd.Name] = cmd
+}
+
+// Out은 REPL이 출력하는 곳(표준 출력)입니다.
+func (r *REPL) Out() io.Writer {
+	return r.out
+}
+
+// Run은 ctx가 끝나거나 사용자가 끝낼 때까지 입력을 읽습니다.
+// '/'로 시작하는 입력은 등록된 명령으로 실행하고, 나머지는 handle에 넘깁니다.
+// handle이 받는 context는 Ctrl-C를 누르면 취소되므로, 모델 호출처럼 오래 걸리는 일에 그대로 넘기면 됩니다.
+func (r *REPL) Run(ctx context.Context, handle func(ctx context.Context, input string) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		input, err := r.readInput(r.cfg.Prompt())
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		trimmed := strings.TrimSpace(input)
+		switch {
+		case trimmed == "":
+			continue
+		case trimmed == "exit" || trimmed == "quit" || trimmed == "/exit":
+			return nil
+		case strings.HasPrefix(trimmed, "/"):
+			r.runCommand(ctx, trimmed)
+		default:
+			r.runTurn(ctx, input, handle)
+		}
+	}
+}
+
+// runTurn은 handle을 실행하는 동안만 Ctrl-C(SIGINT)를 받아 턴의 context를 취소합니다.
+// 프롬프트에서는 터미널이 raw 모드라 Ctrl-C가 시그널이 되지 않습니다.
+func (r *REPL) runTurn(ctx context.Context, input string, handle func(ctx context.Context, input string) error) {
+	turnCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	err := handle(turnCtx, input)
+	switch {
+	case turnCtx.Err() != nil && ctx.Err() == nil:
+		fmt.Fprintln(r.out, r.paint(colorYellow, "\n⏹ 실행을 취소했습니다."))
+	case err != nil:
+		fmt.Fprintln(r.out, r.paint(colorRed, "⚠ 오류: "+err.Error()))
+	}
+}
+
+func (r *REPL) runCommand(ctx context.Context, input string) {
+	fields := strings.Fields(input)
+	cmd, ok := r.commands[fields[0]]
+	if !ok {
+		fmt.Fprintf(r.out, "알 수 없는 명령입니다: %s\n", fields[0])
+		r.printHelp()
+		return
+	}
+	err := cmd.Run(ctx, fields[1:])
+	switch {
+	case errors.Is(err, ErrUsage):
+		fmt.Fprintf(r.out, "사용법: %s\n", cmd.usage())
+	case err != nil:
+		fmt.Fprintln(r.out, r.paint(colorRed, "⚠ "+err.Error()))
+	}
+}
+
+func (c Command) usage() string {
+	if c.Usage != "" {
+		return c.Usage
+	}
+	return c.Name
+}
+
+func (r *REPL) printHelp() {
+	cmds := make([]Command, 0, len(r.commands)+1)
+	for _, c := range r.commands {
+		cmds = append(cmds, c)
+	}
+	cmds = append(cmds, Command{Name: "/exit", Help: "종료 (exit, Ctrl-D도 됩니다)"})
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+
+	width := 0
+	for _, c := range cmds {
+		width = max(width, len(c.usage()))
+	}
+	fmt.Fprintln(r.out, "명령:")
+	for _, c := range cmds {
+		fmt.Fprintf(r.out, "  %-*s  %s\n", width, c.usage(), c.Help)
+	}
+	fmt.Fprintln(r.out, `여러 줄 입력: 줄 끝에 \ 를 붙이거나 """ 로 감싸세요. 실행 중 Ctrl-C는 그 턴만 취소합니다.`)
+}
+
+// Close는 입력 기록 파일을 닫습니다.
+func (r *REPL) Close() error {
+	return r.history.close()
+}
@@ -0,0 +1,62 @@
+package replay
+
+// row는 나란히 보기(side-by-side)의 한 줄입니다.
+// op는 " "(같음), "~"(바뀜), "-"(이전에만 있음), "+"(새 버전에만 있음)입니다.
+type row struct {
+	op          string
+	left, right string
+}
+
+// sideBySide는 두 줄 목록의 최장 공통 부분열(LCS)을 구해 나란히 놓습니다.
+// 이어서 지워지고 추가된 줄은 한 줄씩 짝지어 "~"로 보여줍니다.
+func sideBySide(a, b []string) []row {
+	// lcs[i][j]는 a[i:]와 b[j:]의 LCS 길이
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var rows []row
+	var removed, added []string
+	flush := func() {
+		for k := 0; k < max(len(removed), len(added)); k++ {
+			switch {
+			case k < len(removed) && k < len(added):
+				rows = append(rows, row{op: "~", left: removed[k], right: added[k]})
+			case k < len(removed):
+				rows = append(rows, row{op: "-", left: removed[k]})
+			default:
+				rows = append(rows, row{op: "+", right: added[k]})
+			}
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			rows = append(rows, row{op: " ", left: a[i], right: b[j]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+	return rows
+}
@@ -0,0 +1,66 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSideBySide(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []row
+	}{
+		{
+			name: "같음",
+			a:    []string{"x", "y"},
+			b:    []string{"x", "y"},
+			want: []row{{" ", "x", "x"}, {" ", "y", "y"}},
+		},
+		{
+			name: "새 버전에 추가",
+			a:    []string{"x", "z"},
+			b:    []string{"x", "y", "z"},
+			want: []row{{" ", "x", "x"}, {"+", "", "y"}, {" ", "z", "z"}},
+		},
+		{
+			name: "이전에만 있음",
+			a:    []string{"x", "y", "z"},
+			b:    []string{"x", "z"},
+			want: []row{{" ", "x", "x"}, {"-", "y", ""}, {" ", "z", "z"}},
+		},
+		{
+			// 이어서 지워지고 추가된 줄은 한 줄씩 짝지어 "~"로 보여줍니다.
+			name: "바뀜",
+			a:    []string{"x", "old", "z"},
+			b:    []string{"x", "new", "z"},
+			want: []row{{" ", "x", "x"}, {"~", "old", "new"}, {" ", "z", "z"}},
+		},
+		{
+			name: "짝이 모자라면 나머지는 추가",
+			a:    []string{"old"},
+			b:    []string{"new1", "new2"},
+			want: []row{{"~", "old", "new1"}, {"+", "", "new2"}},
+		},
+		{
+			name: "짝이 모자라면 나머지는 삭제",
+			a:    []string{"x", "old1", "old2"},
+			b:    []string{"x", "new"},
+			want: []row{{" ", "x", "x"}, {"~", "old1", "new"}, {"-", "old2", ""}},
+		},
+		{
+			name: "한쪽이 비어 있음",
+			a:    nil,
+			b:    []string{"y"},
+			want: []row{{"+", "", "y"}},
+		},
+		{name: "둘 다 비어 있음"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sideBySide(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sideBySide(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package replay는 기록된 대화(대화록)의 사용자 발화를 새 버전의 에이전트로 다시 실행하고,
+// 이전 답변/도구 호출과 새 답변/도구 호출을 턴마다 나란히 비교합니다.
+//
+// 프롬프트(지시문)나 모델을 바꿨을 때, 예전에 잘 되던 대화가 여전히 잘 되는지(회귀가 없는지) 확인하는 데 씁니다.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/transcript"
+)
+
+// Turn은 기록된 대화의 턴 하나입니다. 사용자 발화 하나와 그 뒤에 나온 에이전트의 이벤트들입니다.
+type Turn struct {
+	Input   string
+	Entries []transcript.Entry
+}
+
+// Turns는 대화록을 턴으로 나눕니다. 첫 사용자 발화 전의 이벤트(예: 요약으로 압축된 이전 대화)는 다시 실행할 수 없으므로
+// 턴에 넣지 않고 그 개수를 skipped로 반환합니다.
+func Turns(t *transcript.Transcript) (turns []Turn, skipped int) {
+	for _, e := range t.Entries {
+		switch {
+		case e.IsUser() && e.Text != "":
+			turns = append(turns, Turn{Input: e.Text})
+		case len(turns) == 0:
+			skipped++
+		default:
+			turns[len(turns)-1].Entries = append(turns[len(turns)-1].Entries, e)
+		}
+	}
+	return turns, skipped
+}
+
+// Config는 새 버전의 에이전트를 실행할 환경입니다.
+// Runner는 원래 대화에 영향을 주지 않도록 따로 만든 세션/기억 서비스를 써야 합니다.
+type Config struct {
+	Runner   *runner.Runner
+	Sessions session.Service
+	AppName  string
+	UserID   string
+	// Label은 보고서에 새 버전을 부르는 이름입니다. 예: "instruction=v2.txt"
+	Label string
+	// AfterTurn은 턴마다 에이전트 실행이 끝난 뒤 불립니다. 기억 저장처럼 원래 대화 루프가 턴 뒤에 하던 일을 합니다.
+	AfterTurn func(ctx context.Context, s session.Session) error
+	// OnTurn은 턴 하나를 다시 실행할 때마다 불립니다. 진행 상황을 출력할 때 씁니다.
+	OnTurn func(i int, r TurnResult)
+}
+
+// TurnResult는 턴 하나의 이전 결과와 새 결과입니다.
+type TurnResult struct {
+	Input string
+	Old   []transcript.Entry
+	New   []transcript.Entry
+	// Err는 새 버전을 실행하다 난 오류입니다. 오류가 나도 다음 턴은 계속 실행합니다.
+	Err string
+}
+
+// Run은 old의 사용자 발화를 순서대로 새 세션 하나에서 다시 실행합니다.
+func Run(ctx context.Context, cfg Config, old *transcript.Transcript) (*Report, error) {
+	turns, skipped := Turns(old)
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("transcript %s has no user turns to replay", old.SessionID)
+	}
+
+	created, err := cfg.Sessions.Create(ctx, &session.CreateRequest{AppName: cfg.AppName, UserID: cfg.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay session: %w", err)
+	}
+	sessionID := created.Session.ID()
+
+	report := &Report{Source: old.SessionID, Label: cfg.Label, Skipped: skipped, StartedAt: time.Now()}
+	for i, turn := range turns {
+		res := TurnResult{Input: turn.Input, Old: turn.Entries}
+		msg := genai.NewContentFromText(turn.Input, genai.RoleUser)
+		for event, err := range cfg.Runner.Run(ctx, cfg.UserID, sessionID, msg, agent.RunConfig{}) {
+			if err != nil {
+				res.Err = err.Error()
+				break
+			}
+			if !event.Partial {
+				res.New = append(res.New, transcript.EntryFromEvent(event))
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if cfg.AfterTurn != nil && res.Err == "" {
+			resp, err := cfg.Sessions.Get(ctx, &session.GetRequest{AppName: cfg.AppName, UserID: cfg.UserID, SessionID: sessionID})
+			if err == nil {
+				err = cfg.AfterTurn(ctx, resp.Session)
+			}
+			if err != nil {
+				res.Err = fmt.Sprintf("after turn: %v", err)
+			}
+		}
+
+		report.Turns = append(report.Turns, res)
+		if cfg.OnTurn != nil {
+			cfg.OnTurn(i, res)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,180 @@
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"awesomeProject2/internal/transcript"
+)
+
+// Status는 턴 하나의 비교 결과입니다. 뒤에 있을수록 심각합니다.
+type Status int
+
+const (
+	Same         Status = iota // 도구 호출과 답변이 모두 같음
+	TextChanged                // 도구 호출은 같고 답변 문장만 다름
+	ArgsChanged                // 같은 도구를 같은 순서로 불렀지만 인자가 다름
+	ToolsChanged               // 부른 도구(이름이나 순서)가 다름
+	Failed                     // 새 버전 실행 중 오류
+)
+
+var statusLabels = map[Status]string{
+	Same:         "✅ 같음",
+	TextChanged:  "📝 답변만 다름",
+	ArgsChanged:  "🔧 도구 인자 다름",
+	ToolsChanged: "⚠️ 도구 호출 다름",
+	Failed:       "❌ 오류",
+}
+
+func (s Status) String() string {
+	return statusLabels[s]
+}
+
+// Status는 이전 결과와 새 결과를 비교합니다.
+// 모델의 답변은 같은 입력에도 표현이 조금씩 달라지므로, 회귀는 주로 도구 호출이 달라졌는지로 판단합니다.
+func (r TurnResult) Status() Status {
+	switch {
+	case r.Err != "":
+		return Failed
+	case !slices.Equal(toolNames(r.Old), toolNames(r.New)):
+		return ToolsChanged
+	case !slices.Equal(toolCalls(r.Old), toolCalls(r.New)):
+		return ArgsChanged
+	case answer(r.Old) != answer(r.New):
+		return TextChanged
+	}
+	return Same
+}
+
+func toolNames(entries []transcript.Entry) []string {
+	var names []string
+	for _, e := range entries {
+		for _, c := range e.ToolCalls {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+func toolCalls(entries []transcript.Entry) []string {
+	var calls []string
+	for _, e := range entries {
+		for _, c := range e.ToolCalls {
+			calls = append(calls, transcript.CallLine(c.Name, c.Args, 0))
+		}
+	}
+	return calls
+}
+
+// answer는 에이전트가 사용자에게 한 말(텍스트)을 모두 이어 붙입니다.
+func answer(entries []transcript.Entry) string {
+	var texts []string
+	for _, e := range entries {
+		if e.Text != "" {
+			texts = append(texts, strings.TrimSpace(e.Text))
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// 도구 결과는 이 글자 수까지만 보여줍니다.
+const maxResultRunes = 120
+
+// lines는 비교할 수 있도록 턴의 이벤트들을 줄 단위로 펼칩니다.
+func lines(entries []transcript.Entry) []string {
+	var out []string
+	for _, e := range entries {
+		for _, c := range e.ToolCalls {
+			out = append(out, transcript.CallLine(c.Name, c.Args, 0))
+		}
+		for _, res := range e.ToolResults {
+			out = append(out, transcript.ResultLine(res.Name, res.Response, maxResultRunes))
+		}
+		if e.Text != "" {
+			for line := range strings.Lines(strings.TrimSpace(e.Text)) {
+				if line = strings.TrimRight(line, "\r\n"); strings.TrimSpace(line) != "" {
+					out = append(out, line)
+				}
+			}
+		}
+		if e.Error != "" {
+			out = append(out, "⚠ "+e.Error)
+		}
+	}
+	return out
+}
+
+// Report는 리플레이 전체 결과입니다.
+type Report struct {
+	Source    string // 원래 세션 ID
+	Label     string
+	Skipped   int
+	StartedAt time.Time
+	Turns     []TurnResult
+}
+
+// Count는 상태별 턴 수입니다.
+func (r *Report) Count() map[Status]int {
+	counts := make(map[Status]int)
+	for _, t := range r.Turns {
+		counts[t.Status()]++
+	}
+	return counts
+}
+
+// Regressions는 도구 호출이 달라졌거나 오류가 난 턴 수입니다.
+func (r *Report) Regressions() int {
+	counts := r.Count()
+	return counts[ToolsChanged] + counts[Failed]
+}
+
+// Summary는 상태별 턴 수를 한 줄로 보여줍니다.
+func (r *Report) Summary() string {
+	counts := r.Count()
+	var parts []string
+	for s := Same; s <= Failed; s++ {
+		parts = append(parts, fmt.Sprintf("%s %d", s, counts[s]))
+	}
+	return fmt.Sprintf("턴 %d개: %s", len(r.Turns), strings.Join(parts, ", "))
+}
+
+// WriteMarkdown은 턴마다 이전 결과와 새 결과를 나란히 놓은 표를 씁니다.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# 리플레이 보고서: %s\n\n", r.Source)
+	fmt.Fprintf(bw, "- 새 버전: %s\n- 실행 시각: %s\n- %s\n", r.Label, r.StartedAt.Format(time.DateTime), r.Summary())
+	if r.Skipped > 0 {
+		fmt.Fprintf(bw, "- 첫 사용자 발화 전의 이벤트 %d개(요약된 이전 대화 등)는 다시 실행하지 않았습니다.\n", r.Skipped)
+	}
+
+	fmt.Fprintf(bw, "\n| # | 사용자 입력 | 결과 |\n|---|---|---|\n")
+	for i, t := range r.Turns {
+		fmt.Fprintf(bw, "| [%d](#턴-%d) | %s | %s |\n", i+1, i+1, cell(transcript.Truncate(t.Input, 60)), t.Status())
+	}
+
+	for i, t := range r.Turns {
+		fmt.Fprintf(bw, "\n## 턴 %d\n\n%s\n\n", i+1, t.Status())
+		for line := range strings.Lines(t.Input) {
+			fmt.Fprintf(bw, "> %s", line)
+		}
+		fmt.Fprintf(bw, "\n\n")
+		if t.Err != "" {
+			fmt.Fprintf(bw, "오류: `%s`\n\n", t.Err)
+		}
+		fmt.Fprintf(bw, "| | 이전 | 새 버전 |\n|---|---|---|\n")
+		for _, row := range sideBySide(lines(t.Old), lines(t.New)) {
+			fmt.Fprintf(bw, "| %s | %s | %s |\n", row.op, cell(row.left), cell(row.right))
+		}
+	}
+	return bw.Flush()
+}
+
+// cell은 표 칸 안에 넣을 수 있게 '|'를 이스케이프합니다.
+func cell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
@@ -0,0 +1,116 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+
+	"awesomeProject2/internal/transcript"
+)
+
+func call(name string, args map[string]any) transcript.Entry {
+	return transcript.Entry{Author: "agent", ToolCalls: []transcript.ToolCall{{Name: name, Args: args}}}
+}
+
+func text(s string) transcript.Entry {
+	return transcript.Entry{Author: "agent", Text: s}
+}
+
+func TestTurns(t *testing.T) {
+	tr := &transcript.Transcript{SessionID: "s1", Entries: []transcript.Entry{
+		// 첫 사용자 발화 전의 이벤트(요약된 이전 대화)는 건너뜁니다.
+		text("이전 대화 요약"),
+		{Author: "user", Text: "부산 날씨"},
+		call("get_weather", map[string]any{"city": "부산"}),
+		text("맑아요"),
+		// 텍스트가 없는 사용자 이벤트(도구 결과 등)는 새 턴이 아닙니다.
+		{Author: "user", ToolResults: []transcript.ToolResult{{Name: "get_weather"}}},
+		{Author: "user", Text: "고마워"},
+		text("천만에요"),
+	}}
+	turns, skipped := Turns(tr)
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1", skipped)
+	}
+	if len(turns) != 2 {
+		t.Fatalf("got %d turns, want 2", len(turns))
+	}
+	if turns[0].Input != "부산 날씨" || len(turns[0].Entries) != 3 {
+		t.Errorf("turn 1 = %q with %d entries, want 3", turns[0].Input, len(turns[0].Entries))
+	}
+	if turns[1].Input != "고마워" || len(turns[1].Entries) != 1 || turns[1].Entries[0].Text != "천만에요" {
+		t.Errorf("turn 2 = %+v", turns[1])
+	}
+
+	if turns, skipped := Turns(&transcript.Transcript{Entries: []transcript.Entry{text("a"), text("b")}}); len(turns) != 0 || skipped != 2 {
+		t.Errorf("no user turns: %d turns, %d skipped", len(turns), skipped)
+	}
+}
+
+func TestTurnResultStatus(t *testing.T) {
+	seoul := call("get_weather", map[string]any{"city": "서울"})
+	busan := call("get_weather", map[string]any{"city": "부산"})
+	tests := []struct {
+		name string
+		res  TurnResult
+		want Status
+	}{
+		{"같음", TurnResult{Old: []transcript.Entry{seoul, text("맑아요")}, New: []transcript.Entry{seoul, text(" 맑아요\n")}}, Same},
+		{"답변만 다름", TurnResult{Old: []transcript.Entry{seoul, text("맑아요")}, New: []transcript.Entry{seoul, text("맑습니다")}}, TextChanged},
+		{"인자 다름", TurnResult{Old: []transcript.Entry{seoul, text("맑아요")}, New: []transcript.Entry{busan, text("맑아요")}}, ArgsChanged},
+		{"도구 추가", TurnResult{Old: []transcript.Entry{text("맑아요")}, New: []transcript.Entry{seoul, text("맑아요")}}, ToolsChanged},
+		{"도구 순서 다름", TurnResult{
+			Old: []transcript.Entry{seoul, call("get_time", nil)},
+			New: []transcript.Entry{call("get_time", nil), seoul},
+		}, ToolsChanged},
+		{"오류", TurnResult{Old: []transcript.Entry{seoul}, New: []transcript.Entry{seoul}, Err: "quota exceeded"}, Failed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.res.Status(); got != tt.want {
+				t.Errorf("status = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	r := &Report{Source: "s1", Label: "v2"}
+	for _, tt := range tests {
+		r.Turns = append(r.Turns, tt.res)
+	}
+	// 도구 호출이 달라진 두 턴과 오류 한 턴이 회귀입니다.
+	if n := r.Regressions(); n != 3 {
+		t.Errorf("regressions = %d, want 3", n)
+	}
+	if got, want := r.Summary(), "턴 6개: ✅ 같음 1, 📝 답변만 다름 1, 🔧 도구 인자 다름 1, ⚠️ 도구 호출 다름 2, ❌ 오류 1"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r := &Report{Source: "s1", Label: "v2", Skipped: 2, Turns: []TurnResult{{
+		Input: "부산 | 날씨",
+		Old: []transcript.Entry{
+			call("get_weather", map[string]any{"city": "서울"}),
+			{Author: "agent", ToolResults: []transcript.ToolResult{{Name: "get_weather", Response: map[string]any{"sky": strings.Repeat("맑", 200)}}}},
+			text("맑아요"),
+		},
+		New: []transcript.Entry{call("get_weather", map[string]any{"city": "부산"}), text("맑아요")},
+	}}}
+	var b strings.Builder
+	if err := r.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# 리플레이 보고서: s1\n",
+		"이벤트 2개(요약된 이전 대화 등)는 다시 실행하지 않았습니다",
+		"| [1](#턴-1) | 부산 \\| 날씨 | 🔧 도구 인자 다름 |",
+		`| ~ | ⚙ get_weather({"city":"서울"}) | ⚙ get_weather({"city":"부산"}) |`,
+		// 도구 결과는 maxResultRunes 글자까지만 보여줍니다.
+		"| - | ↳ get_weather: {\"sky\":\"" + strings.Repeat("맑", maxResultRunes-8) + "… |  |",
+		"|   | 맑아요 | 맑아요 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// Decode는 에이전트의 최종 답변(JSON)을 For[T]의 스키마로 검사한 뒤 T로 읽습니다.
+// 필수 필드가 빠졌거나, 타입이 다르거나, enum에 없는 값이거나, 숫자가 범위를 벗어나면 어느 필드인지 알려주는 오류를 반환합니다.
+// 모델이 JSON을 ```json 코드 블록으로 감싸 보내는 경우도 받아줍니다.
+// 스키마는 타입마다 처음 한 번만 만들고 다시 씁니다.
+func Decode[T any](text string) (T, error) {
+	var v T
+	s, err := cached(reflect.TypeFor[T]())
+	if err != nil {
+		return v, err
+	}
+
+	data := []byte(stripFence(text))
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return v, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := validate(s, raw, ""); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// decodeSchemas는 Decode가 검사에 쓰는 타입별 스키마(또는 만들다 난 오류)입니다.
+// 검사는 스키마를 바꾸지 않으므로 여러 고루틴이 같은 스키마를 함께 써도 안전합니다.
+var decodeSchemas sync.Map // reflect.Type → cachedSchema
+
+type cachedSchema struct {
+	schema *genai.Schema
+	err    error
+}
+
+// cached는 t의 스키마를 decodeSchemas에서 찾고, 없으면 만들어 넣습니다.
+func cached(t reflect.Type) (*genai.Schema, error) {
+	if c, ok := decodeSchemas.Load(t); ok {
+		return c.(cachedSchema).schema, c.(cachedSchema).err
+	}
+	s, err := forType(t)
+	c, _ := decodeSchemas.LoadOrStore(t, cachedSchema{s, err})
+	return c.(cachedSchema).schema, c.(cachedSchema).err
+}
+
+// stripFence는 ```json ... ``` 코드 블록을 벗겨냅니다.
+func stripFence(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	return strings.Trim(text, "`\n ")
+}
+
+// validate는 json.Unmarshal로 읽은 값 v가 스키마 s에 맞는지 검사합니다. path는 오류 메시지에 쓸 필드 위치입니다.
+func validate(s *genai.Schema, v any, path string) error {
+	if v == nil {
+		if s.Nullable != nil && *s.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", at(path))
+	}
+
+	switch s.Type {
+	case genai.TypeObject:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return typeError(path, "an object", v)
+		}
+		for _, name := range s.Required {
+			if _, ok := m[name]; !ok {
+				return fmt.Errorf("%s: missing required field %q", at(path), name)
+			}
+		}
+		for _, name := range s.PropertyOrdering {
+			if fv, ok := m[name]; ok {
+				if err := validate(s.Properties[name], fv, join(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+	case genai.TypeArray:
+		a, ok := v.([]any)
+		if !ok {
+			return typeError(path, "an array", v)
+		}
+		for i, item := range a {
+			if err := validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case genai.TypeString:
+		str, ok := v.(string)
+		if !ok {
+			return typeError(path, "a string", v)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: %q is not one of %s", at(path), str, strings.Join(s.Enum, ", "))
+		}
+	case genai.TypeNumber, genai.TypeInteger:
+		f, ok := v.(float64)
+		if !ok {
+			return typeError(path, "a number", v)
+		}
+		if s.Type == genai.TypeInteger && f != math.Trunc(f) {
+			return fmt.Errorf("%s: %v is not an integer", at(path), f)
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Errorf("%s: %v is less than %v", at(path), f, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fmt.Errorf("%s: %v is greater than %v", at(path), f, *s.Maximum)
+		}
+	case genai.TypeBoolean:
+		if _, ok := v.(bool); !ok {
+			return typeError(path, "a boolean", v)
+		}
+	}
+	return nil
+}
+
+func typeError(path, want string, got any) error {
+	var kind string
+	switch got.(type) {
+	case map[string]any:
+		kind = "an object"
+	case []any:
+		kind = "an array"
+	case string:
+		kind = "a string"
+	case float64:
+		kind = "a number"
+	case bool:
+		kind = "a boolean"
+	}
+	return fmt.Errorf("%s: want %s, got %s", at(path), want, kind)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// at은 오류 메시지에 쓸 위치입니다. 최상위 값은 "response"라고 부릅니다.
+func at(path string) string {
+	if path == "" {
+		return "response"
+	}
+	return path
+}
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	const valid = `{"id": "1", "title": "t", "score": 0.5, "parent": null, "tags": ["a"], "items": [{"name": "x", "qty": 2}], "Plain": true}`
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string // 비어 있으면 성공해야 합니다
+	}{
+		{"올바른 답변 (omitempty, omitzero 필드는 빠져도 됨)", valid, ""},
+		{"```json 코드 블록", "```json\n" + valid + "\n```", ""},
+		{"언어 없는 코드 블록", "```\n" + valid + "\n```", ""},
+		{"앞뒤 공백", "\n  " + valid + "  \n", ""},
+		{"JSON이 아님", "sure! here it is", "invalid JSON"},
+		{"배열이 최상위", `[]`, "response: want an object, got an array"},
+		{"필수 필드 누락", `{"id": "1", "score": 0.5, "parent": null, "tags": [], "items": [], "Plain": true}`, `response: missing required field "title"`},
+		{"포인터가 아닌 필드에 null", strings.Replace(valid, `"title": "t"`, `"title": null`, 1), "title: must not be null"},
+		{"포인터 필드에 값", strings.Replace(valid, `"parent": null`, `"parent": "p"`, 1), ""},
+		{"타입이 다름", strings.Replace(valid, `"score": 0.5`, `"score": "high"`, 1), "score: want a number, got a string"},
+		{"최솟값보다 작음", strings.Replace(valid, `"score": 0.5`, `"score": -0.1`, 1), "score: -0.1 is less than 0"},
+		{"최댓값보다 큼", strings.Replace(valid, `"score": 0.5`, `"score": 1.5`, 1), "score: 1.5 is greater than 1"},
+		{"enum에 없는 항목", strings.Replace(valid, `"tags": ["a"]`, `"tags": ["a", "z"]`, 1), `tags[1]: "z" is not one of a, b, c`},
+		{"중첩된 배열 안의 필드", strings.Replace(valid, `"qty": 2`, `"qty": 20`, 1), "items[0].qty: 20 is greater than 10"},
+		{"중첩된 배열 안의 정수", strings.Replace(valid, `"qty": 2`, `"qty": 2.5`, 1), "items[0].qty: 2.5 is not an integer"},
+		{"중첩된 배열 안의 필수 필드", strings.Replace(valid, `{"name": "x", "qty": 2}`, `{"name": "x", "qty": 2}, {"qty": 1}`, 1), `items[1]: missing required field "name"`},
+		{"배열의 배열", strings.Replace(valid, `"Plain": true`, `"Plain": true, "grid": [[{"name": "g", "qty": 0}]]`, 1), "grid[0][0].qty: 0 is less than 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode[sample](tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.ID != "1" || got.Title != "t" || len(got.Items) != 1 || got.Items[0].Qty != 2 {
+				t.Errorf("decoded = %+v", got)
+			}
+		})
+	}
+}
+
+func TestDecodeRecursiveType(t *testing.T) {
+	if _, err := Decode[node](`{"name": "root", "children": []}`); err == nil || !strings.Contains(err.Error(), "recursive type") {
+		t.Errorf("err = %v, want recursive type error", err)
+	}
+	// 오류도 캐시되므로 두 번째 호출도 같은 오류를 돌려줍니다.
+	if _, err := Decode[node](`{}`); err == nil {
+		t.Error("second Decode should fail too")
+	}
+}
+
+func TestDecodeCachesSchema(t *testing.T) {
+	if _, err := Decode[item](`{"name": "x", "qty": 1}`); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := decodeSchemas.Load(reflect.TypeFor[item]())
+	if _, err := Decode[item](`{"name": "y", "qty": 2}`); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := decodeSchemas.Load(reflect.TypeFor[item]())
+	if first == nil || first.(cachedSchema).schema != second.(cachedSchema).schema {
+		t.Error("Decode rebuilt the schema instead of reusing the cached one")
+	}
+}
@@ -0,0 +1,183 @@
+// Package schema는 Go 구조체로 에이전트의 OutputSchema(genai.Schema)를 만들고,
+// 에이전트의 최종 답변(JSON)을 그 스키마로 검사해 같은 구조체로 읽습니다.
+//
+// 스키마를 손으로 쓰면 답변을 읽는 Go 코드와 어긋나기 쉽습니다. 이 패키지를 쓰면 구조체 하나가 둘 다의 기준이 됩니다.
+//
+//	type notes struct {
+//		Summary     string   `json:"summary" description:"One-paragraph summary."`
+//		ActionItems []string `json:"action_items" description:"Things someone has to do."`
+//		Mood        string   `json:"mood,omitempty" enum:"positive,neutral,negative"`
+//	}
+//
+//	s, err := schema.For[notes]()          // llmagent.Config의 OutputSchema로
+//	n, err := schema.Decode[notes](answer) // 에이전트의 최종 답변을 읽을 때
+//
+// 필드는 encoding/json과 같은 규칙으로 고릅니다. json 태그의 이름을 쓰고, "-"와 내보내지 않은 필드는 건너뛰며,
+// 태그 없이 임베드한 구조체의 필드는 바깥 구조체의 필드로 펼칩니다.
+// omitempty나 omitzero가 없는 필드는 필수(Required)이고, 포인터 필드는 null을 허용(Nullable)합니다.
+// 그 밖의 태그는 다음과 같습니다.
+//
+//	description:"..."  필드 설명
+//	enum:"a,b,c"       고를 수 있는 값 (문자열, 또는 문자열 배열의 각 항목)
+//	minimum:"0"        숫자의 최솟값
+//	maximum:"1"        숫자의 최댓값
+//
+// 구조체와 슬라이스는 안쪽까지 따라가 중첩된 object/array 스키마를 만듭니다. 맵, 인터페이스, 자기 자신을 품는 타입은 지원하지 않습니다.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// For는 T(보통 구조체)의 스키마를 만듭니다. 태그가 잘못됐거나 지원하지 않는 타입이 있으면 오류를 반환합니다.
+// 호출할 때마다 새 스키마를 만들므로, 받은 쪽에서 고쳐 써도 됩니다.
+func For[T any]() (*genai.Schema, error) {
+	return forType(reflect.TypeFor[T]())
+}
+
+func forType(t reflect.Type) (*genai.Schema, error) {
+	s, err := fromType(t, make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	return s, nil
+}
+
+// fromType은 t의 스키마를 만듭니다. seen은 지금 따라가고 있는 구조체들로, 자기 자신을 품는 타입을 찾는 데 씁니다.
+func fromType(t reflect.Type, seen map[reflect.Type]bool) (*genai.Schema, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s, err := fromType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		s.Nullable = genai.Ptr(true)
+		return s, nil
+	case reflect.String:
+		return &genai.Schema{Type: genai.TypeString}, nil
+	case reflect.Bool:
+		return &genai.Schema{Type: genai.TypeBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &genai.Schema{Type: genai.TypeInteger}, nil
+	case reflect.Float32, reflect.Float64:
+		return &genai.Schema{Type: genai.TypeNumber}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := fromType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &genai.Schema{Type: genai.TypeArray, Items: items}, nil
+	case reflect.Struct:
+		if seen[t] {
+			return nil, fmt.Errorf("recursive type %s is not supported", t)
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s := &genai.Schema{Type: genai.TypeObject, Properties: make(map[string]*genai.Schema)}
+		if err := addFields(s, t, seen); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// addFields는 구조체 t의 필드를 s의 속성으로 더합니다. 태그 없이 임베드한 구조체는 펼쳐서 더합니다.
+func addFields(s *genai.Schema, t reflect.Type, seen map[reflect.Type]bool) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				if err := addFields(s, et, seen); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := fromType(f.Type, seen)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		if err := applyTags(prop, f.Tag); err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		if _, dup := s.Properties[name]; dup {
+			return fmt.Errorf("%s.%s: duplicate field name %q", t.Name(), f.Name, name)
+		}
+		s.Properties[name] = prop
+		s.PropertyOrdering = append(s.PropertyOrdering, name)
+		if !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// applyTags는 description, enum, minimum, maximum 태그를 s에 적용합니다.
+// enum은 문자열 배열이면 각 항목에 적용합니다.
+func applyTags(s *genai.Schema, tag reflect.StructTag) error {
+	s.Description = tag.Get("description")
+
+	if enum, ok := tag.Lookup("enum"); ok {
+		target := s
+		if target.Type == genai.TypeArray {
+			target = target.Items
+		}
+		if target.Type != genai.TypeString {
+			return fmt.Errorf("enum needs a string field, got %s", strings.ToLower(string(target.Type)))
+		}
+		for v := range strings.SplitSeq(enum, ",") {
+			target.Enum = append(target.Enum, strings.TrimSpace(v))
+		}
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  **float64
+	}{{"minimum", &s.Minimum}, {"maximum", &s.Maximum}} {
+		v, ok := tag.Lookup(bound.name)
+		if !ok {
+			continue
+		}
+		if s.Type != genai.TypeNumber && s.Type != genai.TypeInteger {
+			return fmt.Errorf("%s needs a number field, got %s", bound.name, strings.ToLower(string(s.Type)))
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", bound.name, v, err)
+		}
+		*bound.dst = genai.Ptr(f)
+	}
+	return nil
+}
+
+func hasOption(opts, name string) bool {
+	for o := range strings.SplitSeq(opts, ",") {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+type base struct {
+	ID      string `json:"id"`
+	private string
+}
+
+type item struct {
+	Name string `json:"name"`
+	Qty  int    `json:"qty" minimum:"1" maximum:"10"`
+}
+
+type sample struct {
+	base
+	Title   string   `json:"title" description:"Short title."`
+	Note    string   `json:"note,omitempty"`
+	Count   int      `json:"count,omitzero"`
+	Score   float64  `json:"score" minimum:"0" maximum:"1"`
+	Parent  *string  `json:"parent"`
+	Tags    []string `json:"tags" enum:"a,b,c"`
+	Items   []item   `json:"items"`
+	Grid    [][]item `json:"grid,omitempty"`
+	Skipped string   `json:"-"`
+	Plain   bool
+}
+
+type withPointerEmbed struct {
+	*base
+	Extra string `json:"extra"`
+}
+
+type Meta struct {
+	Source string `json:"source"`
+}
+
+type withTaggedEmbed struct {
+	Meta `json:"meta"`
+}
+
+type node struct {
+	Name     string `json:"name"`
+	Children []node `json:"children"`
+}
+
+type linked struct {
+	Next *linked `json:"next"`
+}
+
+type outer struct {
+	Inner inner `json:"inner"`
+}
+
+type inner struct {
+	Back *outer `json:"back"`
+}
+
+func mustFor[T any](t *testing.T) *genai.Schema {
+	t.Helper()
+	s, err := For[T]()
+	if err != nil {
+		t.Fatalf("For: %v", err)
+	}
+	return s
+}
+
+func TestForFields(t *testing.T) {
+	s := mustFor[sample](t)
+
+	// 임베드한 base의 id가 바깥 필드로 펼쳐지고, "-"와 내보내지 않은 필드는 빠집니다.
+	want := []string{"id", "title", "note", "count", "score", "parent", "tags", "items", "grid", "Plain"}
+	if !slices.Equal(s.PropertyOrdering, want) {
+		t.Errorf("ordering = %v, want %v", s.PropertyOrdering, want)
+	}
+	for _, name := range []string{"private", "Skipped", "base"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("property %q should not exist", name)
+		}
+	}
+	if got := s.Properties["title"].Description; got != "Short title." {
+		t.Errorf("description = %q", got)
+	}
+}
+
+func TestForRequired(t *testing.T) {
+	s := mustFor[sample](t)
+	want := []string{"id", "title", "score", "parent", "tags", "items", "Plain"}
+	if !slices.Equal(s.Required, want) {
+		t.Errorf("required = %v, want %v (omitempty, omitzero fields are optional)", s.Required, want)
+	}
+}
+
+func TestForNullable(t *testing.T) {
+	s := mustFor[sample](t)
+	if p := s.Properties["parent"]; p.Type != genai.TypeString || p.Nullable == nil || !*p.Nullable {
+		t.Errorf("pointer field: type %s, nullable %v", p.Type, p.Nullable)
+	}
+	if p := s.Properties["title"]; p.Nullable != nil {
+		t.Errorf("non-pointer field should not be nullable")
+	}
+}
+
+func TestForEmbedded(t *testing.T) {
+	s := mustFor[withPointerEmbed](t)
+	if want := []string{"id", "extra"}; !slices.Equal(s.PropertyOrdering, want) {
+		t.Errorf("pointer embed: ordering = %v, want %v", s.PropertyOrdering, want)
+	}
+
+	// json 태그로 이름을 준 임베드는 펼치지 않고 중첩된 객체로 둡니다.
+	s = mustFor[withTaggedEmbed](t)
+	m, ok := s.Properties["meta"]
+	if !ok || m.Type != genai.TypeObject || m.Properties["source"] == nil {
+		t.Errorf("tagged embed: %+v", s.Properties)
+	}
+}
+
+func TestForNestedArrays(t *testing.T) {
+	s := mustFor[sample](t)
+
+	items := s.Properties["items"]
+	if items.Type != genai.TypeArray || items.Items.Type != genai.TypeObject {
+		t.Fatalf("items: %s of %s", items.Type, items.Items.Type)
+	}
+	if !slices.Equal(items.Items.Required, []string{"name", "qty"}) {
+		t.Errorf("items required = %v", items.Items.Required)
+	}
+
+	grid := s.Properties["grid"]
+	if grid.Type != genai.TypeArray || grid.Items.Type != genai.TypeArray || grid.Items.Items.Type != genai.TypeObject {
+		t.Errorf("grid should be an array of arrays of objects")
+	}
+}
+
+func TestForEnumOnStringSlice(t *testing.T) {
+	tags := mustFor[sample](t).Properties["tags"]
+	if len(tags.Enum) != 0 {
+		t.Errorf("enum should be on the items, not the array: %v", tags.Enum)
+	}
+	if !slices.Equal(tags.Items.Enum, []string{"a", "b", "c"}) {
+		t.Errorf("items enum = %v", tags.Items.Enum)
+	}
+}
+
+func TestForBounds(t *testing.T) {
+	s := mustFor[sample](t)
+	score := s.Properties["score"]
+	if score.Minimum == nil || *score.Minimum != 0 || score.Maximum == nil || *score.Maximum != 1 {
+		t.Errorf("score bounds = %v, %v", score.Minimum, score.Maximum)
+	}
+	qty := s.Properties["items"].Items.Properties["qty"]
+	if qty.Type != genai.TypeInteger || *qty.Minimum != 1 || *qty.Maximum != 10 {
+		t.Errorf("qty = %s [%v, %v]", qty.Type, qty.Minimum, qty.Maximum)
+	}
+}
+
+func TestForRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() (*genai.Schema, error)
+		want  string
+	}{
+		{"자기 자신의 슬라이스", For[node], "recursive type"},
+		{"자기 자신의 포인터", For[linked], "recursive type"},
+		{"서로를 품는 타입", For[outer], "recursive type"},
+		{"맵", For[struct {
+			M map[string]string `json:"m"`
+		}], "unsupported type"},
+		{"숫자 필드의 enum", For[struct {
+			N int `json:"n" enum:"1,2"`
+		}], "enum needs a string field"},
+		{"문자열 필드의 minimum", For[struct {
+			S string `json:"s" minimum:"0"`
+		}], "minimum needs a number field"},
+		{"숫자가 아닌 maximum", For[struct {
+			F float64 `json:"f" maximum:"one"`
+		}], "invalid maximum"},
+		{"임베드한 필드와 겹치는 이름", For[struct {
+			base
+			Other string `json:"id"`
+		}], "duplicate field name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestForReturnsFreshSchema(t *testing.T) {
+	// 받은 쪽에서 스키마를 고쳐도 다음 For나 Decode에 영향이 없어야 합니다.
+	s := mustFor[item](t)
+	s.Required = nil
+	if got := mustFor[item](t); len(got.Required) != 2 {
+		t.Errorf("For returned a shared schema: required = %v", got.Required)
+	}
+}
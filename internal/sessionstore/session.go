@@ -0,0 +1,116 @@
+package sessionstore
+
+import (
+	"iter"
+	"maps"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+type id struct {
+	appName   string
+	userID    string
+	sessionID string
+}
+
+// storedSession은 Service가 보관하는 세션 원본입니다. app:/user: 상태는 Service가 따로 보관합니다.
+type storedSession struct {
+	id        id
+	state     map[string]any
+	events    []*session.Event
+	updatedAt time.Time
+	// version은 이벤트가 추가되거나 압축될 때마다 1씩 늘어납니다.
+	version int
+}
+
+// sessionCopy는 Get/Create/List가 돌려주는 세션 사본입니다.
+// version은 사본을 만들 때 원본의 version이며, AppendEvent/CompactEvents가 낙관적 동시성 검사에 씁니다.
+type sessionCopy struct {
+	id id
+
+	mu        sync.RWMutex
+	state     map[string]any
+	events    []*session.Event
+	updatedAt time.Time
+	version   int
+}
+
+var _ session.Session = (*sessionCopy)(nil)
+
+func (s *sessionCopy) ID() string      { return s.id.sessionID }
+func (s *sessionCopy) AppName() string { return s.id.appName }
+func (s *sessionCopy) UserID() string  { return s.id.userID }
+
+func (s *sessionCopy) State() session.State {
+	return &state{mu: &s.mu, state: s.state}
+}
+
+func (s *sessionCopy) Events() session.Events {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return events(s.events)
+}
+
+func (s *sessionCopy) LastUpdateTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+type events []*session.Event
+
+func (e events) All() iter.Seq[*session.Event] {
+	return func(yield func(*session.Event) bool) {
+		for _, event := range e {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+func (e events) Len() int { return len(e) }
+
+func (e events) At(i int) *session.Event {
+	if i >= 0 && i < len(e) {
+		return e[i]
+	}
+	return nil
+}
+
+type state struct {
+	mu    *sync.RWMutex
+	state map[string]any
+}
+
+func (s *state) Get(key string) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.state[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return val, nil
+}
+
+func (s *state) Set(key string, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state[key] = value
+	return nil
+}
+
+func (s *state) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		s.mu.RLock()
+		snapshot := maps.Clone(s.state)
+		s.mu.RUnlock()
+		for k, v := range snapshot {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
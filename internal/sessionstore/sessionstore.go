@@ -0,0 +1,419 @@
+// Package sessionstore는 프로세스를 다시 시작해도 대화가 이어지는 session.Service 구현입니다.
+//
+// 세션 생성/이벤트 추가/삭제를 JSONL 파일에 한 줄씩 덧붙여(append-only) 기록하고,
+// 시작할 때 파일을 처음부터 다시 읽어 세션, 이벤트, 상태(app:/user:/세션 상태)를 복원합니다.
+// 외부 데이터베이스 없이 표준 라이브러리만 사용합니다. NewInMemory는 파일 없이 메모리에만 보관합니다.
+//
+// CompactEvents는 오래된 이벤트들을 요약 이벤트 하나로 바꿔, 긴 대화가 모델의 컨텍스트를 넘지 않게 합니다.
+//
+// AppendEvent는 낙관적 동시성(optimistic concurrency)을 사용합니다.
+// Get으로 받은 세션 사본 이후에 다른 호출자가 같은 세션에 이벤트를 추가했다면 ErrStaleSession을 반환합니다.
+//
+// 하나의 파일은 한 프로세스만 열어야 합니다.
+// 상태 값은 JSON으로 저장되므로 다시 읽으면 숫자는 float64, 구조체는 map[string]any가 됩니다.
+package sessionstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"google.golang.org/adk/session"
+
+	"awesomeProject2/internal/jsonl"
+)
+
+// ErrStaleSession은 세션 사본을 받은 뒤 다른 호출자가 같은 세션을 먼저 변경했을 때 반환됩니다.
+// 세션을 다시 Get 한 뒤 재시도해야 합니다.
+var ErrStaleSession = errors.New("session was modified by another writer; reload it and retry")
+
+// 파일에 기록되는 한 줄
+type record struct {
+	Op        string         `json:"op"` // create, append, compact, delete
+	AppName   string         `json:"appName"`
+	UserID    string         `json:"userId"`
+	SessionID string         `json:"sessionId"`
+	State     map[string]any `json:"state,omitempty"`
+	Event     *session.Event `json:"event,omitempty"`
+	// KeepFrom은 compact에서 그대로 남길 첫 이벤트의 ID입니다. 그 앞의 이벤트는 Event(요약)로 바뀝니다.
+	KeepFrom string    `json:"keepFrom,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Service는 JSONL 파일에 기록되는 session.Service입니다. 여러 고루틴에서 동시에 써도 안전합니다.
+type Service struct {
+	mu        sync.RWMutex
+	file      *jsonl.Log
+	readOnly  bool
+	closed    bool
+	sessions  map[id]*storedSession
+	appState  map[string]map[string]any
+	userState map[string]map[string]map[string]any
+}
+
+var _ session.Service = (*Service)(nil)
+
+// NewInMemory는 파일에 기록하지 않는 Service를 만듭니다. 프로세스가 끝나면 세션도 사라집니다.
+func NewInMemory() *Service {
+	return &Service{
+		sessions:  make(map[id]*storedSession),
+		appState:  make(map[string]map[string]any),
+		userState: make(map[string]map[string]map[string]any),
+	}
+}
+
+// Open은 path의 세션 파일을 읽어 Service를 만듭니다. 파일이 없으면 새로 만듭니다.
+// 기록 도중 프로세스가 죽어 마지막 줄이 잘렸다면 그 줄은 버립니다.
+func Open(path string) (*Service, error) {
+	s := NewInMemory()
+	f, err := jsonl.Open(path, s.apply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session file: %w", err)
+	}
+	s.file = f
+	return s, nil
+}
+
+// OpenReadOnly는 path의 세션 파일을 읽기만 해서 Service를 만듭니다. 파일이 없으면 오류를 반환합니다.
+// 파일을 만들거나 고치지(잘린 줄 정리) 않으므로, 대화 중인 다른 프로세스가 쓰고 있는 파일을 내보내거나 다시 돌려볼 때 씁니다.
+// 세션을 바꾸는 호출(Create, AppendEvent, CompactEvents, Delete)은 오류를 반환합니다.
+func OpenReadOnly(path string) (*Service, error) {
+	s := NewInMemory()
+	if err := jsonl.Replay(path, s.apply); err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	s.readOnly = true
+	return s, nil
+}
+
+// apply는 기록 한 줄을 메모리 상태에 반영합니다. 파일을 읽을 때와 새로 기록할 때 같은 함수를 씁니다.
+func (s *Service) apply(rec record) error {
+	key := id{appName: rec.AppName, userID: rec.UserID, sessionID: rec.SessionID}
+	switch rec.Op {
+	case "create":
+		appDelta, userDelta, sessionState := splitState(rec.State)
+		s.updateAppState(rec.AppName, appDelta)
+		s.updateUserState(rec.AppName, rec.UserID, userDelta)
+		s.sessions[key] = &storedSession{id: key, state: sessionState, updatedAt: rec.Time}
+	case "append":
+		stored, ok := s.sessions[key]
+		if !ok || rec.Event == nil {
+			return fmt.Errorf("event for unknown session %q", rec.SessionID)
+		}
+		stored.events = append(stored.events, rec.Event)
+		stored.updatedAt = rec.Event.Timestamp
+		stored.version++
+		appDelta, userDelta, sessionDelta := splitState(rec.Event.Actions.StateDelta)
+		s.updateAppState(rec.AppName, appDelta)
+		s.updateUserState(rec.AppName, rec.UserID, userDelta)
+		maps.Copy(stored.state, sessionDelta)
+	case "compact":
+		stored, ok := s.sessions[key]
+		if !ok || rec.Event == nil {
+			return fmt.Errorf("compaction for unknown session %q", rec.SessionID)
+		}
+		keep := slices.IndexFunc(stored.events, func(e *session.Event) bool { return e.ID == rec.KeepFrom })
+		if keep < 0 {
+			return fmt.Errorf("session %q has no event %q to keep", rec.SessionID, rec.KeepFrom)
+		}
+		stored.events = append([]*session.Event{rec.Event}, stored.events[keep:]...)
+		stored.version++
+	case "delete":
+		delete(s.sessions, key)
+	default:
+		return fmt.Errorf("unknown op %q", rec.Op)
+	}
+	return nil
+}
+
+// writeLocked는 기록 한 줄을 파일에 덧붙이고 fsync 한 뒤 메모리 상태에 반영합니다.
+func (s *Service) writeLocked(rec record) error {
+	if s.closed {
+		return errors.New("session store is closed")
+	}
+	if s.readOnly {
+		return errors.New("session store is opened read-only")
+	}
+	if s.file != nil {
+		if err := s.file.Append(rec); err != nil {
+			return fmt.Errorf("failed to write session record: %w", err)
+		}
+	}
+	return s.apply(rec)
+}
+
+func (s *Service) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+	key := id{appName: req.AppName, userID: req.UserID, sessionID: sessionID}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[key]; ok {
+		return nil, fmt.Errorf("session %s already exists", sessionID)
+	}
+	rec := record{Op: "create", AppName: req.AppName, UserID: req.UserID, SessionID: sessionID, State: req.State, Time: time.Now()}
+	if err := s.writeLocked(rec); err != nil {
+		return nil, err
+	}
+	return &session.CreateResponse{Session: s.copyLocked(s.sessions[key], 0, time.Time{})}, nil
+}
+
+func (s *Service) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.sessions[id{appName: req.AppName, userID: req.UserID, sessionID: req.SessionID}]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", req.SessionID)
+	}
+	return &session.GetResponse{Session: s.copyLocked(stored, req.NumRecentEvents, req.After)}, nil
+}
+
+// List는 app(과 user)의 세션 목록을 최근에 변경된 순서로 반환합니다. 이벤트는 담지 않습니다.
+func (s *Service) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stored []*storedSession
+	for key, sess := range s.sessions {
+		if key.appName == req.AppName && (req.UserID == "" || key.userID == req.UserID) {
+			stored = append(stored, sess)
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].updatedAt.After(stored[j].updatedAt) })
+
+	sessions := make([]session.Session, 0, len(stored))
+	for _, sess := range stored {
+		c := s.copyLocked(sess, 0, time.Time{})
+		c.events = nil
+		sessions = append(sessions, c)
+	}
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+func (s *Service) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id{appName: req.AppName, userID: req.UserID, sessionID: req.SessionID}]; !ok {
+		return nil
+	}
+	return s.writeLocked(record{Op: "delete", AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID, Time: time.Now()})
+}
+
+// AppendEvent는 이벤트를 세션에 추가하고 파일에 기록합니다.
+// curSession을 받은 뒤 같은 세션에 다른 이벤트가 먼저 추가되었다면 ErrStaleSession을 반환합니다.
+func (s *Service) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if curSession == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	if event.Partial {
+		return nil
+	}
+	sess, ok := curSession.(*sessionCopy)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+
+	// temp: 상태는 이번 실행에서만 쓰이므로 저장하지 않습니다.
+	event.Actions.StateDelta = withoutTemp(event.Actions.StateDelta)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sessions[sess.id]
+	if !ok {
+		return fmt.Errorf("session not found, cannot apply event")
+	}
+	if err := checkVersion(sess, stored); err != nil {
+		return err
+	}
+
+	rec := record{Op: "append", AppName: sess.id.appName, UserID: sess.id.userID, SessionID: sess.id.sessionID, Event: event, Time: time.Now()}
+	if err := s.writeLocked(rec); err != nil {
+		return err
+	}
+
+	// 호출자가 들고 있는 사본도 같은 상태로 맞춰줍니다.
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.events = append(sess.events, event)
+	sess.updatedAt = event.Timestamp
+	sess.version++
+	maps.Copy(sess.state, event.Actions.StateDelta)
+	return nil
+}
+
+// CompactEvents는 keepFromEventID 이벤트 앞의 모든 이벤트를 summary 하나로 바꿉니다.
+// summary는 남는 이벤트들보다 앞에 놓이므로, Timestamp는 요약한 마지막 이벤트의 시각 정도로 정해야 합니다.
+// curSession을 받은 뒤 세션이 바뀌었다면 ErrStaleSession을 반환합니다. 성공하면 curSession도 압축된 상태로 바뀝니다.
+func (s *Service) CompactEvents(ctx context.Context, curSession session.Session, summary *session.Event, keepFromEventID string) error {
+	sess, ok := curSession.(*sessionCopy)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+	if summary == nil {
+		return fmt.Errorf("summary is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sessions[sess.id]
+	if !ok {
+		return fmt.Errorf("session not found, cannot compact")
+	}
+	if err := checkVersion(sess, stored); err != nil {
+		return err
+	}
+
+	rec := record{Op: "compact", AppName: sess.id.appName, UserID: sess.id.userID, SessionID: sess.id.sessionID, Event: summary, KeepFrom: keepFromEventID, Time: time.Now()}
+	if err := s.writeLocked(rec); err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.events = slices.Clone(stored.events)
+	sess.version = stored.version
+	return nil
+}
+
+// checkVersion은 사본을 만든 뒤 원본이 바뀌지 않았는지 확인합니다.
+func checkVersion(sess *sessionCopy, stored *storedSession) error {
+	sess.mu.RLock()
+	version := sess.version
+	sess.mu.RUnlock()
+	if version != stored.version {
+		return fmt.Errorf("session %s: %w", sess.id.sessionID, ErrStaleSession)
+	}
+	return nil
+}
+
+// Close는 세션 파일을 닫습니다.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// copyLocked는 세션 사본을 만듭니다. 상태에는 app:/user: 상태가 접두사와 함께 합쳐집니다.
+func (s *Service) copyLocked(stored *storedSession, numRecent int, after time.Time) *sessionCopy {
+	evs := stored.events
+	if numRecent > 0 {
+		evs = evs[max(len(evs)-numRecent, 0):]
+	}
+	if !after.IsZero() {
+		first := sort.Search(len(evs), func(i int) bool { return !evs[i].Timestamp.Before(after) })
+		evs = evs[first:]
+	}
+
+	merged := maps.Clone(stored.state)
+	if merged == nil {
+		merged = make(map[string]any)
+	}
+	for k, v := range s.appState[stored.id.appName] {
+		merged[session.KeyPrefixApp+k] = v
+	}
+	for k, v := range s.userState[stored.id.appName][stored.id.userID] {
+		merged[session.KeyPrefixUser+k] = v
+	}
+
+	return &sessionCopy{
+		id:        stored.id,
+		state:     merged,
+		events:    slices.Clone(evs),
+		updatedAt: stored.updatedAt,
+		version:   stored.version,
+	}
+}
+
+func (s *Service) updateAppState(appName string, delta map[string]any) {
+	if len(delta) == 0 {
+		return
+	}
+	if s.appState[appName] == nil {
+		s.appState[appName] = make(map[string]any)
+	}
+	maps.Copy(s.appState[appName], delta)
+}
+
+func (s *Service) updateUserState(appName, userID string, delta map[string]any) {
+	if len(delta) == 0 {
+		return
+	}
+	if s.userState[appName] == nil {
+		s.userState[appName] = make(map[string]map[string]any)
+	}
+	if s.userState[appName][userID] == nil {
+		s.userState[appName][userID] = make(map[string]any)
+	}
+	maps.Copy(s.userState[appName][userID], delta)
+}
+
+// splitState는 상태 변경분을 app:, user:, 세션 상태로 나눕니다. temp: 상태는 버립니다.
+func splitState(delta map[string]any) (app, user, sess map[string]any) {
+	app, user, sess = make(map[string]any), make(map[string]any), make(map[string]any)
+	for key, value := range delta {
+		if k, ok := strings.CutPrefix(key, session.KeyPrefixApp); ok {
+			app[k] = value
+		} else if k, ok := strings.CutPrefix(key, session.KeyPrefixUser); ok {
+			user[k] = value
+		} else if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			sess[key] = value
+		}
+	}
+	return app, user, sess
+}
+
+func withoutTemp(delta map[string]any) map[string]any {
+	if len(delta) == 0 {
+		return delta
+	}
+	filtered := make(map[string]any, len(delta))
+	for key, value := range delta {
+		if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,175 @@
+package sessionstore
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func TestOpenReadOnlyLeavesFileAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// 다른 프로세스가 한 줄을 쓰는 도중인 것처럼 잘린 줄을 덧붙입니다.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"create","appName":"app"`)
+	f.Close()
+	before, _ := os.ReadFile(path)
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+	if _, err := ro.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "alice", SessionID: "s1"}); err != nil {
+		t.Errorf("Get = %v", err)
+	}
+	if _, err := ro.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice"}); err == nil {
+		t.Error("Create on a read-only store succeeded")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Errorf("read-only open changed the file:\n%s", after)
+	}
+
+	if _, err := OpenReadOnly(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("OpenReadOnly on a missing file succeeded")
+	}
+}
+
+// appendText는 text를 담은 이벤트를 curSession에 더합니다.
+func appendText(t *testing.T, s *Service, cur session.Session, author, text string, delta map[string]any) *session.Event {
+	t.Helper()
+	ev := session.NewEvent("inv-test")
+	ev.Author = author
+	ev.LLMResponse.Content = genai.NewContentFromText(text, genai.RoleUser)
+	ev.Actions.StateDelta = delta
+	if err := s.AppendEvent(t.Context(), cur, ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func get(t *testing.T, s *Service, userID, sessionID string) session.Session {
+	t.Helper()
+	res, err := s.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: userID, SessionID: sessionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Session
+}
+
+func TestAppendEventRejectsStaleSession(t *testing.T) {
+	s := NewInMemory()
+	if _, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 두 호출자가 같은 세션을 받아 두고, 먼저 쓴 쪽만 성공합니다.
+	first, second := get(t, s, "alice", "s1"), get(t, s, "alice", "s1")
+	appendText(t, s, first, "user", "안녕", nil)
+
+	ev := session.NewEvent("inv-test")
+	ev.Author = "user"
+	if err := s.AppendEvent(t.Context(), second, ev); !errors.Is(err, ErrStaleSession) {
+		t.Errorf("AppendEvent on a stale copy = %v, want ErrStaleSession", err)
+	}
+	if err := s.CompactEvents(t.Context(), second, session.NewEvent("inv-test"), first.Events().At(0).ID); !errors.Is(err, ErrStaleSession) {
+		t.Errorf("CompactEvents on a stale copy = %v, want ErrStaleSession", err)
+	}
+
+	// 먼저 쓴 쪽의 사본은 최신으로 맞춰져 있어 계속 쓸 수 있고, 다시 받은 사본도 쓸 수 있습니다.
+	appendText(t, s, first, "model", "안녕하세요", nil)
+	appendText(t, s, get(t, s, "alice", "s1"), "user", "고마워요", nil)
+	if n := get(t, s, "alice", "s1").Events().Len(); n != 3 {
+		t.Errorf("session has %d events, want 3 (the stale append must not be stored)", n)
+	}
+}
+
+func TestReopenRestoresEventsAndState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1", State: map[string]any{
+		"app:theme": "dark",
+		"topic":     "여행",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur := get(t, s, "alice", "s1")
+	var ids []string
+	ids = append(ids, appendText(t, s, cur, "user", "부산 가고 싶어요", map[string]any{"user:name": "Alice", "temp:draft": "x"}).ID)
+	ids = append(ids, appendText(t, s, cur, "model", "좋아요", map[string]any{"count": 1}).ID)
+	ids = append(ids, appendText(t, s, cur, "user", "2박 3일이요", map[string]any{"topic": "부산 여행"}).ID)
+
+	// 앞의 두 이벤트를 요약 하나로 바꿉니다.
+	summary := session.NewEvent("inv-summary")
+	summary.ID = "summary-1"
+	summary.Author = "summarizer"
+	if err := s.CompactEvents(t.Context(), cur, summary, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s2"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	got := get(t, s, "alice", "s1")
+	var gotIDs []string
+	for e := range got.Events().All() {
+		gotIDs = append(gotIDs, e.ID)
+	}
+	if want := []string{"summary-1", ids[2]}; !slices.Equal(gotIDs, want) {
+		t.Errorf("events = %v, want %v", gotIDs, want)
+	}
+	if text := got.Events().At(1).Content.Parts[0].Text; text != "2박 3일이요" {
+		t.Errorf("event text = %q", text)
+	}
+
+	wantState := map[string]any{
+		"app:theme": "dark",
+		"user:name": "Alice",
+		"topic":     "부산 여행",
+		"count":     float64(1), // JSON으로 저장되므로 숫자는 float64로 돌아옵니다.
+	}
+	gotState := maps.Collect(got.State().All())
+	if !reflect.DeepEqual(gotState, wantState) {
+		t.Errorf("state = %v, want %v (temp: keys must not be stored)", gotState, wantState)
+	}
+
+	// app:, user: 상태는 같은 사용자의 다른 세션에서도 보입니다.
+	other := maps.Collect(get(t, s, "alice", "s2").State().All())
+	if other["app:theme"] != "dark" || other["user:name"] != "Alice" || other["topic"] != nil {
+		t.Errorf("state of another session = %v", other)
+	}
+
+	// 다시 연 뒤에도 이어서 기록할 수 있습니다.
+	appendText(t, s, got, "model", "일정을 짜 볼게요", nil)
+	if n := get(t, s, "alice", "s1").Events().Len(); n != 3 {
+		t.Errorf("after append: %d events, want 3", n)
+	}
+}
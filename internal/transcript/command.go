@@ -0,0 +1,79 @@
+package transcript
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"awesomeProject2/internal/sessionstore"
+)
+
+// --- export 하위 명령 ---
+
+// ExportCommand는 세션 파일에 저장된 대화를 대화록으로 내보내는 export 하위 명령입니다.
+// 예제마다 다른 것은 앱 이름과 세션 파일의 기본값뿐이므로, 플래그를 읽고 내보내는 일은 모두 여기서 합니다.
+//
+//	go run ./cmd/06-session-memory export --session_id session-20250101-120000 --format md,html
+type ExportCommand struct {
+	// AppName은 --app의 기본값입니다.
+	AppName string
+	// SessionFile은 --session_file의 기본값입니다. 비어 있으면 --session_file을 꼭 줘야 합니다.
+	SessionFile string
+}
+
+// Run은 args의 플래그대로 세션을 내보내고, 파일에 쓴 경로를 표준 오류에 알립니다.
+func (c ExportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	sessionFile := fs.String("session_file", c.SessionFile, "Session file to export from")
+	appName := fs.String("app", c.AppName, "App the session belongs to")
+	userID := fs.String("user", "", "Only look at this user's sessions (default: all users)")
+	sessionID := fs.String("session_id", "", "Session to export (default: the most recently updated one)")
+	formats := fs.String("format", "md", "Comma-separated transcript formats: md, jsonl, html")
+	out := fs.String("out", "", "Output file, or - for stdout (default: data/transcript-<session>.<format>)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *sessionFile == "" {
+		return errors.New("--session_file is required")
+	}
+	list, err := ParseFormats(*formats)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	store, err := sessionstore.OpenReadOnly(*sessionFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := ExportAll(ctx, store, Options{
+		AppName:   *appName,
+		UserID:    *userID,
+		SessionID: *sessionID,
+		Path:      *out,
+	}, list)
+	for _, path := range paths {
+		if path != "-" {
+			fmt.Fprintf(os.Stderr, ">>> 대화록을 %s에 저장했습니다.\n", path)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export session: %w", err)
+	}
+	return nil
+}
+
+// LoadFile은 세션 파일을 읽기 전용으로 열어 opts의 세션을 Transcript로 읽어 옵니다.
+func LoadFile(ctx context.Context, path string, opts Options) (*Transcript, error) {
+	store, err := sessionstore.OpenReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Load(ctx, store, opts)
+	if err != nil {
+		return nil, err
+	}
+	return FromSession(s), nil
+}
@@ -0,0 +1,130 @@
+package transcript
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/adk/session"
+)
+
+// Options는 Export가 내보낼 세션과 파일을 정합니다.
+type Options struct {
+	AppName string
+	// UserID가 비어 있으면 앱의 모든 사용자에서 세션을 찾습니다.
+	UserID string
+	// SessionID가 비어 있으면 가장 최근에 대화한 세션을 내보냅니다.
+	SessionID string
+	Format    Format
+	// Path가 비어 있으면 data/transcript-<세션 ID>.<형식>에 쓰고, "-"이면 표준 출력에 씁니다.
+	Path string
+}
+
+// ParseFormats는 "md,html"처럼 쉼표로 구분한 형식 목록을 읽습니다.
+func ParseFormats(list string) ([]Format, error) {
+	var formats []Format
+	for name := range strings.SplitSeq(list, ",") {
+		f, err := ParseFormat(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+// ExportAll은 같은 세션을 formats의 형식마다 하나씩 내보내고, 쓴 경로들을 반환합니다. opts.Format은 쓰지 않습니다.
+// opts.Path를 정했다면 한 파일에 여러 형식을 쓸 수 없으므로 형식도 하나여야 합니다.
+func ExportAll(ctx context.Context, sessions session.Service, opts Options, formats []Format) ([]string, error) {
+	if opts.Path != "" && len(formats) > 1 {
+		return nil, errors.New("an output path can only be used with a single format")
+	}
+	s, err := Load(ctx, sessions, opts)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range formats {
+		path, err := write(s, opts.Path, f)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Export는 세션 서비스에서 세션을 읽어 대화록 파일로 쓰고, 쓴 경로를 반환합니다.
+func Export(ctx context.Context, sessions session.Service, opts Options) (string, error) {
+	s, err := Load(ctx, sessions, opts)
+	if err != nil {
+		return "", err
+	}
+	return write(s, opts.Path, opts.Format)
+}
+
+// Load는 opts의 AppName, UserID, SessionID로 내보낼 세션을 찾아 이벤트까지 읽어 옵니다.
+func Load(ctx context.Context, sessions session.Service, opts Options) (session.Session, error) {
+	userID, sessionID, err := findSession(ctx, sessions, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sessions.Get(ctx, &session.GetRequest{AppName: opts.AppName, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session %s: %w", sessionID, err)
+	}
+	return resp.Session, nil
+}
+
+// write는 세션 s를 format 형식으로 path에 씁니다. path의 규칙은 Options.Path와 같습니다.
+func write(s session.Session, path string, format Format) (string, error) {
+	if path == "-" {
+		return path, Write(os.Stdout, s, format)
+	}
+	if path == "" {
+		path = filepath.Join("data", fmt.Sprintf("transcript-%s.%s", s.ID(), format))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create transcript dir: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcript file: %w", err)
+	}
+	if err := Write(f, s, format); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// findSession은 내보낼 세션의 사용자와 ID를 찾습니다.
+func findSession(ctx context.Context, sessions session.Service, opts Options) (string, string, error) {
+	if opts.UserID != "" && opts.SessionID != "" {
+		return opts.UserID, opts.SessionID, nil
+	}
+	resp, err := sessions.List(ctx, &session.ListRequest{AppName: opts.AppName, UserID: opts.UserID})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var found session.Session
+	for _, s := range resp.Sessions {
+		if opts.SessionID != "" && s.ID() != opts.SessionID {
+			continue
+		}
+		if found == nil || s.LastUpdateTime().After(found.LastUpdateTime()) {
+			found = s
+		}
+	}
+	switch {
+	case found != nil:
+		return found.UserID(), found.ID(), nil
+	case opts.SessionID != "":
+		return "", "", fmt.Errorf("session %s not found in app %s", opts.SessionID, opts.AppName)
+	default:
+		return "", "", fmt.Errorf("no sessions in app %s", opts.AppName)
+	}
+}
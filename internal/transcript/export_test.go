@@ -0,0 +1,115 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"awesomeProject2/internal/sessionstore"
+)
+
+func TestParseFormats(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []Format
+		wantErr bool
+	}{
+		{"md", []Format{Markdown}, false},
+		{"md, html,jsonl", []Format{Markdown, HTML, JSONL}, false},
+		{"markdown,json", []Format{Markdown, JSONL}, false},
+		{"md,pdf", nil, true},
+		{"", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormats(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("ParseFormats(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestExportAll(t *testing.T) {
+	ctx := t.Context()
+	sessions := session.InMemoryService()
+	if _, err := sessions.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	// 경로를 정하면 형식은 하나만 쓸 수 있습니다.
+	if _, err := ExportAll(ctx, sessions, Options{AppName: "app", Path: filepath.Join(dir, "out.md")}, []Format{Markdown, HTML}); err == nil {
+		t.Error("ExportAll with a path and two formats succeeded")
+	}
+
+	path := filepath.Join(dir, "out.html")
+	paths, err := ExportAll(ctx, sessions, Options{AppName: "app", Path: path}, []Format{HTML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(paths, []string{path}) {
+		t.Errorf("paths = %v, want [%s]", paths, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sessions.jsonl")
+	store, err := sessionstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := store.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := session.NewEvent("inv-1")
+	ev.Author = "user"
+	ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("안녕", genai.RoleUser)}
+	if err := store.AppendEvent(ctx, created.Session, ev); err != nil {
+		t.Fatal(err)
+	}
+	// 대화 중인 프로세스가 파일을 열어 둔 채로도 내보낼 수 있어야 합니다.
+	defer store.Close()
+
+	out := filepath.Join(dir, "out.jsonl")
+	cmd := ExportCommand{AppName: "app", SessionFile: path}
+	if err := cmd.Run(ctx, []string{"--format", "jsonl", "--out", out}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tr, err := ReadJSONL(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.SessionID != "s1" || len(tr.Entries) != 1 || tr.Entries[0].Text != "안녕" {
+		t.Errorf("exported transcript = %+v", tr)
+	}
+
+	for name, tt := range map[string]struct {
+		cmd  ExportCommand
+		args []string
+	}{
+		"no session file": {ExportCommand{AppName: "app"}, nil},
+		"bad format":      {cmd, []string{"--format", "pdf"}},
+		"missing file":    {ExportCommand{AppName: "app", SessionFile: filepath.Join(dir, "missing.jsonl")}, nil},
+		"other app":       {cmd, []string{"--app", "other", "--out", filepath.Join(dir, "x.md")}},
+		"unknown flag":    {cmd, []string{"--bogus"}},
+	} {
+		if err := tt.cmd.Run(ctx, tt.args); err == nil {
+			t.Errorf("%s: Run succeeded", name)
+		}
+	}
+}
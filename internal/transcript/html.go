@@ -0,0 +1,98 @@
+package transcript
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// WriteHTML은 CSS까지 파일 안에 담은 HTML 페이지 하나를 씁니다. 외부 파일 없이 브라우저로 바로 열 수 있습니다.
+// 모든 텍스트는 html/template이 이스케이프하므로, 대화 내용에 HTML이 있어도 그대로 글자로 보입니다.
+func (t *Transcript) WriteHTML(w io.Writer) error {
+	if err := htmlTemplate.Execute(w, t); err != nil {
+		return fmt.Errorf("failed to render transcript: %w", err)
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format(time.DateTime) },
+	"pretty":   prettyJSON,
+	"compact":  CompactJSON,
+}).Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>대화록: {{.SessionID}}</title>
+<style>
+  body { font-family: -apple-system, "Apple SD Gothic Neo", "Noto Sans KR", sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #222; background: #f6f7f9; }
+  header { margin-bottom: 1.5rem; }
+  header dl { display: grid; grid-template-columns: max-content 1fr; gap: .2rem 1rem; color: #555; font-size: .9rem; }
+  header dd { margin: 0; }
+  .event { background: #fff; border-radius: 10px; padding: .8rem 1rem; margin: .8rem 0; box-shadow: 0 1px 2px rgba(0,0,0,.08); }
+  .event.user { background: #e8f1ff; margin-left: 3rem; }
+  .meta { font-size: .8rem; color: #777; margin-bottom: .4rem; }
+  .author { font-weight: 600; color: #333; }
+  .text { white-space: pre-wrap; line-height: 1.5; }
+  .thought { white-space: pre-wrap; color: #888; font-style: italic; }
+  details { margin: .4rem 0; }
+  summary { cursor: pointer; font-family: monospace; }
+  summary.call { color: #0b7285; }
+  summary.result { color: #5c6370; }
+  pre { background: #f1f3f5; padding: .6rem; border-radius: 6px; overflow-x: auto; font-size: .85rem; }
+  .action { font-size: .85rem; color: #5f3dc4; margin-top: .3rem; }
+  .error { color: #c92a2a; }
+  table { border-collapse: collapse; width: 100%; background: #fff; }
+  td, th { border: 1px solid #dee2e6; padding: .3rem .5rem; text-align: left; font-family: monospace; font-size: .85rem; }
+</style>
+</head>
+<body>
+<header>
+  <h1>대화록</h1>
+  <dl>
+    <dt>세션</dt><dd>{{.SessionID}}</dd>
+    <dt>앱</dt><dd>{{.AppName}}</dd>
+    <dt>사용자</dt><dd>{{.UserID}}</dd>
+    <dt>마지막 대화</dt><dd>{{datetime .UpdatedAt}}</dd>
+    <dt>이벤트</dt><dd>{{len .Entries}}개</dd>
+    <dt>내보낸 시각</dt><dd>{{datetime .ExportedAt}}</dd>
+  </dl>
+</header>
+<main>
+{{- range .Entries}}
+<section class="event{{if .IsUser}} user{{end}}" id="{{.EventID}}">
+  <div class="meta">{{if .IsUser}}👤{{else}}🤖{{end}} <span class="author">{{.Author}}</span> · {{datetime .Time}}{{if .Branch}} · {{.Branch}}{{end}}</div>
+  {{- if .Thought}}<div class="thought">💭 {{.Thought}}</div>{{end}}
+  {{- if .Text}}<div class="text">{{.Text}}</div>{{end}}
+  {{- range .ToolCalls}}
+  <details><summary class="call">⚙ {{.Name}}</summary><pre>{{pretty .Args}}</pre></details>
+  {{- end}}
+  {{- range .ToolResults}}
+  <details><summary class="result">↳ {{.Name}}</summary><pre>{{pretty .Response}}</pre></details>
+  {{- end}}
+  {{- range $key, $value := .StateDelta}}
+  <div class="action">📝 상태 변경: <code>{{$key}}</code> = <code>{{compact $value}}</code></div>
+  {{- end}}
+  {{- range $name, $version := .ArtifactDelta}}
+  <div class="action">📎 아티팩트: <code>{{$name}}</code> (버전 {{$version}})</div>
+  {{- end}}
+  {{- if .TransferTo}}<div class="action">➡️ <code>{{.TransferTo}}</code>에게 넘김</div>{{end}}
+  {{- if .Escalate}}<div class="action">⬆️ 상위 에이전트로 에스컬레이션</div>{{end}}
+  {{- if .Error}}<div class="action error">⚠️ 오류: {{.Error}}</div>{{end}}
+</section>
+{{- end}}
+</main>
+{{- if .State}}
+<h2>최종 상태</h2>
+<table>
+  <tr><th>키</th><th>값</th></tr>
+  {{- range $key, $value := .State}}
+  <tr><td>{{$key}}</td><td>{{compact $value}}</td></tr>
+  {{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
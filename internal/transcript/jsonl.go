@@ -0,0 +1,74 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonlLine은 JSON Lines 파일의 한 줄입니다. 첫 줄은 세션 정보(type "session"), 그다음부터는 이벤트(type "event")입니다.
+type jsonlLine struct {
+	Type string `json:"type"`
+	*Transcript
+	*Entry
+}
+
+// WriteJSONL은 한 줄에 JSON 하나씩 씁니다. 다른 도구(jq 등)로 다시 읽어 분석하기 좋습니다.
+func (t *Transcript) WriteJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(jsonlLine{Type: "session", Transcript: t}); err != nil {
+		return fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	for i := range t.Entries {
+		if err := enc.Encode(jsonlLine{Type: "event", Entry: &t.Entries[i]}); err != nil {
+			return fmt.Errorf("failed to encode transcript: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadJSONL은 WriteJSONL로 쓴 대화록을 다시 읽습니다.
+func ReadJSONL(r io.Reader) (*Transcript, error) {
+	var t *Transcript
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var head struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &head); err != nil {
+			return nil, fmt.Errorf("invalid transcript line %d: %w", n, err)
+		}
+		switch head.Type {
+		case "session":
+			t = &Transcript{}
+			if err := json.Unmarshal(line, t); err != nil {
+				return nil, fmt.Errorf("invalid transcript line %d: %w", n, err)
+			}
+		case "event":
+			if t == nil {
+				return nil, fmt.Errorf("invalid transcript line %d: event before session header", n)
+			}
+			var e Entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("invalid transcript line %d: %w", n, err)
+			}
+			t.Entries = append(t.Entries, e)
+		default:
+			return nil, fmt.Errorf("invalid transcript line %d: unknown type %q", n, head.Type)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("transcript has no session header")
+	}
+	return t, nil
+}
@@ -0,0 +1,83 @@
+package transcript
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown은 GitHub 등에서 바로 읽을 수 있는 Markdown 대화록을 씁니다.
+func (t *Transcript) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# 대화록: %s\n\n", t.SessionID)
+	fmt.Fprintf(bw, "- 앱: `%s`\n- 사용자: `%s`\n- 마지막 대화: %s\n- 이벤트: %d개\n- 내보낸 시각: %s\n",
+		t.AppName, t.UserID, t.UpdatedAt.Format(time.DateTime), len(t.Entries), t.ExportedAt.Format(time.DateTime))
+
+	for _, e := range t.Entries {
+		icon := "🤖"
+		if e.IsUser() {
+			icon = "👤"
+		}
+		fmt.Fprintf(bw, "\n---\n\n### %s %s · %s\n\n", icon, e.Author, e.Time.Format(time.DateTime))
+		fmt.Fprintln(bw, strings.Join(markdownBlocks(e), "\n\n"))
+	}
+
+	if len(t.State) > 0 {
+		fmt.Fprintf(bw, "\n---\n\n## 최종 상태\n\n| 키 | 값 |\n|---|---|\n")
+		for _, k := range sortedKeys(t.State) {
+			fmt.Fprintf(bw, "| `%s` | `%s` |\n", k, strings.ReplaceAll(CompactJSON(t.State[k]), "|", `\|`))
+		}
+	}
+	return bw.Flush()
+}
+
+// markdownBlocks는 이벤트 하나를 빈 줄로 구분할 덩어리들로 나눕니다.
+func markdownBlocks(e Entry) []string {
+	var blocks []string
+	if e.Thought != "" {
+		blocks = append(blocks, "<details><summary>💭 생각</summary>\n\n"+e.Thought+"\n\n</details>")
+	}
+	if e.Text != "" {
+		blocks = append(blocks, e.Text)
+	}
+	for _, c := range e.ToolCalls {
+		blocks = append(blocks, fmt.Sprintf("**⚙ 도구 호출** `%s`\n\n%s", c.Name, codeBlock(prettyJSON(c.Args))))
+	}
+	for _, r := range e.ToolResults {
+		blocks = append(blocks, fmt.Sprintf("**↳ 도구 결과** `%s`\n\n%s", r.Name, codeBlock(prettyJSON(r.Response))))
+	}
+
+	var actions []string
+	for _, k := range sortedKeys(e.StateDelta) {
+		actions = append(actions, fmt.Sprintf("> 📝 상태 변경: `%s` = `%s`", k, CompactJSON(e.StateDelta[k])))
+	}
+	for _, k := range sortedKeys(e.ArtifactDelta) {
+		actions = append(actions, fmt.Sprintf("> 📎 아티팩트: `%s` (버전 %d)", k, e.ArtifactDelta[k]))
+	}
+	if e.TransferTo != "" {
+		actions = append(actions, fmt.Sprintf("> ➡️ `%s`에게 넘김", e.TransferTo))
+	}
+	if e.Escalate {
+		actions = append(actions, "> ⬆️ 상위 에이전트로 에스컬레이션")
+	}
+	if e.Error != "" {
+		actions = append(actions, "> ⚠️ 오류: "+e.Error)
+	}
+	if len(actions) > 0 {
+		// 인용문 줄들이 한 문단으로 합쳐지지 않도록 줄 끝에 공백 두 칸(줄바꿈)을 둡니다.
+		blocks = append(blocks, strings.Join(actions, "  \n"))
+	}
+	return blocks
+}
+
+// codeBlock은 text를 json 코드 블록으로 감쌉니다. text 안에 ```가 있으면 더 긴 펜스를 씁니다.
+func codeBlock(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "json\n" + text + "\n" + fence
+}
@@ -0,0 +1,202 @@
+// Package transcript는 세션을 다른 사람과 공유할 수 있는 대화록으로 내보냅니다.
+//
+// 세션의 모든 이벤트(사용자 발화, 어느 에이전트가 말했는지, 도구 호출과 결과, 상태 변경)를
+// Markdown, JSON Lines, HTML(파일 하나로 열리는 페이지) 중 하나로 씁니다.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// Format은 대화록 형식입니다.
+type Format string
+
+const (
+	Markdown Format = "md"
+	JSONL    Format = "jsonl"
+	HTML     Format = "html"
+)
+
+// Formats는 지원하는 형식 목록입니다.
+var Formats = []Format{Markdown, JSONL, HTML}
+
+// ParseFormat은 "md", "markdown", "jsonl", "json", "html"을 Format으로 바꿉니다.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "md", "markdown":
+		return Markdown, nil
+	case "jsonl", "json":
+		return JSONL, nil
+	case "html", "htm":
+		return HTML, nil
+	}
+	return "", fmt.Errorf("unknown transcript format %q (want md, jsonl or html)", s)
+}
+
+// Transcript는 내보낼 세션 하나입니다. 세 형식 모두 이 값을 그립니다.
+type Transcript struct {
+	AppName    string         `json:"app_name"`
+	UserID     string         `json:"user_id"`
+	SessionID  string         `json:"session_id"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	ExportedAt time.Time      `json:"exported_at"`
+	State      map[string]any `json:"state,omitempty"`
+	Entries    []Entry        `json:"-"`
+}
+
+// Entry는 이벤트 하나입니다. 비어 있는 항목은 출력하지 않습니다.
+type Entry struct {
+	EventID       string           `json:"event_id"`
+	InvocationID  string           `json:"invocation_id,omitempty"`
+	Time          time.Time        `json:"time"`
+	Author        string           `json:"author"`
+	Branch        string           `json:"branch,omitempty"`
+	Text          string           `json:"text,omitempty"`
+	Thought       string           `json:"thought,omitempty"`
+	ToolCalls     []ToolCall       `json:"tool_calls,omitempty"`
+	ToolResults   []ToolResult     `json:"tool_results,omitempty"`
+	StateDelta    map[string]any   `json:"state_delta,omitempty"`
+	ArtifactDelta map[string]int64 `json:"artifact_delta,omitempty"`
+	TransferTo    string           `json:"transfer_to,omitempty"`
+	Escalate      bool             `json:"escalate,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+type ToolCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type ToolResult struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response,omitempty"`
+}
+
+// FromSession은 세션을 Transcript로 바꿉니다. 스트리밍 중간 조각(Partial) 이벤트는 건너뜁니다.
+func FromSession(s session.Session) *Transcript {
+	t := &Transcript{
+		AppName:    s.AppName(),
+		UserID:     s.UserID(),
+		SessionID:  s.ID(),
+		UpdatedAt:  s.LastUpdateTime(),
+		ExportedAt: time.Now(),
+		State:      maps.Collect(s.State().All()),
+	}
+	for e := range s.Events().All() {
+		if e.Partial {
+			continue
+		}
+		t.Entries = append(t.Entries, EntryFromEvent(e))
+	}
+	return t
+}
+
+// EntryFromEvent는 이벤트 하나를 Entry로 바꿉니다.
+func EntryFromEvent(e *session.Event) Entry {
+	entry := Entry{
+		EventID:       e.ID,
+		InvocationID:  e.InvocationID,
+		Time:          e.Timestamp,
+		Author:        e.Author,
+		Branch:        e.Branch,
+		StateDelta:    e.Actions.StateDelta,
+		ArtifactDelta: e.Actions.ArtifactDelta,
+		TransferTo:    e.Actions.TransferToAgent,
+		Escalate:      e.Actions.Escalate,
+	}
+	if e.ErrorCode != "" || e.ErrorMessage != "" {
+		entry.Error = strings.TrimSpace(e.ErrorCode + " " + e.ErrorMessage)
+	}
+	if e.Content == nil {
+		return entry
+	}
+
+	var text, thought []string
+	for _, p := range e.Content.Parts {
+		switch {
+		case p.FunctionCall != nil:
+			entry.ToolCalls = append(entry.ToolCalls, ToolCall{ID: p.FunctionCall.ID, Name: p.FunctionCall.Name, Args: p.FunctionCall.Args})
+		case p.FunctionResponse != nil:
+			entry.ToolResults = append(entry.ToolResults, ToolResult{ID: p.FunctionResponse.ID, Name: p.FunctionResponse.Name, Response: p.FunctionResponse.Response})
+		case p.Thought && p.Text != "":
+			thought = append(thought, p.Text)
+		case p.Text != "":
+			text = append(text, p.Text)
+		}
+	}
+	entry.Text = strings.Join(text, "")
+	entry.Thought = strings.Join(thought, "")
+	return entry
+}
+
+// IsUser는 사용자가 쓴 이벤트인지 알려줍니다.
+func (e Entry) IsUser() bool {
+	return e.Author == genai.RoleUser
+}
+
+// Write는 세션을 format 형식으로 w에 씁니다.
+func Write(w io.Writer, s session.Session, format Format) error {
+	t := FromSession(s)
+	switch format {
+	case Markdown:
+		return t.WriteMarkdown(w)
+	case JSONL:
+		return t.WriteJSONL(w)
+	case HTML:
+		return t.WriteHTML(w)
+	}
+	return fmt.Errorf("unknown transcript format %q", format)
+}
+
+// prettyJSON은 도구 인자/결과와 상태 값을 들여쓴 JSON으로 만듭니다.
+func prettyJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// CompactJSON은 상태 값이나 도구 인자처럼 짧은 값을 한 줄 JSON으로 만듭니다.
+func CompactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// CallLine은 도구 호출을 "⚙ 이름(인자)" 한 줄로 나타냅니다. 리플레이 보고서와 REPL이 같은 모양으로 보여줍니다.
+// maxRunes가 0보다 크면 인자를 그 글자 수까지만 보여줍니다.
+func CallLine(name string, args map[string]any, maxRunes int) string {
+	return fmt.Sprintf("⚙ %s(%s)", name, Truncate(CompactJSON(args), maxRunes))
+}
+
+// ResultLine은 도구 결과를 "↳ 이름: 결과" 한 줄로 나타냅니다. maxRunes는 CallLine과 같습니다.
+func ResultLine(name string, response map[string]any, maxRunes int) string {
+	return fmt.Sprintf("↳ %s: %s", name, Truncate(CompactJSON(response), maxRunes))
+}
+
+// Truncate는 s가 n글자보다 길면 n글자까지 자르고 "…"를 붙입니다. n이 0 이하면 자르지 않습니다.
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
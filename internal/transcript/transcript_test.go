@@ -0,0 +1,142 @@
+package transcript
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sample은 세 형식이 모두 그려야 하는 항목(도구 호출과 결과, 상태 변경, 넘김, 오류)을 담은 대화록입니다.
+func sample() *Transcript {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	return &Transcript{
+		AppName:    "app",
+		UserID:     "alice",
+		SessionID:  "s1",
+		UpdatedAt:  at.Add(time.Minute),
+		ExportedAt: at.Add(time.Hour),
+		State:      map[string]any{"trip": "부산", "budget": float64(300000), "note": "a|b"},
+		Entries: []Entry{
+			{EventID: "e1", InvocationID: "inv-1", Time: at, Author: "user", Text: "부산 날씨 알려줘"},
+			{
+				EventID: "e2", InvocationID: "inv-1", Time: at.Add(time.Second), Author: "planner", Branch: "root.planner",
+				Thought:    "날씨 도구를 불러야겠다",
+				ToolCalls:  []ToolCall{{ID: "c1", Name: "get_weather", Args: map[string]any{"city": "부산"}}},
+				StateDelta: map[string]any{"trip": "부산"},
+			},
+			{
+				EventID: "e3", InvocationID: "inv-1", Time: at.Add(2 * time.Second), Author: "planner",
+				ToolResults:   []ToolResult{{ID: "c1", Name: "get_weather", Response: map[string]any{"sky": "맑음", "temp": float64(12)}}},
+				ArtifactDelta: map[string]int64{"plan.md": 2},
+				TransferTo:    "booker",
+			},
+			{EventID: "e4", Time: at.Add(3 * time.Second), Author: "booker", Text: "맑아요", Escalate: true, Error: "RATE_LIMIT busy"},
+		},
+	}
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	want := sample()
+	var buf bytes.Buffer
+	if err := want.WriteJSONL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 1+len(want.Entries) {
+		t.Errorf("wrote %d lines, want a header and one line per event", n)
+	}
+	got, err := ReadJSONL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the transcript:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	for name, data := range map[string]string{
+		"event before header": `{"type":"event","event_id":"e1"}`,
+		"unknown type":        `{"type":"session"}` + "\n" + `{"type":"note"}`,
+		"no header":           "\n",
+		"bad JSON":            `{"type":`,
+	} {
+		if _, err := ReadJSONL(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: ReadJSONL succeeded", name)
+		}
+	}
+}
+
+func TestHTMLEscapesText(t *testing.T) {
+	tr := sample()
+	tr.Entries[0].Text = `<script>alert("hi")</script>`
+	tr.Entries[1].ToolCalls[0].Args = map[string]any{"q": "<img src=x onerror=alert(1)>"}
+	tr.State["html"] = "<b>굵게</b>"
+
+	var buf bytes.Buffer
+	if err := tr.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, raw := range []string{"<script>", "<img", "<b>"} {
+		if strings.Contains(out, raw) {
+			t.Errorf("HTML contains unescaped %q", raw)
+		}
+	}
+	if !strings.Contains(out, "&lt;script&gt;") {
+		t.Error("escaped event text is missing")
+	}
+	for _, want := range []string{"⚙ get_weather", "↳ get_weather", "📎 아티팩트", "booker</code>에게 넘김", "⚠️ 오류: RATE_LIMIT busy", "<th>키</th>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML does not contain %q", want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sample().WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# 대화록: s1\n",
+		"- 이벤트: 4개\n",
+		"### 👤 user · 2025-01-01 12:00:00\n\n부산 날씨 알려줘\n",
+		"### 🤖 planner · 2025-01-01 12:00:01\n",
+		"<details><summary>💭 생각</summary>\n\n날씨 도구를 불러야겠다\n\n</details>",
+		"**⚙ 도구 호출** `get_weather`\n\n```json\n{\n  \"city\": \"부산\"\n}\n```",
+		"> 📝 상태 변경: `trip` = `\"부산\"`",
+		"**↳ 도구 결과** `get_weather`\n\n```json\n{\n  \"sky\": \"맑음\",\n  \"temp\": 12\n}\n```",
+		// 여러 동작은 줄바꿈(공백 두 칸)으로 이어 한 인용문에 둡니다.
+		"> 📎 아티팩트: `plan.md` (버전 2)  \n> ➡️ `booker`에게 넘김",
+		"> ⬆️ 상위 에이전트로 에스컬레이션  \n> ⚠️ 오류: RATE_LIMIT busy",
+		// 최종 상태는 키 순서대로, 표를 깨는 |는 이스케이프합니다.
+		"| `budget` | `300000` |\n| `note` | `\"a\\|b\"` |\n| `trip` | `\"부산\"` |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown does not contain %q:\n%s", want, out)
+		}
+	}
+	if got := codeBlock("```"); got != "````json\n```\n````" {
+		t.Errorf("codeBlock with a fence inside = %q", got)
+	}
+}
+
+func TestCallAndResultLines(t *testing.T) {
+	args := map[string]any{"city": "부산"}
+	if got := CallLine("get_weather", args, 0); got != `⚙ get_weather({"city":"부산"})` {
+		t.Errorf("CallLine = %q", got)
+	}
+	if got := CallLine("get_weather", args, 5); got != `⚙ get_weather({"cit…)` {
+		t.Errorf("truncated CallLine = %q", got)
+	}
+	if got := ResultLine("get_weather", map[string]any{"sky": "맑음"}, 0); got != `↳ get_weather: {"sky":"맑음"}` {
+		t.Errorf("ResultLine = %q", got)
+	}
+	if got := CallLine("now", nil, 0); got != "⚙ now(null)" {
+		t.Errorf("CallLine without args = %q", got)
+	}
+	if got := Truncate("가나다", 2); got != "가나…" {
+		t.Errorf("Truncate = %q", got)
+	}
+}